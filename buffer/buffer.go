@@ -2,272 +2,602 @@
 package buffer
 
 import (
+	"bytes"
 	"encoding/binary"
+	"io"
 	"math"
-	"reflect"
+	"math/bits"
 	"unsafe"
 
 	"github.com/valyala/bytebufferpool"
 )
 
+// Buffer reads and writes the peechy wire format.
+//
+// Writes always append to the end of the underlying bytes, and reads start at a
+// separate read offset, so a message can be encoded and then decoded from the
+// same Buffer without rewinding.
+//
+// ReadStringBytes, ReadAlphanumericBytes and ReadByteArrayView don't copy: they
+// return slices of the Buffer's own bytes. Those slices are only valid until
+// the Buffer is Reset or written to, or its ByteBuffer is returned to
+// bytebufferpool, and changing them changes the Buffer. Copy anything that
+// needs to live longer. The other Read methods always return copies.
 type Buffer struct {
-	Bytes  *bytebufferpool.ByteBuffer
-	Offset uint
+	data   *bytebufferpool.ByteBuffer
+	offset uint
+	format WireFormat
+
+	// sink and source are set for Buffers from NewWriter and NewReader.
+	sink   io.Writer
+	source io.Reader
+	// discarded counts bytes that were flushed or read and then dropped
+	// from data, so offsets in errors are from the start of the stream.
+	discarded uint
+	// types is set by SetTypes.
+	types Types
+
+	err error
+}
+
+// WireFormat selects how a Buffer encodes uint, int and the length prefixes of
+// strings and arrays.
+type WireFormat byte
+
+const (
+	// FixedWidthFormat writes them as 4 byte little-endian integers, the same as
+	// js/bb.ts. This is the default.
+	FixedWidthFormat WireFormat = iota
+
+	// VarintFormat writes them as LEB128 varints, zigzag-encoding signed values
+	// first, so small numbers take a single byte. Both sides of a connection need
+	// to agree on it.
+	VarintFormat
+)
+
+// NewBuffer returns a Buffer that appends to bb and reads from its start.
+func NewBuffer(bb *bytebufferpool.ByteBuffer) *Buffer {
+	return &Buffer{data: bb}
+}
+
+// FromBytes returns a Buffer that reads from data. Writes append to data.
+func FromBytes(data []byte) *Buffer {
+	return &Buffer{data: &bytebufferpool.ByteBuffer{B: data}}
+}
+
+// Len returns how many bytes have been written, including those already read.
+// For a Buffer from NewWriter, it only counts bytes that haven't been flushed.
+func (b *Buffer) Len() int {
+	return len(b.data.B)
+}
+
+// Remaining returns how many bytes are left to read. For a Buffer from
+// NewReader, it only counts bytes that have already been read from the stream.
+func (b *Buffer) Remaining() int {
+	if b.offset >= uint(len(b.data.B)) {
+		return 0
+	}
+
+	return len(b.data.B) - int(b.offset)
+}
+
+// SetWireFormat changes how integers are read and written from here on.
+func (b *Buffer) SetWireFormat(format WireFormat) {
+	b.format = format
+}
+
+func (b *Buffer) WireFormat() WireFormat {
+	return b.format
+}
+
+// Offset returns the read offset.
+func (b *Buffer) Offset() uint {
+	return b.discarded + b.offset
 }
 
+// Bytes returns everything written to the buffer, whether or not it has been
+// read. The slice is only valid until the next write or Reset.
+func (b *Buffer) Bytes() []byte {
+	return b.data.B
+}
+
+// Rewind moves the read offset back to the start and clears Err. A Buffer from
+// NewReader can only rewind to the oldest byte it still holds.
+func (b *Buffer) Rewind() {
+	b.offset = 0
+	b.err = nil
+}
+
+const SIZEOF_INT64 = 8 // bytes
 const SIZEOF_INT32 = 4 // bytes
 const SIZEOF_INT16 = 2 // bytes
 
-func (b *Buffer) WriteVarFloat(s float32) {
+// isLittleEndian is true when the host stores integers in the same byte order
+// as the wire format, so typed arrays can be copied without converting them.
+var isLittleEndian = func() bool {
+	value := uint16(1)
+	return *(*byte)(unsafe.Pointer(&value)) == 1
+}()
+
+// bytesOf returns the n bytes of memory starting at p.
+func bytesOf(p unsafe.Pointer, n int) []byte {
+	return (*[math.MaxInt32]byte)(p)[:n:n]
+}
+
+// WriteVarFloat moves the exponent of value into the first byte so that zero
+// takes a single byte. Denormals have the same exponent as zero, so they are
+// written as zero too. This matches ByteBuffer.writeVarFloat in js/bb.ts.
+func (b *Buffer) WriteVarFloat(value float32) {
+	bits := math.Float32bits(value)
+
+	// Move the exponent to the first 8 bits
+	bits = (bits >> 23) | (bits << 9)
 
+	if bits&255 == 0 {
+		b.WriteByte(0)
+		return
+	}
+
+	var bytes [4]byte
+	binary.LittleEndian.PutUint32(bytes[:], bits)
+	b.write(bytes[:])
 }
 func (b *Buffer) WriteFloat32(value float32) {
-	bytes := (*[4]byte)(unsafe.Pointer(&value))[:]
-	b.Bytes.Write(bytes)
-	b.Offset++
+	b.WriteUint32(math.Float32bits(value))
 }
 func (b *Buffer) WriteVarUint(value uint) {
-	b.WriteUint32(uint32(value))
-	// for {
-	// 	curr := byte(value) & 127
-	// 	value >>= 7
+	if b.format == FixedWidthFormat {
+		b.WriteUint32(uint32(value))
+		return
+	}
+
+	b.WriteVarUint64(uint64(value))
+}
+
+// WriteVarUint64 writes value as a LEB128 varint, whatever the wire format.
+func (b *Buffer) WriteVarUint64(value uint64) {
+	for {
+		curr := byte(value) & 127
+		value >>= 7
 
-	// 	if value == 0 {
-	// 		b.Bytes.WriteByte(curr)
-	// 		b.Offset++
-	// 		return
-	// 	}
+		if value == 0 {
+			b.writeByte(curr)
+			return
+		}
+
+		b.writeByte(curr | 128)
+	}
+}
+
+// WriteVarInt64 zigzag-encodes value and writes it as a LEB128 varint, whatever
+// the wire format.
+func (b *Buffer) WriteVarInt64(value int64) {
+	b.WriteVarUint64(uint64((value << 1) ^ (value >> 63)))
+}
+
+// Err returns the first error hit by a Read method, or nil.
+// Once a read fails, every later read returns the zero value until Reset.
+func (b *Buffer) Err() error {
+	return b.err
+}
 
-	// 	b.Bytes.WriteByte(curr | 128)
-	// 	b.Offset++
-	// }
+// next advances past n bytes and returns where they start.
+// It returns false and records an *UnexpectedEOFError when fewer than n are left.
+func (b *Buffer) next(n uint) (uint, bool) {
+	if b.err != nil {
+		return b.offset, false
+	}
+
+	if !b.fill(n) {
+		b.eof(n)
+		return b.offset, false
+	}
+
+	start := b.offset
+	b.offset += n
+	return start, true
+}
 
+// eof records that a read of n bytes ran past the end of the data, unless
+// reading from the stream already failed for another reason.
+func (b *Buffer) eof(n uint) {
+	if b.err == nil {
+		b.err = &UnexpectedEOFError{Offset: b.Offset(), Size: n}
+	}
 }
 
 func (b *Buffer) ReadBool() bool {
-	offset := b.Offset
-	b.Offset++
-	return b.Bytes.B[offset] >= 1
+	return b.ReadUint8() >= 1
 }
 
+// Slice is the same as Bytes.
 func (b *Buffer) Slice() []byte {
-	return b.Bytes.B[0:b.Offset]
+	return b.Bytes()
 }
 
 func (b *Buffer) WriteUint16(value uint16) {
-	b.Bytes.Write((*[2]byte)(unsafe.Pointer(&value))[:])
-	b.Offset += 2
+	var bytes [2]byte
+	binary.LittleEndian.PutUint16(bytes[:], value)
+	b.write(bytes[:])
 }
 func (b *Buffer) WriteUint32(value uint32) {
-	b.Bytes.Write((*[4]byte)(unsafe.Pointer(&value))[:])
-	b.Offset += 4
+	var bytes [4]byte
+	binary.LittleEndian.PutUint32(bytes[:], value)
+	b.write(bytes[:])
+}
+func (b *Buffer) WriteUint64(value uint64) {
+	var bytes [8]byte
+	binary.LittleEndian.PutUint64(bytes[:], value)
+	b.write(bytes[:])
+}
+
+// Grow makes room for n more bytes to be written without reallocating.
+// It does nothing for a Buffer from NewWriter, which writes in chunks.
+func (b *Buffer) Grow(n int) {
+	if b.sink != nil || cap(b.data.B)-len(b.data.B) >= n {
+		return
+	}
+
+	grown := make([]byte, len(b.data.B), len(b.data.B)+n)
+	copy(grown, b.data.B)
+	b.data.B = grown
 }
 
 func (b *Buffer) Reset() {
-	b.Bytes.Reset()
-	b.Offset = 0
+	b.data.Reset()
+	b.offset = 0
+	b.discarded = 0
+	b.err = nil
 }
 
 func (b *Buffer) WriteInt8Array(value []int8) {
-	b.WriteVarUint(uint(cap(value)))
-	header := *(*reflect.SliceHeader)(unsafe.Pointer(&value))
-
-	// The length and capacity of the slice are different.
-	// header.Len /=
-	// header.Cap /=
-
-	// Convert slice header to an []int32
-	b.Bytes.Write(*(*[]byte)(unsafe.Pointer(&header)))
+	b.WriteVarUint(uint(len(value)))
+	if len(value) > 0 {
+		b.write(bytesOf(unsafe.Pointer(&value[0]), len(value)))
+	}
 }
 
-func (b *Buffer) WriteInt16Array(value []int16) {
-	b.WriteVarUint(uint(cap(value)))
-	header := *(*reflect.SliceHeader)(unsafe.Pointer(&value))
+// The typed array writers below copy the slice's memory as-is on little-endian
+// hosts, where it is already in wire order, and write it an element at a time
+// everywhere else. Arrays are prefixed with their length in bytes, the same as
+// ByteBuffer.writeByteArray in js/bb.ts.
 
-	// The length and capacity of the slice are different.
-	header.Len *= SIZEOF_INT16
-	header.Cap *= SIZEOF_INT16
+func (b *Buffer) WriteInt16Array(value []int16) {
+	b.WriteVarUint(uint(len(value) * SIZEOF_INT16))
+	if isLittleEndian && len(value) > 0 {
+		b.write(bytesOf(unsafe.Pointer(&value[0]), len(value)*SIZEOF_INT16))
+		return
+	}
 
-	// Convert slice header to an []int32
-	b.Bytes.Write(*(*[]byte)(unsafe.Pointer(&header)))
+	for _, v := range value {
+		b.WriteInt16(v)
+	}
 }
 
 func (b *Buffer) WriteInt32Array(value []int32) {
-	b.WriteVarUint(uint(cap(value)))
-	header := *(*reflect.SliceHeader)(unsafe.Pointer(&value))
-
-	// The length and capacity of the slice are different.
-	header.Len *= SIZEOF_INT32
-	header.Cap *= SIZEOF_INT32
+	b.WriteVarUint(uint(len(value) * SIZEOF_INT32))
+	if isLittleEndian && len(value) > 0 {
+		b.write(bytesOf(unsafe.Pointer(&value[0]), len(value)*SIZEOF_INT32))
+		return
+	}
 
-	// Convert slice header to an []int32
-	b.Bytes.Write(*(*[]byte)(unsafe.Pointer(&header)))
+	for _, v := range value {
+		b.WriteInt32(v)
+	}
 }
 
 func (b *Buffer) WriteUInt16Array(value []uint16) {
-	b.WriteVarUint(uint(cap(value)))
-	header := *(*reflect.SliceHeader)(unsafe.Pointer(&value))
-
-	// The length and capacity of the slice are different.
-	header.Len *= SIZEOF_INT16
-	header.Cap *= SIZEOF_INT16
+	b.WriteVarUint(uint(len(value) * SIZEOF_INT16))
+	if isLittleEndian && len(value) > 0 {
+		b.write(bytesOf(unsafe.Pointer(&value[0]), len(value)*SIZEOF_INT16))
+		return
+	}
 
-	// Convert slice header to an []int32
-	b.Bytes.Write(*(*[]byte)(unsafe.Pointer(&header)))
+	for _, v := range value {
+		b.WriteUint16(v)
+	}
 }
 
 func (b *Buffer) WriteUInt32Array(value []uint32) {
-	b.WriteVarUint(uint(cap(value)))
-	header := *(*reflect.SliceHeader)(unsafe.Pointer(&value))
-
-	// The length and capacity of the slice are different.
-	header.Len *= SIZEOF_INT32
-	header.Cap *= SIZEOF_INT32
+	b.WriteVarUint(uint(len(value) * SIZEOF_INT32))
+	if isLittleEndian && len(value) > 0 {
+		b.write(bytesOf(unsafe.Pointer(&value[0]), len(value)*SIZEOF_INT32))
+		return
+	}
 
-	// Convert slice header to an []int32
-	b.Bytes.Write(*(*[]byte)(unsafe.Pointer(&header)))
+	for _, v := range value {
+		b.WriteUint32(v)
+	}
 }
 
 func (b *Buffer) WriteFloat32Array(value []float32) {
-	b.WriteVarUint(uint(cap(value)))
-	header := *(*reflect.SliceHeader)(unsafe.Pointer(&value))
+	b.WriteVarUint(uint(len(value) * SIZEOF_INT32))
+	if isLittleEndian && len(value) > 0 {
+		b.write(bytesOf(unsafe.Pointer(&value[0]), len(value)*SIZEOF_INT32))
+		return
+	}
+
+	for _, v := range value {
+		b.WriteFloat32(v)
+	}
+}
+
+func (b *Buffer) WriteInt64Array(value []int64) {
+	b.WriteVarUint(uint(len(value) * SIZEOF_INT64))
+	if isLittleEndian && len(value) > 0 {
+		b.write(bytesOf(unsafe.Pointer(&value[0]), len(value)*SIZEOF_INT64))
+		return
+	}
+
+	for _, v := range value {
+		b.WriteInt64(v)
+	}
+}
+
+func (b *Buffer) WriteUInt64Array(value []uint64) {
+	b.WriteVarUint(uint(len(value) * SIZEOF_INT64))
+	if isLittleEndian && len(value) > 0 {
+		b.write(bytesOf(unsafe.Pointer(&value[0]), len(value)*SIZEOF_INT64))
+		return
+	}
+
+	for _, v := range value {
+		b.WriteUint64(v)
+	}
+}
+
+func (b *Buffer) WriteFloat64Array(value []float64) {
+	b.WriteVarUint(uint(len(value) * SIZEOF_INT64))
+	if isLittleEndian && len(value) > 0 {
+		b.write(bytesOf(unsafe.Pointer(&value[0]), len(value)*SIZEOF_INT64))
+		return
+	}
+
+	for _, v := range value {
+		b.WriteFloat64(v)
+	}
+}
 
-	// The length and capacity of the slice are different.
-	header.Len *= SIZEOF_INT32
-	header.Cap *= SIZEOF_INT32
+// WriteByte implements io.ByteWriter. It only returns an error for a Buffer
+// from NewWriter that failed to flush.
+func (b *Buffer) WriteByte(value byte) error {
+	b.writeByte(value)
+	if b.sink != nil {
+		return b.err
+	}
+	return nil
+}
 
-	// Convert slice header to an []int32
-	b.Bytes.Write(*(*[]byte)(unsafe.Pointer(&header)))
+// Write implements io.Writer. It writes p as is, without a length.
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.write(p)
+	if b.sink != nil && b.err != nil {
+		return 0, b.err
+	}
+	return len(p), nil
 }
 
-func (b *Buffer) WriteByte(value byte) {
-	b.Bytes.WriteByte(value)
-	b.Offset++
+func (b *Buffer) WriteUint8(value uint8) {
+	b.WriteByte(value)
 }
 
 func (b *Buffer) WriteByteArray(value []byte) {
-	b.WriteVarUint(uint(cap(value)))
-	b.Bytes.Write(value)
+	b.WriteVarUint(uint(len(value)))
+	b.write(value)
 }
 
 func (b *Buffer) WriteVarInt(value int) {
-	b.WriteInt32(int32(value))
-	// b.WriteVarUint(uint((value << 1) ^ (value >> 31)))
+	if b.format == FixedWidthFormat {
+		b.WriteInt32(int32(value))
+		return
+	}
+
+	b.WriteVarUint(uint((value << 1) ^ (value >> (bits.UintSize - 1))))
 }
 func (b *Buffer) WriteInt8(value int8) {
-	bytes := (*[1]byte)(unsafe.Pointer(&value))[:]
-	b.Bytes.Write(bytes)
-	b.Offset++
+	b.writeByte(byte(value))
 }
 func (b *Buffer) WriteBool(value bool) {
 	if value {
-		b.Bytes.WriteByte(1)
+		b.writeByte(1)
 	} else {
-		b.Bytes.WriteByte(0)
+		b.writeByte(0)
 	}
-
-	b.Offset++
 }
 func (b *Buffer) WriteInt16(value int16) {
-	bytes := (*[2]byte)(unsafe.Pointer(&value))[:]
-	b.Bytes.Write(bytes)
-	b.Offset += 2
+	b.WriteUint16(uint16(value))
 }
 func (b *Buffer) WriteInt32(value int32) {
-	bytes := (*[4]byte)(unsafe.Pointer(&value))[:]
-	b.Bytes.Write(bytes)
-	b.Offset += 4
+	b.WriteUint32(uint32(value))
+}
+func (b *Buffer) WriteInt64(value int64) {
+	b.WriteUint64(uint64(value))
+}
+func (b *Buffer) WriteFloat64(value float64) {
+	b.WriteUint64(math.Float64bits(value))
 }
 func (b *Buffer) WriteLowpFloat(value float64) {
 	b.WriteVarInt(int(math.Round(value * 1000)))
 }
+
+// This is not a null-terminated string.
+// The length in bytes comes first, the same as ByteBuffer.writeString in js/bb.ts.
 func (b *Buffer) WriteString(s string) {
-	if len(s) > 0 {
-		byteLength, _ := b.Bytes.WriteString(s)
-		b.Offset += uint(byteLength)
-	}
-	b.WriteByte(0)
+	b.WriteVarUint(uint(len(s)))
+	b.writeString(s)
+}
+
+// WriteStringBytes writes s the same way as WriteString.
+func (b *Buffer) WriteStringBytes(s []byte) {
+	b.WriteVarUint(uint(len(s)))
+	b.write(s)
 }
 
 func (b *Buffer) ReadVarFloat() float32 {
-	b.Offset++
-	return 0
+	start, ok := b.next(1)
+	if !ok || b.data.B[start] == 0 {
+		return 0
+	}
+
+	b.offset = start
+	bits := b.ReadUint32()
+
+	// Move the exponent back into place
+	bits = (bits << 23) | (bits >> 9)
+
+	return math.Float32frombits(bits)
 }
 
 func (b *Buffer) ReadFloat32() float32 {
-	start := b.Offset
-	b.Offset += 4
-	return math.Float32frombits(binary.LittleEndian.Uint32(b.Bytes.B[start:b.Offset]))
+	return math.Float32frombits(b.ReadUint32())
+}
+
+func (b *Buffer) ReadFloat64() float64 {
+	return math.Float64frombits(b.ReadUint64())
 }
 
 func (b *Buffer) ReadVarUint() uint {
-	return uint(b.ReadUint32())
-	// var value uint32
-	// var shift uint32
-	// var curr uint8
+	if b.format == FixedWidthFormat {
+		return uint(b.ReadUint32())
+	}
+
+	return uint(b.readLEB128(bits.UintSize))
+}
+
+// ReadVarUint64 reads a LEB128 varint, whatever the wire format.
+func (b *Buffer) ReadVarUint64() uint64 {
+	return b.readLEB128(64)
+}
+
+// ReadVarInt64 reads a zigzag-encoded LEB128 varint, whatever the wire format.
+func (b *Buffer) ReadVarInt64() int64 {
+	value := b.ReadVarUint64()
+	x := int64(value >> 1)
+	if value&1 != 0 {
+		x = ^x
+	}
+	return x
+}
 
-	// value = 0
-	// shift = 0
+// readLEB128 reads a varint that has to fit in size bits.
+func (b *Buffer) readLEB128(size uint) uint64 {
+	var value uint64
+	var shift uint
 
-	// for {
-	// 	if b.Offset >= uint(b.Bytes.Len()) {
-	// 		break
-	// 	}
+	for {
+		start, ok := b.next(1)
+		if !ok {
+			return 0
+		}
 
-	// 	curr = b.ReadByte()
-	// 	value |= uint32((curr & 127)) << shift
-	// 	shift += 7
-	// 	if (curr&128) == 0 || shift >= 35 {
-	// 		break
-	// 	}
-	// }
+		curr := b.data.B[start]
+		if shift >= size-7 && curr>>(size-shift) != 0 {
+			b.err = &VarintOverflowError{Offset: b.discarded + start, Bits: size}
+			return 0
+		}
 
-	// return uint(value >> 0)
+		value |= uint64(curr&127) << shift
+		shift += 7
+		if curr&128 == 0 {
+			return value
+		}
+	}
 }
 
-func (b *Buffer) ReadByte() byte {
-	start := b.Offset
+// ReadByte implements io.ByteReader.
+func (b *Buffer) ReadByte() (byte, error) {
+	start, ok := b.next(1)
+	if !ok {
+		return 0, b.err
+	}
+
+	return b.data.B[start], nil
+}
 
-	b.Offset++
-	// if b.Offset > uint(b.Bytes.Len()) {
-	// 	return byte(0), errors.New("offset exceeded bounds of buffer")
-	// }
+func (b *Buffer) ReadUint8() uint8 {
+	start, ok := b.next(1)
+	if !ok {
+		return 0
+	}
 
-	return b.Bytes.B[start]
+	return b.data.B[start]
 }
 
+// ReadByteArray returns a copy of the next byte array.
 func (b *Buffer) ReadByteArray() []byte {
-	start := b.Offset
-	b.Offset += b.ReadVarUint()
+	return b.ReadByteArrayInto(nil)
+}
+
+// ReadByteArrayInto is ReadByteArray, reusing dst if it has room.
+func (b *Buffer) ReadByteArrayInto(dst []byte) []byte {
+	view := b.ReadByteArrayView()
+	if view == nil {
+		return nil
+	}
 
-	return b.Bytes.B[start:b.Offset]
+	if dst == nil || cap(dst) < len(view) {
+		dst = make([]byte, 0, len(view))
+	}
+	return append(dst[:0], view...)
 }
 
-const zeroRune = rune(byte(0))
+// ReadByteArrayView is ReadByteArray without the copy. See Buffer for how long
+// the slice is valid.
+func (b *Buffer) ReadByteArrayView() []byte {
+	length := b.ReadVarUint()
+	start, ok := b.next(length)
+	if !ok {
+		return nil
+	}
+
+	return b.data.B[start:b.offset:b.offset]
+}
 
 func (b *Buffer) ReadAlphanumeric() string {
-	runes := make([]rune, 0, 10)
-	var r rune
+	return string(b.ReadAlphanumericBytes())
+}
 
+// ReadAlphanumericBytes is ReadAlphanumeric without the copy. The terminating
+// NUL isn't included. See Buffer for how long the slice is valid.
+func (b *Buffer) ReadAlphanumericBytes() []byte {
+	if b.err != nil {
+		return nil
+	}
+
+	var length uint
 	for {
-		r = b.ReadRune()
-		if r == zeroRune {
+		i := bytes.IndexByte(b.data.B[b.offset+length:], 0)
+		if i != -1 {
+			length += uint(i)
 			break
 		}
 
-		runes = append(runes, r)
+		length = uint(len(b.data.B)) - b.offset
+		if !b.fill(length + 1) {
+			b.offset += length
+			b.eof(1)
+			return nil
+		}
 	}
 
-	return string(runes)
+	start := b.offset
+	b.offset += length + 1
+	return b.data.B[start : start+length : start+length]
 }
 
-func (b *Buffer) ReadRune() rune {
-	pos := b.Offset
-	b.Offset++
-	return rune(b.Bytes.B[pos])
+// ReadRune implements io.RuneReader. Alphanumeric strings are ASCII, so
+// every rune is a single byte.
+func (b *Buffer) ReadRune() (rune, int, error) {
+	value, err := b.ReadByte()
+	if err != nil {
+		return zeroRune, 0, err
+	}
 
+	return rune(value), 1, nil
 }
 
+const zeroRune = rune(byte(0))
+
 func (b *Buffer) WriteAlphanumeric(s string) {
 	for _, r := range s {
 		b.WriteByte(byte(r))
@@ -275,20 +605,43 @@ func (b *Buffer) WriteAlphanumeric(s string) {
 	b.WriteByte(0)
 }
 
+func (b *Buffer) WriteAlphanumericBytes(s []byte) {
+	b.write(s)
+	b.WriteByte(0)
+}
+
 func (b *Buffer) ReadUint16() uint16 {
-	start := b.Offset
-	b.Offset += 2
+	start, ok := b.next(2)
+	if !ok {
+		return 0
+	}
 
-	return binary.LittleEndian.Uint16(b.Bytes.B[start:b.Offset])
+	return binary.LittleEndian.Uint16(b.data.B[start:b.offset])
 }
 
 func (b *Buffer) ReadUint32() uint32 {
-	start := b.Offset
-	b.Offset += 4
-	return binary.LittleEndian.Uint32(b.Bytes.B[start:b.Offset])
+	start, ok := b.next(4)
+	if !ok {
+		return 0
+	}
+
+	return binary.LittleEndian.Uint32(b.data.B[start:b.offset])
+}
+
+func (b *Buffer) ReadUint64() uint64 {
+	start, ok := b.next(8)
+	if !ok {
+		return 0
+	}
+
+	return binary.LittleEndian.Uint64(b.data.B[start:b.offset])
 }
 
 func (b *Buffer) ReadVarInt() int {
+	if b.format == FixedWidthFormat {
+		return int(b.ReadInt32())
+	}
+
 	value := b.ReadVarUint()
 	x := int(value >> 1)
 	if value&1 != 0 {
@@ -298,7 +651,7 @@ func (b *Buffer) ReadVarInt() int {
 }
 
 func (b *Buffer) ReadInt8() int8 {
-	return int8(b.ReadByte())
+	return int8(b.ReadUint8())
 }
 func (b *Buffer) ReadInt16() int16 {
 	return int16(b.ReadUint16())
@@ -306,107 +659,312 @@ func (b *Buffer) ReadInt16() int16 {
 func (b *Buffer) ReadInt32() int32 {
 	return int32(b.ReadUint32())
 }
+func (b *Buffer) ReadInt64() int64 {
+	return int64(b.ReadUint64())
+}
+
+// ReadLowpFloat reads the int WriteLowpFloat wrote, which is a varint in
+// VarintFormat.
 func (b *Buffer) ReadLowpFloat() float32 {
-	return float32(b.ReadInt32()) / 1000
+	return float32(b.ReadVarInt()) / 1000
 }
-func (b *Buffer) ReadString() string {
-	start := b.Offset
-	var stop int
 
-	for i, char := range b.Bytes.B[start:] {
-		if char == 0 {
-			stop = i
-			break
-		}
-	}
-	b.Offset += uint(stop + 1)
-	if stop > 0 {
-		return string(b.Bytes.B[start : b.Offset-1])
-	} else {
-		return ""
-	}
+// This is not a null-terminated string.
+func (b *Buffer) ReadString() string {
+	return string(b.ReadStringBytes())
+}
 
+// ReadStringBytes is ReadString without the copy. See Buffer for how long the
+// slice is valid.
+func (b *Buffer) ReadStringBytes() []byte {
+	return b.ReadByteArrayView()
 }
 
 func (b *Buffer) ReadInt8Array() []int8 {
-	length := b.ReadVarUint()
+	return b.ReadInt8ArrayInto(nil)
+}
 
-	arr := make([]int8, length)
+// ReadInt8ArrayInto is ReadInt8Array, reusing dst if it has room.
+func (b *Buffer) ReadInt8ArrayInto(dst []int8) []int8 {
+	data, ok := b.readArrayBytes(1)
+	if !ok {
+		return nil
+	}
 
-	for i := uint(0); i < length; i++ {
-		arr[i] = int8(b.Bytes.B[b.Offset])
-		b.Offset++
+	var arr []int8
+	if n := len(data); dst != nil && cap(dst) >= n {
+		arr = dst[:n]
+	} else {
+		arr = make([]int8, n)
+	}
+	if len(arr) > 0 {
+		copy(bytesOf(unsafe.Pointer(&arr[0]), len(data)), data)
 	}
 
 	return arr
 }
 
 func (b *Buffer) ReadInt16Array() []int16 {
-	length := b.ReadVarUint()
-	end := b.Offset + length*SIZEOF_INT16
+	return b.ReadInt16ArrayInto(nil)
+}
+
+// ReadInt16ArrayInto is ReadInt16Array, reusing dst if it has room.
+func (b *Buffer) ReadInt16ArrayInto(dst []int16) []int16 {
+	data, ok := b.readArrayBytes(SIZEOF_INT16)
+	if !ok {
+		return nil
+	}
 
-	arr := make([]int16, length)
+	var arr []int16
+	if n := len(data) / SIZEOF_INT16; dst != nil && cap(dst) >= n {
+		arr = dst[:n]
+	} else {
+		arr = make([]int16, n)
+	}
+	if isLittleEndian && len(arr) > 0 {
+		copy(bytesOf(unsafe.Pointer(&arr[0]), len(data)), data)
+		return arr
+	}
 
-	for i := uint(0); i < length; i++ {
-		arr[i] = int16(binary.LittleEndian.Uint16(b.Bytes.B[b.Offset:end]))
-		b.Offset += SIZEOF_INT16
+	for i := range arr {
+		arr[i] = int16(binary.LittleEndian.Uint16(data[i*SIZEOF_INT16:]))
 	}
 
 	return arr
 }
 
 func (b *Buffer) ReadUInt16Array() []uint16 {
-	length := b.ReadVarUint()
-	end := b.Offset + length*SIZEOF_INT16
+	return b.ReadUInt16ArrayInto(nil)
+}
+
+// ReadUInt16ArrayInto is ReadUInt16Array, reusing dst if it has room.
+func (b *Buffer) ReadUInt16ArrayInto(dst []uint16) []uint16 {
+	data, ok := b.readArrayBytes(SIZEOF_INT16)
+	if !ok {
+		return nil
+	}
 
-	arr := make([]uint16, length)
+	var arr []uint16
+	if n := len(data) / SIZEOF_INT16; dst != nil && cap(dst) >= n {
+		arr = dst[:n]
+	} else {
+		arr = make([]uint16, n)
+	}
+	if isLittleEndian && len(arr) > 0 {
+		copy(bytesOf(unsafe.Pointer(&arr[0]), len(data)), data)
+		return arr
+	}
 
-	for i := uint(0); i < length; i++ {
-		arr[i] = binary.LittleEndian.Uint16(b.Bytes.B[b.Offset:end])
-		b.Offset += SIZEOF_INT16
+	for i := range arr {
+		arr[i] = binary.LittleEndian.Uint16(data[i*SIZEOF_INT16:])
 	}
 
 	return arr
 }
 
 func (b *Buffer) ReadUInt32Array() []uint32 {
-	length := b.ReadVarUint()
-	end := b.Offset + length*SIZEOF_INT32
+	return b.ReadUInt32ArrayInto(nil)
+}
+
+// ReadUInt32ArrayInto is ReadUInt32Array, reusing dst if it has room.
+func (b *Buffer) ReadUInt32ArrayInto(dst []uint32) []uint32 {
+	data, ok := b.readArrayBytes(SIZEOF_INT32)
+	if !ok {
+		return nil
+	}
 
-	arr := make([]uint32, length)
+	var arr []uint32
+	if n := len(data) / SIZEOF_INT32; dst != nil && cap(dst) >= n {
+		arr = dst[:n]
+	} else {
+		arr = make([]uint32, n)
+	}
+	if isLittleEndian && len(arr) > 0 {
+		copy(bytesOf(unsafe.Pointer(&arr[0]), len(data)), data)
+		return arr
+	}
 
-	for i := uint(0); i < length; i++ {
-		arr[i] = binary.LittleEndian.Uint32(b.Bytes.B[b.Offset:end])
-		b.Offset += SIZEOF_INT32
+	for i := range arr {
+		arr[i] = binary.LittleEndian.Uint32(data[i*SIZEOF_INT32:])
 	}
 
 	return arr
 }
 
 func (b *Buffer) ReadInt32Array() []int32 {
-	length := b.ReadVarUint()
-	end := b.Offset + length*SIZEOF_INT32
+	return b.ReadInt32ArrayInto(nil)
+}
 
-	arr := make([]int32, length)
+// ReadInt32ArrayInto is ReadInt32Array, reusing dst if it has room.
+func (b *Buffer) ReadInt32ArrayInto(dst []int32) []int32 {
+	data, ok := b.readArrayBytes(SIZEOF_INT32)
+	if !ok {
+		return nil
+	}
+
+	var arr []int32
+	if n := len(data) / SIZEOF_INT32; dst != nil && cap(dst) >= n {
+		arr = dst[:n]
+	} else {
+		arr = make([]int32, n)
+	}
+	if isLittleEndian && len(arr) > 0 {
+		copy(bytesOf(unsafe.Pointer(&arr[0]), len(data)), data)
+		return arr
+	}
 
-	for i := uint(0); i < length; i++ {
-		arr[i] = int32(binary.LittleEndian.Uint32(b.Bytes.B[b.Offset:end]))
-		b.Offset += SIZEOF_INT32
+	for i := range arr {
+		arr[i] = int32(binary.LittleEndian.Uint32(data[i*SIZEOF_INT32:]))
 	}
 
 	return arr
 }
 
 func (b *Buffer) ReadFloat32Array() []float32 {
-	length := b.ReadVarUint()
-	end := b.Offset + length*SIZEOF_INT32
+	return b.ReadFloat32ArrayInto(nil)
+}
+
+// ReadFloat32ArrayInto is ReadFloat32Array, reusing dst if it has room.
+func (b *Buffer) ReadFloat32ArrayInto(dst []float32) []float32 {
+	data, ok := b.readArrayBytes(SIZEOF_INT32)
+	if !ok {
+		return nil
+	}
+
+	var arr []float32
+	if n := len(data) / SIZEOF_INT32; dst != nil && cap(dst) >= n {
+		arr = dst[:n]
+	} else {
+		arr = make([]float32, n)
+	}
+	if isLittleEndian && len(arr) > 0 {
+		copy(bytesOf(unsafe.Pointer(&arr[0]), len(data)), data)
+		return arr
+	}
+
+	for i := range arr {
+		arr[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*SIZEOF_INT32:]))
+	}
+
+	return arr
+}
+
+func (b *Buffer) ReadInt64Array() []int64 {
+	return b.ReadInt64ArrayInto(nil)
+}
+
+// ReadInt64ArrayInto is ReadInt64Array, reusing dst if it has room.
+func (b *Buffer) ReadInt64ArrayInto(dst []int64) []int64 {
+	data, ok := b.readArrayBytes(SIZEOF_INT64)
+	if !ok {
+		return nil
+	}
+
+	var arr []int64
+	if n := len(data) / SIZEOF_INT64; dst != nil && cap(dst) >= n {
+		arr = dst[:n]
+	} else {
+		arr = make([]int64, n)
+	}
+	if isLittleEndian && len(arr) > 0 {
+		copy(bytesOf(unsafe.Pointer(&arr[0]), len(data)), data)
+		return arr
+	}
+
+	for i := range arr {
+		arr[i] = int64(binary.LittleEndian.Uint64(data[i*SIZEOF_INT64:]))
+	}
+
+	return arr
+}
+
+func (b *Buffer) ReadUInt64Array() []uint64 {
+	return b.ReadUInt64ArrayInto(nil)
+}
+
+// ReadUInt64ArrayInto is ReadUInt64Array, reusing dst if it has room.
+func (b *Buffer) ReadUInt64ArrayInto(dst []uint64) []uint64 {
+	data, ok := b.readArrayBytes(SIZEOF_INT64)
+	if !ok {
+		return nil
+	}
+
+	var arr []uint64
+	if n := len(data) / SIZEOF_INT64; dst != nil && cap(dst) >= n {
+		arr = dst[:n]
+	} else {
+		arr = make([]uint64, n)
+	}
+	if isLittleEndian && len(arr) > 0 {
+		copy(bytesOf(unsafe.Pointer(&arr[0]), len(data)), data)
+		return arr
+	}
+
+	for i := range arr {
+		arr[i] = binary.LittleEndian.Uint64(data[i*SIZEOF_INT64:])
+	}
+
+	return arr
+}
+
+func (b *Buffer) ReadFloat64Array() []float64 {
+	return b.ReadFloat64ArrayInto(nil)
+}
+
+// ReadFloat64ArrayInto is ReadFloat64Array, reusing dst if it has room.
+func (b *Buffer) ReadFloat64ArrayInto(dst []float64) []float64 {
+	data, ok := b.readArrayBytes(SIZEOF_INT64)
+	if !ok {
+		return nil
+	}
 
-	arr := make([]float32, length)
+	var arr []float64
+	if n := len(data) / SIZEOF_INT64; dst != nil && cap(dst) >= n {
+		arr = dst[:n]
+	} else {
+		arr = make([]float64, n)
+	}
+	if isLittleEndian && len(arr) > 0 {
+		copy(bytesOf(unsafe.Pointer(&arr[0]), len(data)), data)
+		return arr
+	}
 
-	for i := uint(0); i < length; i++ {
-		arr[i] = float32(binary.LittleEndian.Uint32(b.Bytes.B[b.Offset:end]))
-		b.Offset += SIZEOF_INT32
+	for i := range arr {
+		arr[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[i*SIZEOF_INT64:]))
 	}
 
 	return arr
 }
+
+// readArrayBytes reads the byte length of a typed array and returns its bytes.
+// A length that isn't a whole number of elements is reported as an
+// *UnexpectedEOFError for the last, partial element.
+func (b *Buffer) readArrayBytes(size uint) ([]byte, bool) {
+	length := b.ReadVarUint()
+	start, ok := b.next(length)
+	if !ok {
+		return nil, false
+	}
+
+	if extra := length % size; extra != 0 {
+		b.err = &UnexpectedEOFError{Offset: b.Offset() - extra, Size: size}
+		return nil, false
+	}
+
+	return b.data.B[start:b.offset], true
+}
+
+// ReadArrayLength reads the length of an array whose elements each take at
+// least one byte. A length larger than the bytes left is reported through Err
+// and read as zero, so a hostile length can't force a huge allocation.
+func (b *Buffer) ReadArrayLength() uint {
+	length := b.ReadVarUint()
+	if b.err == nil && !b.fill(length) {
+		b.eof(length)
+	}
+	if b.err != nil {
+		return 0
+	}
+
+	return length
+}
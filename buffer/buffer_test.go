@@ -2,8 +2,15 @@ package buffer_test
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"strconv"
 	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/jarred-sumner/peechy/buffer"
 	"github.com/valyala/bytebufferpool"
@@ -12,11 +19,9 @@ import (
 func bufferReadVarUintAssert(t *testing.T, expected []byte, num uint) {
 	bb := bytebufferpool.ByteBuffer{B: expected}
 
-	buffer := buffer.Buffer{
-		&bb,
-		0,
-	}
-	val := buffer.ReadVarUint()
+	buf := buffer.NewBuffer(&bb)
+	buf.SetWireFormat(buffer.VarintFormat)
+	val := buf.ReadVarUint()
 
 	if val != num {
 		t.Logf("Expected %d to equal %d", val, num)
@@ -28,11 +33,9 @@ func bufferReadVarUintAssert(t *testing.T, expected []byte, num uint) {
 func bufferReadVarIntAssert(t *testing.T, expected []byte, num int) {
 	bb := bytebufferpool.ByteBuffer{B: expected}
 
-	buffer := buffer.Buffer{
-		&bb,
-		0,
-	}
-	val := buffer.ReadVarInt()
+	buf := buffer.NewBuffer(&bb)
+	buf.SetWireFormat(buffer.VarintFormat)
+	val := buf.ReadVarInt()
 
 	if val != num {
 		t.Logf("Expected %d to equal %d", val, num)
@@ -44,14 +47,12 @@ func bufferReadVarIntAssert(t *testing.T, expected []byte, num int) {
 func bufferWriteVarUintAssert(t *testing.T, num uint, expected []byte) {
 	bb := bytebufferpool.Get()
 
-	buffer := buffer.Buffer{
-		bb,
-		0,
-	}
-	buffer.WriteVarUint(num)
+	buf := buffer.NewBuffer(bb)
+	buf.SetWireFormat(buffer.VarintFormat)
+	buf.WriteVarUint(num)
 
-	if !bytes.Equal(expected, buffer.Bytes.B[0:buffer.Offset]) {
-		t.Logf("Expected %d to equal %d", expected, buffer.Bytes.B[0:buffer.Offset])
+	if !bytes.Equal(expected, buf.Bytes()) {
+		t.Logf("Expected %d to equal %d", expected, buf.Bytes())
 		t.FailNow()
 	}
 
@@ -61,14 +62,12 @@ func bufferWriteVarUintAssert(t *testing.T, num uint, expected []byte) {
 func bufferWriteIntAssert(t *testing.T, num int, expected []byte) {
 	bb := bytebufferpool.Get()
 
-	buffer := buffer.Buffer{
-		bb,
-		0,
-	}
-	buffer.WriteVarInt(num)
+	buf := buffer.NewBuffer(bb)
+	buf.SetWireFormat(buffer.VarintFormat)
+	buf.WriteVarInt(num)
 
-	if !bytes.Equal(expected, buffer.Bytes.B[0:buffer.Offset]) {
-		t.Logf("Expected %d to equal %d", expected, buffer.Bytes.B[0:buffer.Offset])
+	if !bytes.Equal(expected, buf.Bytes()) {
+		t.Logf("Expected %d to equal %d", expected, buf.Bytes())
 		t.FailNow()
 	}
 
@@ -79,10 +78,7 @@ func BenchmarkWriteVarInt(b *testing.B) {
 
 	bb := bytebufferpool.Get()
 
-	var buffer = buffer.Buffer{
-		bb,
-		0,
-	}
+	var buffer = buffer.NewBuffer(bb)
 
 	for i := 0; i < b.N; i++ {
 		if i%1024 == 0 {
@@ -98,10 +94,7 @@ func BenchmarkReadVarInt(b *testing.B) {
 
 	bb := bytebufferpool.Get()
 
-	var buffer = buffer.Buffer{
-		bb,
-		0,
-	}
+	var buffer = buffer.NewBuffer(bb)
 
 	for i := 0; i < 1024; i++ {
 		buffer.WriteVarInt(i * 8)
@@ -109,7 +102,7 @@ func BenchmarkReadVarInt(b *testing.B) {
 
 	for i := 0; i < b.N; i++ {
 		if i%1024 == 0 {
-			buffer.Offset = 0
+			buffer.Rewind()
 		}
 		buffer.ReadVarInt()
 	}
@@ -141,14 +134,11 @@ func TestBufferReadVarInt(t *testing.T) {
 func bufferWriteAsciiAssert(t *testing.T, s string, expected []byte) {
 	bb := bytebufferpool.Get()
 
-	buffer := buffer.Buffer{
-		bb,
-		0,
-	}
+	buffer := buffer.NewBuffer(bb)
 	buffer.WriteAlphanumeric(s)
 
-	if !bytes.Equal(expected, buffer.Bytes.B[0:buffer.Offset]) {
-		t.Logf("Expected %d to equal %d", expected, buffer.Bytes.B[0:buffer.Offset])
+	if !bytes.Equal(expected, buffer.Bytes()) {
+		t.Logf("Expected %d to equal %d", expected, buffer.Bytes())
 		t.FailNow()
 	}
 
@@ -158,13 +148,9 @@ func bufferWriteAsciiAssert(t *testing.T, s string, expected []byte) {
 func bufferReadAsciiAssert(t *testing.T, expected []byte, s string) {
 	bb := bytebufferpool.Get()
 
-	buffer := buffer.Buffer{
-		bb,
-		0,
-	}
+	buffer := buffer.NewBuffer(bb)
 
 	buffer.WriteAlphanumeric(s)
-	buffer.Offset = 0
 	val := buffer.ReadAlphanumeric()
 
 	if !strings.EqualFold(val, s) {
@@ -177,14 +163,11 @@ func bufferReadAsciiAssert(t *testing.T, expected []byte, s string) {
 func bufferWriteStringAssert(t *testing.T, s string, expected []byte) {
 	bb := bytebufferpool.Get()
 
-	buffer := buffer.Buffer{
-		bb,
-		0,
-	}
+	buffer := buffer.NewBuffer(bb)
 
 	buffer.WriteString(s)
-	if !bytes.EqualFold(expected, bb.B[0:buffer.Offset]) {
-		t.Logf("Expected %d to equal %d", expected, bb.B[0:buffer.Offset])
+	if !bytes.EqualFold(expected, buffer.Bytes()) {
+		t.Logf("Expected %d to equal %d", expected, buffer.Bytes())
 		t.FailNow()
 	}
 	bytebufferpool.Put(bb)
@@ -195,10 +178,7 @@ func bufferReadStringAssert(t *testing.T, s string, expected []byte) {
 		B: expected,
 	}
 
-	buffer := buffer.Buffer{
-		&bb,
-		0,
-	}
+	buffer := buffer.NewBuffer(&bb)
 
 	val := buffer.ReadString()
 
@@ -256,15 +236,101 @@ func TestBufferWriteVarUint(t *testing.T) {
 }
 
 func TestBufferReadString(t *testing.T) {
-	bufferReadStringAssert(t, "", []byte{0})
-	bufferReadStringAssert(t, "abc", []byte{97, 98, 99, 0})
-	bufferReadStringAssert(t, "🙉🙈🙊", []byte{240, 159, 153, 137, 240, 159, 153, 136, 240, 159, 153, 138, 0})
+	bufferReadStringAssert(t, "", []byte{0, 0, 0, 0})
+	bufferReadStringAssert(t, "abc", []byte{3, 0, 0, 0, 97, 98, 99})
+	bufferReadStringAssert(t, "a\x00b", []byte{3, 0, 0, 0, 97, 0, 98})
+	bufferReadStringAssert(t, "🙉🙈🙊", []byte{12, 0, 0, 0, 240, 159, 153, 137, 240, 159, 153, 136, 240, 159, 153, 138})
 }
 
 func TestBufferWriteString(t *testing.T) {
-	bufferWriteStringAssert(t, "", []byte{0})
-	bufferWriteStringAssert(t, "abc", []byte{97, 98, 99, 0})
-	bufferWriteStringAssert(t, "🙉🙈🙊", []byte{240, 159, 153, 137, 240, 159, 153, 136, 240, 159, 153, 138, 0})
+	bufferWriteStringAssert(t, "", []byte{0, 0, 0, 0})
+	bufferWriteStringAssert(t, "abc", []byte{3, 0, 0, 0, 97, 98, 99})
+	bufferWriteStringAssert(t, "a\x00b", []byte{3, 0, 0, 0, 97, 0, 98})
+	bufferWriteStringAssert(t, "🙉🙈🙊", []byte{12, 0, 0, 0, 240, 159, 153, 137, 240, 159, 153, 136, 240, 159, 153, 138})
+}
+
+type jsFixture struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+	Bytes []int           `json:"bytes"`
+}
+
+// These come from test/go-fixtures.js, which encodes them with js/bb.ts.
+func readJSFixtures(t *testing.T, typeName string) []jsFixture {
+	contents, err := ioutil.ReadFile("../test/go-fixtures.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var all []jsFixture
+	if err = json.Unmarshal(contents, &all); err != nil {
+		t.Fatal(err)
+	}
+
+	fixtures := make([]jsFixture, 0, len(all))
+	for _, fixture := range all {
+		if fixture.Type == typeName {
+			fixtures = append(fixtures, fixture)
+		}
+	}
+
+	if len(fixtures) == 0 {
+		t.Fatalf("No fixtures for %s", typeName)
+	}
+
+	return fixtures
+}
+
+func (f jsFixture) bytes() []byte {
+	out := make([]byte, len(f.Bytes))
+	for i, b := range f.Bytes {
+		out[i] = byte(b)
+	}
+	return out
+}
+
+func TestBufferStringMatchesJS(t *testing.T) {
+	for _, fixture := range readJSFixtures(t, "StringStruct") {
+		var value struct{ X string }
+		if err := json.Unmarshal(fixture.Value, &value); err != nil {
+			t.Fatal(err)
+		}
+
+		bufferReadStringAssert(t, value.X, fixture.bytes())
+		bufferWriteStringAssert(t, value.X, fixture.bytes())
+	}
+
+	for _, fixture := range readJSFixtures(t, "StringArrayStruct") {
+		var value struct{ X []string }
+		if err := json.Unmarshal(fixture.Value, &value); err != nil {
+			t.Fatal(err)
+		}
+
+		bb := bytebufferpool.Get()
+		buf := buffer.NewBuffer(bb)
+
+		buf.WriteVarUint(uint(len(value.X)))
+		for _, s := range value.X {
+			buf.WriteString(s)
+		}
+
+		if !bytes.Equal(fixture.bytes(), buf.Slice()) {
+			t.Fatalf("Expected %v to equal %v", buf.Slice(), fixture.bytes())
+		}
+
+		length := buf.ReadVarUint()
+		if length != uint(len(value.X)) {
+			t.Fatalf("Expected %d to equal %d", length, len(value.X))
+		}
+
+		for _, s := range value.X {
+			if val := buf.ReadString(); val != s {
+				t.Fatalf("Expected %q to equal %q", val, s)
+			}
+		}
+
+		bytebufferpool.Put(bb)
+	}
 }
 
 func TestBufferReadVarUint(t *testing.T) {
@@ -288,47 +354,93 @@ func TestBufferReadVarUint(t *testing.T) {
 	bufferReadVarUintAssert(t, []byte{255, 255, 255, 255, 15}, 4294967295)
 }
 
+func TestBufferVarIntMatchesJS(t *testing.T) {
+	for _, fixture := range readJSFixtures(t, "IntStruct") {
+		var value struct{ X int }
+		if err := json.Unmarshal(fixture.Value, &value); err != nil {
+			t.Fatal(err)
+		}
+
+		buf := buffer.FromBytes(nil)
+		buf.WriteVarInt(value.X)
+		if !bytes.Equal(fixture.bytes(), buf.Bytes()) {
+			t.Fatalf("Expected %v to equal %v", buf.Bytes(), fixture.bytes())
+		}
+
+		if val := buffer.FromBytes(fixture.bytes()).ReadVarInt(); val != value.X {
+			t.Fatalf("Expected %d to equal %d", val, value.X)
+		}
+	}
+
+	for _, fixture := range readJSFixtures(t, "UintStruct") {
+		var value struct{ X uint }
+		if err := json.Unmarshal(fixture.Value, &value); err != nil {
+			t.Fatal(err)
+		}
+
+		buf := buffer.FromBytes(nil)
+		buf.WriteVarUint(value.X)
+		if !bytes.Equal(fixture.bytes(), buf.Bytes()) {
+			t.Fatalf("Expected %v to equal %v", buf.Bytes(), fixture.bytes())
+		}
+
+		if val := buffer.FromBytes(fixture.bytes()).ReadVarUint(); val != value.X {
+			t.Fatalf("Expected %d to equal %d", val, value.X)
+		}
+	}
+}
+
+func TestBufferReadVarUintOverflow(t *testing.T) {
+	buf := buffer.FromBytes(bytes.Repeat([]byte{255}, 11))
+	buf.SetWireFormat(buffer.VarintFormat)
+
+	if val := buf.ReadVarUint(); val != 0 {
+		t.Fatalf("Expected %d to equal 0", val)
+	}
+
+	var overflow *buffer.VarintOverflowError
+	if !errors.As(buf.Err(), &overflow) {
+		t.Fatalf("Expected a VarintOverflowError, got %v", buf.Err())
+	}
+}
+
 func TestBufferWrite(t *testing.T) {
 	bb := bytebufferpool.Get()
 
-	buffer := buffer.Buffer{
-		bb,
-		0,
-	}
+	buffer := buffer.NewBuffer(bb)
 
 	buffer.WriteUint16(510)
 	buffer.WriteVarUint(1)
 	buffer.WriteVarUint(129)
 	buffer.WriteVarUint(512)
 
-	buffer.Offset = 0
 	var valueU16 uint16 = 0
-	t.Logf("buffer contents: %v", buffer.Bytes.B)
+	t.Logf("buffer contents: %v", buffer.Bytes())
 	valueU16 = buffer.ReadUint16()
 
 	if valueU16 != 510 {
-		t.Logf("Expected %d to equal 510 at %d", valueU16, buffer.Offset-2)
+		t.Logf("Expected %d to equal 510 at %d", valueU16, buffer.Offset()-2)
 		t.FailNow()
 	}
 
 	var valueU uint = buffer.ReadVarUint()
 
 	if valueU != 1 {
-		t.Logf("Expected %d to equal 1 at %d", valueU, buffer.Offset)
+		t.Logf("Expected %d to equal 1 at %d", valueU, buffer.Offset())
 		t.FailNow()
 	}
 
 	valueU = buffer.ReadVarUint()
 
 	if valueU != 129 {
-		t.Logf("Expected %d to equal 129 at %d", valueU, buffer.Offset)
+		t.Logf("Expected %d to equal 129 at %d", valueU, buffer.Offset())
 		t.FailNow()
 	}
 
 	valueU = buffer.ReadVarUint()
 
 	if valueU != 512 {
-		t.Logf("Expected %d to equal 512 at %d", valueU, buffer.Offset)
+		t.Logf("Expected %d to equal 512 at %d", valueU, buffer.Offset())
 		t.FailNow()
 	}
 
@@ -336,3 +448,637 @@ func TestBufferWrite(t *testing.T) {
 	// no longer used.
 	bytebufferpool.Put(bb)
 }
+
+func bufferReadTruncatedAssert(t *testing.T, name string, input []byte, offset uint, read func(*buffer.Buffer)) {
+	bb := bytebufferpool.ByteBuffer{B: input}
+	buf := buffer.NewBuffer(&bb)
+
+	read(buf)
+
+	var eof *buffer.UnexpectedEOFError
+	if !errors.As(buf.Err(), &eof) || !errors.Is(buf.Err(), io.ErrUnexpectedEOF) {
+		t.Fatalf("%s: expected an UnexpectedEOFError, got %v", name, buf.Err())
+	}
+
+	if eof.Offset != offset {
+		t.Fatalf("%s: expected the error at offset %d, got %d", name, offset, eof.Offset)
+	}
+}
+
+func TestBufferReadTruncated(t *testing.T) {
+	bufferReadTruncatedAssert(t, "ReadUint8", []byte{}, 0, func(b *buffer.Buffer) { b.ReadUint8() })
+	bufferReadTruncatedAssert(t, "ReadBool", []byte{}, 0, func(b *buffer.Buffer) { b.ReadBool() })
+	bufferReadTruncatedAssert(t, "ReadUint16", []byte{1}, 0, func(b *buffer.Buffer) { b.ReadUint16() })
+	bufferReadTruncatedAssert(t, "ReadUint32", []byte{1, 2, 3}, 0, func(b *buffer.Buffer) { b.ReadUint32() })
+	bufferReadTruncatedAssert(t, "ReadFloat32", []byte{1, 2, 3}, 0, func(b *buffer.Buffer) { b.ReadFloat32() })
+	bufferReadTruncatedAssert(t, "ReadString", []byte{3, 0, 0, 0, 97, 98}, 4, func(b *buffer.Buffer) { b.ReadString() })
+	bufferReadTruncatedAssert(t, "ReadAlphanumeric", []byte{97, 98}, 2, func(b *buffer.Buffer) { b.ReadAlphanumeric() })
+	bufferReadTruncatedAssert(t, "ReadByteArray", []byte{3, 0, 0, 0, 1}, 4, func(b *buffer.Buffer) { b.ReadByteArray() })
+	bufferReadTruncatedAssert(t, "ReadInt32Array", []byte{2, 0, 0, 0, 1, 0, 0, 0}, 4, func(b *buffer.Buffer) { b.ReadInt32Array() })
+	bufferReadTruncatedAssert(t, "ReadFloat32Array", []byte{255, 255, 255, 255}, 4, func(b *buffer.Buffer) { b.ReadFloat32Array() })
+	bufferReadTruncatedAssert(t, "ReadArrayLength", []byte{255, 255, 255, 255}, 4, func(b *buffer.Buffer) { b.ReadArrayLength() })
+
+	bufferReadTruncatedAssert(t, "sticky", []byte{1, 2, 3}, 2, func(b *buffer.Buffer) {
+		b.ReadUint16()
+		b.ReadUint16()
+
+		if value := b.ReadUint8(); value != 0 {
+			t.Fatalf("Expected reads after an error to return 0, got %d", value)
+		}
+	})
+}
+
+func TestBufferReadByte(t *testing.T) {
+	bb := bytebufferpool.ByteBuffer{B: []byte{7}}
+	buf := buffer.NewBuffer(&bb)
+
+	value, err := buf.ReadByte()
+	if value != 7 || err != nil {
+		t.Fatalf("Expected 7, <nil> but got %d, %v", value, err)
+	}
+
+	if _, err = buf.ReadByte(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("Expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestBufferWriteThenRead(t *testing.T) {
+	bb := bytebufferpool.Get()
+	defer bytebufferpool.Put(bb)
+
+	buf := buffer.NewBuffer(bb)
+
+	buf.WriteByteArray([]byte{1, 2, 3})
+	buf.WriteInt32Array([]int32{-1, 2})
+	buf.WriteString("abc")
+
+	if buf.Len() != len(buf.Bytes()) || buf.Len() != 4+3+4+8+4+3 {
+		t.Fatalf("Expected Len() to count every written byte, got %d for %v", buf.Len(), buf.Bytes())
+	}
+
+	if buf.Remaining() != buf.Len() || buf.Offset() != 0 {
+		t.Fatalf("Expected writes to leave the read offset alone, got %d", buf.Offset())
+	}
+
+	if value := buf.ReadByteArray(); !bytes.Equal(value, []byte{1, 2, 3}) {
+		t.Fatalf("Expected [1 2 3], got %v", value)
+	}
+
+	if buf.Remaining() != buf.Len()-7 {
+		t.Fatalf("Expected %d bytes left, got %d", buf.Len()-7, buf.Remaining())
+	}
+
+	if value := buf.ReadInt32Array(); len(value) != 2 || value[0] != -1 || value[1] != 2 {
+		t.Fatalf("Expected [-1 2], got %v", value)
+	}
+
+	if value := buf.ReadString(); value != "abc" {
+		t.Fatalf("Expected abc, got %q", value)
+	}
+
+	if buf.Remaining() != 0 || buf.Err() != nil {
+		t.Fatalf("Expected to read everything, %d bytes left: %v", buf.Remaining(), buf.Err())
+	}
+
+	buf.Rewind()
+	if buf.Remaining() != buf.Len() {
+		t.Fatalf("Expected Rewind to move back to the start")
+	}
+}
+
+func parseFixtureFloat(t *testing.T, raw json.RawMessage) float32 {
+	var text string
+	if err := json.Unmarshal(raw, &text); err != nil {
+		text = string(raw)
+	}
+
+	value, err := strconv.ParseFloat(text, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return float32(value)
+}
+
+func TestBufferVarFloatMatchesJS(t *testing.T) {
+	for _, fixture := range readJSFixtures(t, "FloatStruct") {
+		var value struct{ X json.RawMessage }
+		if err := json.Unmarshal(fixture.Value, &value); err != nil {
+			t.Fatal(err)
+		}
+		expected := parseFixtureFloat(t, value.X)
+
+		buf := buffer.FromBytes(fixture.bytes())
+		decoded := buf.ReadVarFloat()
+		if buf.Err() != nil || buf.Remaining() != 0 {
+			t.Fatalf("Expected to read all of %v: %v", fixture.bytes(), buf.Err())
+		}
+
+		if math.Float32bits(decoded) != math.Float32bits(expected) {
+			t.Fatalf("Expected %v to decode to %v, got %v", fixture.bytes(), expected, decoded)
+		}
+
+		buf = buffer.FromBytes(nil)
+		buf.WriteVarFloat(expected)
+		if !bytes.Equal(buf.Bytes(), fixture.bytes()) {
+			t.Fatalf("Expected %v to encode to %v, got %v", expected, fixture.bytes(), buf.Bytes())
+		}
+	}
+}
+
+func TestBufferVarFloat(t *testing.T) {
+	values := []float32{
+		0, 1, -1, 0.5, 1e-30, -1e30,
+		math.MaxFloat32, math.SmallestNonzeroFloat32,
+		float32(math.Inf(1)), float32(math.Inf(-1)), float32(math.NaN()),
+	}
+
+	buf := buffer.FromBytes(nil)
+	for _, value := range values {
+		buf.WriteVarFloat(value)
+	}
+
+	for _, value := range values {
+		decoded := buf.ReadVarFloat()
+
+		switch {
+		case value == math.SmallestNonzeroFloat32:
+			// Denormals are written as zero
+			if decoded != 0 {
+				t.Fatalf("Expected the denormal %v to decode as 0, got %v", value, decoded)
+			}
+		case math.IsNaN(float64(value)):
+			if !math.IsNaN(float64(decoded)) {
+				t.Fatalf("Expected NaN, got %v", decoded)
+			}
+		case decoded != value:
+			t.Fatalf("Expected %v, got %v", value, decoded)
+		}
+	}
+
+	if buf.Remaining() != 0 || buf.Err() != nil {
+		t.Fatalf("Expected to read everything, %d bytes left: %v", buf.Remaining(), buf.Err())
+	}
+
+	// The first byte is zero only for zero, so a truncated non-zero float is an error
+	buf = buffer.FromBytes([]byte{127, 0})
+	buf.ReadVarFloat()
+	if !errors.Is(buf.Err(), io.ErrUnexpectedEOF) {
+		t.Fatalf("Expected io.ErrUnexpectedEOF, got %v", buf.Err())
+	}
+}
+
+func TestBufferLowpFloat(t *testing.T) {
+	values := []float64{0, 0.001, -1.5, 123456.789, -2147483.648}
+
+	for _, format := range []buffer.WireFormat{buffer.FixedWidthFormat, buffer.VarintFormat} {
+		buf := buffer.FromBytes(nil)
+		buf.SetWireFormat(format)
+		for _, value := range values {
+			buf.WriteLowpFloat(value)
+		}
+
+		for _, value := range values {
+			if decoded := buf.ReadLowpFloat(); decoded != float32(value) {
+				t.Fatalf("Expected %v, got %v", value, decoded)
+			}
+		}
+
+		if buf.Remaining() != 0 || buf.Err() != nil {
+			t.Fatalf("Expected to read everything, %d bytes left: %v", buf.Remaining(), buf.Err())
+		}
+	}
+}
+
+func TestBuffer64Bit(t *testing.T) {
+	buf := buffer.FromBytes(nil)
+
+	buf.WriteInt64(math.MinInt64)
+	buf.WriteUint64(math.MaxUint64)
+	buf.WriteFloat64(math.Pi)
+	buf.WriteVarInt64(-1)
+	buf.WriteVarInt64(math.MaxInt64)
+	buf.WriteVarUint64(math.MaxUint64)
+
+	expected := []byte{
+		0, 0, 0, 0, 0, 0, 0, 128,
+		255, 255, 255, 255, 255, 255, 255, 255,
+		24, 45, 68, 84, 251, 33, 9, 64,
+		1,
+		254, 255, 255, 255, 255, 255, 255, 255, 255, 1,
+		255, 255, 255, 255, 255, 255, 255, 255, 255, 1,
+	}
+	if !bytes.Equal(expected, buf.Bytes()) {
+		t.Fatalf("Expected %v to equal %v", buf.Bytes(), expected)
+	}
+
+	if value := buf.ReadInt64(); value != math.MinInt64 {
+		t.Fatalf("Expected %d to equal %d", value, int64(math.MinInt64))
+	}
+	if value := buf.ReadUint64(); value != math.MaxUint64 {
+		t.Fatalf("Expected %d to equal %d", value, uint64(math.MaxUint64))
+	}
+	if value := buf.ReadFloat64(); value != math.Pi {
+		t.Fatalf("Expected %v to equal %v", value, math.Pi)
+	}
+	if value := buf.ReadVarInt64(); value != -1 {
+		t.Fatalf("Expected %d to equal -1", value)
+	}
+	if value := buf.ReadVarInt64(); value != math.MaxInt64 {
+		t.Fatalf("Expected %d to equal %d", value, int64(math.MaxInt64))
+	}
+	if value := buf.ReadVarUint64(); value != math.MaxUint64 {
+		t.Fatalf("Expected %d to equal %d", value, uint64(math.MaxUint64))
+	}
+
+	buf.Reset()
+	buf.WriteInt64Array([]int64{-1, 1 << 40})
+	buf.WriteUInt64Array([]uint64{math.MaxUint64})
+	buf.WriteFloat64Array([]float64{0.1, -2})
+
+	if value := buf.ReadInt64Array(); len(value) != 2 || value[0] != -1 || value[1] != 1<<40 {
+		t.Fatalf("Expected [-1 %d], got %v", int64(1<<40), value)
+	}
+	if value := buf.ReadUInt64Array(); len(value) != 1 || value[0] != math.MaxUint64 {
+		t.Fatalf("Expected [%d], got %v", uint64(math.MaxUint64), value)
+	}
+	if value := buf.ReadFloat64Array(); len(value) != 2 || value[0] != 0.1 || value[1] != -2 {
+		t.Fatalf("Expected [0.1 -2], got %v", value)
+	}
+	if buf.Err() != nil {
+		t.Fatal(buf.Err())
+	}
+
+	overflow := buffer.FromBytes(append(bytes.Repeat([]byte{255}, 9), 2))
+	overflow.ReadVarUint64()
+
+	var err *buffer.VarintOverflowError
+	if !errors.As(overflow.Err(), &err) || err.Bits != 64 || err.Offset != 9 {
+		t.Fatalf("Expected a 64-bit VarintOverflowError at offset 9, got %v", overflow.Err())
+	}
+}
+
+func TestBufferTypedArrays(t *testing.T) {
+	for _, littleEndian := range []bool{true, false} {
+		restore := buffer.SetLittleEndian(littleEndian)
+
+		buf := buffer.FromBytes(nil)
+		buf.WriteInt8Array([]int8{-1, 2})
+		buf.WriteInt16Array([]int16{-2, 0x102})
+		buf.WriteUInt16Array([]uint16{0xFFFE})
+		buf.WriteInt32Array([]int32{-3, 0x1020304})
+		buf.WriteUInt32Array([]uint32{0xFFFFFFFD})
+		buf.WriteFloat32Array([]float32{1.5, float32(math.Inf(-1))})
+		buf.WriteInt64Array([]int64{-4})
+		buf.WriteUInt64Array([]uint64{0x0102030405060708})
+		buf.WriteFloat64Array([]float64{-0.5})
+		buf.WriteFloat32Array(nil)
+
+		expected := []byte{
+			2, 0, 0, 0, 255, 2,
+			4, 0, 0, 0, 254, 255, 2, 1,
+			2, 0, 0, 0, 254, 255,
+			8, 0, 0, 0, 253, 255, 255, 255, 4, 3, 2, 1,
+			4, 0, 0, 0, 253, 255, 255, 255,
+			8, 0, 0, 0, 0, 0, 192, 63, 0, 0, 128, 255,
+			8, 0, 0, 0, 252, 255, 255, 255, 255, 255, 255, 255,
+			8, 0, 0, 0, 8, 7, 6, 5, 4, 3, 2, 1,
+			8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 224, 191,
+			0, 0, 0, 0,
+		}
+		if !bytes.Equal(expected, buf.Bytes()) {
+			t.Fatalf("Expected %v to equal %v", buf.Bytes(), expected)
+		}
+
+		if value := buf.ReadInt8Array(); len(value) != 2 || value[0] != -1 || value[1] != 2 {
+			t.Fatalf("Expected [-1 2], got %v", value)
+		}
+		if value := buf.ReadInt16Array(); len(value) != 2 || value[0] != -2 || value[1] != 0x102 {
+			t.Fatalf("Expected [-2 258], got %v", value)
+		}
+		if value := buf.ReadUInt16Array(); len(value) != 1 || value[0] != 0xFFFE {
+			t.Fatalf("Expected [65534], got %v", value)
+		}
+		if value := buf.ReadInt32Array(); len(value) != 2 || value[0] != -3 || value[1] != 0x1020304 {
+			t.Fatalf("Expected [-3 16909060], got %v", value)
+		}
+		if value := buf.ReadUInt32Array(); len(value) != 1 || value[0] != 0xFFFFFFFD {
+			t.Fatalf("Expected [4294967293], got %v", value)
+		}
+		if value := buf.ReadFloat32Array(); len(value) != 2 || value[0] != 1.5 || !math.IsInf(float64(value[1]), -1) {
+			t.Fatalf("Expected [1.5 -Inf], got %v", value)
+		}
+		if value := buf.ReadInt64Array(); len(value) != 1 || value[0] != -4 {
+			t.Fatalf("Expected [-4], got %v", value)
+		}
+		if value := buf.ReadUInt64Array(); len(value) != 1 || value[0] != 0x0102030405060708 {
+			t.Fatalf("Expected [72623859790382856], got %v", value)
+		}
+		if value := buf.ReadFloat64Array(); len(value) != 1 || value[0] != -0.5 {
+			t.Fatalf("Expected [-0.5], got %v", value)
+		}
+		if value := buf.ReadFloat32Array(); value == nil || len(value) != 0 {
+			t.Fatalf("Expected an empty array, got %#v", value)
+		}
+		if buf.Err() != nil || buf.Remaining() != 0 {
+			t.Fatalf("Expected to read everything, %d bytes left: %v", buf.Remaining(), buf.Err())
+		}
+
+		restore()
+	}
+}
+
+func TestBufferTypedArrayPartialElement(t *testing.T) {
+	buf := buffer.FromBytes([]byte{6, 0, 0, 0, 1, 0, 0, 0, 2, 0})
+	if value := buf.ReadInt32Array(); value != nil {
+		t.Fatalf("Expected nil, got %v", value)
+	}
+
+	var eof *buffer.UnexpectedEOFError
+	if !errors.As(buf.Err(), &eof) || eof.Offset != 8 || eof.Size != 4 {
+		t.Fatalf("Expected an UnexpectedEOFError for 4 bytes at offset 8, got %v", buf.Err())
+	}
+}
+
+func TestBufferViews(t *testing.T) {
+	buf := buffer.FromBytes(nil)
+	buf.WriteStringBytes([]byte("abc"))
+	buf.WriteByteArray([]byte{1, 2})
+	buf.WriteAlphanumericBytes([]byte("xyz"))
+	buf.WriteByteArray([]byte{3, 4})
+
+	if !bytes.Equal(buf.Bytes(), []byte{3, 0, 0, 0, 97, 98, 99, 2, 0, 0, 0, 1, 2, 120, 121, 122, 0, 2, 0, 0, 0, 3, 4}) {
+		t.Fatalf("Unexpected bytes %v", buf.Bytes())
+	}
+
+	str := buf.ReadStringBytes()
+	view := buf.ReadByteArrayView()
+	alpha := buf.ReadAlphanumericBytes()
+	copied := buf.ReadByteArray()
+	if string(str) != "abc" || !bytes.Equal(view, []byte{1, 2}) || string(alpha) != "xyz" || !bytes.Equal(copied, []byte{3, 4}) {
+		t.Fatalf("Expected abc [1 2] xyz [3 4], got %q %v %q %v", str, view, alpha, copied)
+	}
+
+	// Views share memory with the buffer, copies don't.
+	buf.Bytes()[4] = 'A'
+	buf.Bytes()[11] = 9
+	buf.Bytes()[21] = 9
+	if string(str) != "Abc" || view[0] != 9 || copied[0] != 3 {
+		t.Fatalf("Expected views to alias the buffer, got %q %v %v", str, view, copied)
+	}
+
+	// Appending to a view must not overwrite the bytes after it.
+	_ = append(view, 0)
+	if buf.Bytes()[13] != 'x' {
+		t.Fatalf("Expected appending to a view to copy it")
+	}
+
+	if buf.Err() != nil || buf.Remaining() != 0 {
+		t.Fatalf("Expected to read everything, %d bytes left: %v", buf.Remaining(), buf.Err())
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestBufferStream(t *testing.T) {
+	var out bytes.Buffer
+	w := buffer.NewWriter(&out)
+
+	long := strings.Repeat("peechy", 1000)
+	for i := 0; i < 10; i++ {
+		w.WriteVarUint(uint(i))
+		w.WriteString(long)
+		w.WriteAlphanumeric("abc")
+		w.WriteFloat64Array([]float64{float64(i), -1})
+	}
+
+	if out.Len() == 0 {
+		t.Fatalf("Expected full chunks to be written before Flush")
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reading a byte at a time makes every read refill the buffer.
+	r := buffer.NewReader(iotest.OneByteReader(bytes.NewReader(out.Bytes())))
+	for i := 0; i < 10; i++ {
+		if value := r.ReadVarUint(); value != uint(i) {
+			t.Fatalf("Expected %d to equal %d", value, i)
+		}
+		if value := r.ReadString(); value != long {
+			t.Fatalf("Expected a %d byte string, got %d bytes", len(long), len(value))
+		}
+		if value := r.ReadAlphanumeric(); value != "abc" {
+			t.Fatalf("Expected abc, got %q", value)
+		}
+		if value := r.ReadFloat64Array(); len(value) != 2 || value[0] != float64(i) || value[1] != -1 {
+			t.Fatalf("Expected [%d -1], got %v", i, value)
+		}
+	}
+
+	if r.Err() != nil || r.Offset() != uint(out.Len()) {
+		t.Fatalf("Expected to read all %d bytes, read %d: %v", out.Len(), r.Offset(), r.Err())
+	}
+
+	r.ReadUint8()
+	var eof *buffer.UnexpectedEOFError
+	if !errors.As(r.Err(), &eof) || eof.Offset != uint(out.Len()) {
+		t.Fatalf("Expected an UnexpectedEOFError at offset %d, got %v", out.Len(), r.Err())
+	}
+}
+
+func TestBufferStreamErrors(t *testing.T) {
+	w := buffer.NewWriter(failingWriter{})
+	w.WriteString("abc")
+	if err := w.Flush(); err != io.ErrClosedPipe {
+		t.Fatalf("Expected io.ErrClosedPipe, got %v", err)
+	}
+	if err := w.WriteByte(1); err != io.ErrClosedPipe {
+		t.Fatalf("Expected WriteByte to return io.ErrClosedPipe, got %v", err)
+	}
+
+	// TimeoutReader fails its second read, which the second ReadUint64 needs.
+	r := buffer.NewReader(iotest.TimeoutReader(bytes.NewReader([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9})))
+	r.ReadUint64()
+	r.ReadUint64()
+	if r.Err() != iotest.ErrTimeout {
+		t.Fatalf("Expected iotest.ErrTimeout, got %v", r.Err())
+	}
+}
+
+func TestBufferSkipField(t *testing.T) {
+	types := buffer.Types{
+		"M": {Kind: buffer.MessageType, Fields: []buffer.TypeField{
+			{Number: 1, Type: "int"},
+			{Number: 2, Type: "string", Array: true},
+			{Number: 3, Type: "S"},
+			{Number: 4, Type: "int16", Array: true},
+		}},
+		"S": {Kind: buffer.StructType, Fields: []buffer.TypeField{{Type: "varuint64"}, {Type: "float"}, {Type: "E"}}},
+		"E": {Kind: buffer.EnumType},
+	}
+
+	for _, format := range []buffer.WireFormat{buffer.FixedWidthFormat, buffer.VarintFormat} {
+		buf := buffer.NewBuffer(bytebufferpool.Get())
+		buf.SetWireFormat(format)
+		buf.WriteVarInt(-300)
+		buf.WriteVarUint(2)
+		buf.WriteString("a")
+		buf.WriteString("")
+		buf.WriteVarUint64(1 << 40)
+		buf.WriteVarFloat(1.5)
+		buf.WriteVarUint(7)
+		buf.WriteInt16Array([]int16{1, -1})
+		buf.WriteByte(9)
+		data := append([]byte(nil), buf.Bytes()...)
+
+		buf = buffer.FromBytes(data)
+		buf.SetWireFormat(format)
+		buf.SetTypes(types)
+
+		// The skipped fields are written back the way they were read.
+		var skipped []byte
+		for field := uint8(1); field <= 4; field++ {
+			out, err := buf.SkipField("M", field)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out[0] != field {
+				t.Fatalf("Expected %v to start with %d", out, field)
+			}
+			skipped = append(skipped, out[1:]...)
+		}
+
+		if !bytes.Equal(skipped, data[:len(data)-1]) {
+			t.Fatalf("Expected %v to equal %v", skipped, data[:len(data)-1])
+		}
+		if val := buf.ReadUint8(); val != 9 {
+			t.Fatalf("Expected %d to equal 9", val)
+		}
+
+		buf = buffer.FromBytes(data)
+		buf.SetWireFormat(format)
+		if _, err := buf.SkipField("M", 1); err == nil || err.Error() != "peechy: unknown field 1 in M" {
+			t.Fatalf("Expected an unknown field without types, got %v", err)
+		}
+	}
+}
+
+func TestBufferSizes(t *testing.T) {
+	for _, format := range []buffer.WireFormat{buffer.FixedWidthFormat, buffer.VarintFormat} {
+		written := func(write func(b *buffer.Buffer)) int {
+			b := buffer.FromBytes(nil)
+			b.SetWireFormat(format)
+			write(b)
+			return len(b.Bytes())
+		}
+
+		for _, v := range []int{0, 1, -1, 63, -64, 64, 1 << 20, math.MaxInt32, math.MinInt32} {
+			if size, want := format.VarIntSize(v), written(func(b *buffer.Buffer) { b.WriteVarInt(v) }); size != want {
+				t.Errorf("VarIntSize(%d) = %d, want %d", v, size, want)
+			}
+			if v >= 0 {
+				if size, want := format.VarUintSize(uint(v)), written(func(b *buffer.Buffer) { b.WriteVarUint(uint(v)) }); size != want {
+					t.Errorf("VarUintSize(%d) = %d, want %d", v, size, want)
+				}
+			}
+		}
+
+		for _, v := range []int64{0, 1, -1, 127, -65, 1 << 40, math.MaxInt64, math.MinInt64} {
+			if size, want := buffer.VarInt64Size(v), written(func(b *buffer.Buffer) { b.WriteVarInt64(v) }); size != want {
+				t.Errorf("VarInt64Size(%d) = %d, want %d", v, size, want)
+			}
+			if size, want := buffer.VarUint64Size(uint64(v)), written(func(b *buffer.Buffer) { b.WriteVarUint64(uint64(v)) }); size != want {
+				t.Errorf("VarUint64Size(%d) = %d, want %d", uint64(v), size, want)
+			}
+		}
+
+		for _, v := range []float32{0, 1.5, -2, float32(math.Inf(1)), math.SmallestNonzeroFloat32} {
+			if size, want := buffer.VarFloatSize(v), written(func(b *buffer.Buffer) { b.WriteVarFloat(v) }); size != want {
+				t.Errorf("VarFloatSize(%v) = %d, want %d", v, size, want)
+			}
+		}
+
+		for _, v := range []float64{0, 0.001, -1.5, 123456.789} {
+			if size, want := format.LowpFloatSize(v), written(func(b *buffer.Buffer) { b.WriteLowpFloat(v) }); size != want {
+				t.Errorf("LowpFloatSize(%v) = %d, want %d", v, size, want)
+			}
+		}
+
+		for _, s := range []string{"", "peechy", strings.Repeat("x", 200)} {
+			if size, want := format.BytesSize(len(s)), written(func(b *buffer.Buffer) { b.WriteString(s) }); size != want {
+				t.Errorf("BytesSize(%d) = %d, want %d", len(s), size, want)
+			}
+		}
+	}
+}
+
+func TestBufferGrow(t *testing.T) {
+	b := buffer.FromBytes(nil)
+	b.WriteByte(1)
+	b.Grow(100)
+	if got := cap(b.Bytes()); got < 101 {
+		t.Fatalf("Expected room for 101 bytes, got %d", got)
+	}
+	if !bytes.Equal(b.Bytes(), []byte{1}) {
+		t.Fatalf("Expected Grow to keep what was written, got %v", b.Bytes())
+	}
+}
+
+func TestBufferReadArrayInto(t *testing.T) {
+	b := buffer.FromBytes(nil)
+	b.WriteInt32Array([]int32{1, 2, 3})
+	b.WriteInt32Array([]int32{4})
+	b.WriteByteArray([]byte("peechy"))
+
+	dst := make([]int32, 0, 3)
+	got := b.ReadInt32ArrayInto(dst)
+	if len(got) != 3 || &got[0] != &dst[:1][0] {
+		t.Fatalf("Expected the values in dst, got %v", got)
+	}
+	if got = b.ReadInt32ArrayInto(got); len(got) != 1 || got[0] != 4 || &got[0] != &dst[:1][0] {
+		t.Fatalf("Expected [4] in dst, got %v", got)
+	}
+
+	if data := b.ReadByteArrayInto(make([]byte, 0, 2)); string(data) != "peechy" {
+		t.Fatalf("Expected a dst without room to grow, got %q", data)
+	}
+	if err := b.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBufferByteArrayLength(t *testing.T) {
+	data := []byte("peechy")
+
+	// A slice's length is what gets written, not what is behind it.
+	b := buffer.FromBytes(nil)
+	b.WriteByteArray(data[1:3])
+	if want := []byte{2, 0, 0, 0, 'e', 'e'}; !bytes.Equal(b.Bytes(), want) {
+		t.Fatalf("Expected %v, got %v", want, b.Bytes())
+	}
+	if size := buffer.FixedWidthFormat.BytesSize(2); size != len(b.Bytes()) {
+		t.Fatalf("Expected BytesSize to be %d, got %d", len(b.Bytes()), size)
+	}
+
+	// Decoding into a value again leaves it room to spare, which must not
+	// show up when it is encoded.
+	b = buffer.FromBytes(nil)
+	b.WriteByteArray(data)
+	b.WriteByteArray(data[:2])
+	value := b.ReadByteArrayInto(nil)
+	value = b.ReadByteArrayInto(value)
+	if err := b.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	again := buffer.FromBytes(nil)
+	again.WriteByteArray(value)
+	if got := again.ReadByteArray(); string(got) != "pe" || again.Remaining() != 0 {
+		t.Fatalf("Expected \"pe\" and nothing after it, got %q and %d bytes", got, again.Remaining())
+	}
+}
@@ -0,0 +1,89 @@
+package buffer
+
+import (
+	"fmt"
+	"io"
+)
+
+// UnexpectedEOFError is returned by Err when a read needed more bytes than
+// were left in the buffer. errors.Is(err, io.ErrUnexpectedEOF) is true for it.
+type UnexpectedEOFError struct {
+	// Offset is where the failed read started.
+	Offset uint
+	// Size is how many bytes the read needed.
+	Size uint
+}
+
+func (e *UnexpectedEOFError) Error() string {
+	return fmt.Sprintf("peechy: unexpected EOF reading %d bytes at offset %d", e.Size, e.Offset)
+}
+
+func (e *UnexpectedEOFError) Is(target error) bool {
+	return target == io.ErrUnexpectedEOF
+}
+
+// VarintOverflowError is returned by Err when a varint has more bits than fit
+// in the integer it is read into.
+type VarintOverflowError struct {
+	// Offset is where the byte that overflowed starts.
+	Offset uint
+	// Bits is the size of the integer, 64 for ReadVarUint64.
+	Bits uint
+}
+
+func (e *VarintOverflowError) Error() string {
+	return fmt.Sprintf("peechy: varint overflows a %d-bit integer at offset %d", e.Bits, e.Offset)
+}
+
+// MissingFieldError is returned by generated code for a message without a
+// field that the schema marks as required with [!], both by DecodeX and by
+// Encode.
+type MissingFieldError struct {
+	// Message is the name of the message in the schema.
+	Message string
+	// Field is the name of the field in the schema.
+	Field string
+}
+
+func (e *MissingFieldError) Error() string {
+	return fmt.Sprintf("peechy: %s is missing required field %q", e.Message, e.Field)
+}
+
+// UnknownFieldError is returned by SkipField for a message field, or a union
+// member, that the Types from SetTypes don't describe.
+type UnknownFieldError struct {
+	// Message is the name of the message or union in the schema.
+	Message string
+	Field   uint8
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("peechy: unknown field %d in %s", e.Field, e.Message)
+}
+
+// UnknownTypeError is returned by SkipField for a type that isn't built in or
+// in the Types from SetTypes.
+type UnknownTypeError struct {
+	Name string
+}
+
+func (e *UnknownTypeError) Error() string {
+	return fmt.Sprintf("peechy: unknown type %q", e.Name)
+}
+
+// InvalidEnumError is returned by generated code for an enum name or value
+// that isn't in the schema.
+type InvalidEnumError struct {
+	// Enum is the name of the enum in the schema.
+	Enum string
+	// Name is set when parsing a name, and Value otherwise.
+	Name  string
+	Value uint
+}
+
+func (e *InvalidEnumError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("peechy: %q is not a %s", e.Name, e.Enum)
+	}
+	return fmt.Sprintf("peechy: %d is not a %s", e.Value, e.Enum)
+}
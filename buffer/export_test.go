@@ -0,0 +1,12 @@
+package buffer
+
+// SetLittleEndian overrides host byte order detection so tests can cover the
+// element-at-a-time typed array path on little-endian machines. It returns a
+// function that restores the real value.
+func SetLittleEndian(value bool) func() {
+	original := isLittleEndian
+	isLittleEndian = value
+	return func() {
+		isLittleEndian = original
+	}
+}
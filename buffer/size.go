@@ -0,0 +1,54 @@
+package buffer
+
+import (
+	"math"
+	"math/bits"
+)
+
+// The Size functions return how many bytes the matching Write method writes,
+// for generated EncodedSize methods.
+
+// VarUintSize returns how many bytes WriteVarUint writes for value.
+func (f WireFormat) VarUintSize(value uint) int {
+	if f == FixedWidthFormat {
+		return SIZEOF_INT32
+	}
+	return VarUint64Size(uint64(value))
+}
+
+// VarIntSize returns how many bytes WriteVarInt writes for value.
+func (f WireFormat) VarIntSize(value int) int {
+	if f == FixedWidthFormat {
+		return SIZEOF_INT32
+	}
+	return VarUint64Size(uint64(uint((value << 1) ^ (value >> (bits.UintSize - 1)))))
+}
+
+// BytesSize returns how many bytes WriteString or WriteByteArray write for a
+// value that is length bytes long.
+func (f WireFormat) BytesSize(length int) int {
+	return f.VarUintSize(uint(length)) + length
+}
+
+// LowpFloatSize returns how many bytes WriteLowpFloat writes for value.
+func (f WireFormat) LowpFloatSize(value float64) int {
+	return f.VarIntSize(int(math.Round(value * 1000)))
+}
+
+// VarUint64Size returns how many bytes WriteVarUint64 writes for value.
+func VarUint64Size(value uint64) int {
+	return (bits.Len64(value|1) + 6) / 7
+}
+
+// VarInt64Size returns how many bytes WriteVarInt64 writes for value.
+func VarInt64Size(value int64) int {
+	return VarUint64Size(uint64((value << 1) ^ (value >> 63)))
+}
+
+// VarFloatSize returns how many bytes WriteVarFloat writes for value.
+func VarFloatSize(value float32) int {
+	if math.Float32bits(value)>>23&255 == 0 {
+		return 1
+	}
+	return 4
+}
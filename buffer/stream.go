@@ -0,0 +1,213 @@
+package buffer
+
+import (
+	"io"
+
+	"github.com/valyala/bytebufferpool"
+)
+
+// streamChunkSize is how many bytes a Buffer from NewWriter collects before
+// flushing them, and the least a Buffer from NewReader asks its io.Reader for.
+const streamChunkSize = 4096
+
+// Writer is the method set generated Encode methods use. *Buffer implements
+// it, whether it was made with NewBuffer, FromBytes or NewWriter.
+type Writer interface {
+	WireFormat() WireFormat
+	Grow(n int)
+	Write(p []byte) (int, error)
+	WriteBool(value bool)
+	WriteByte(value byte) error
+	WriteUint8(value uint8)
+	WriteInt8(value int8)
+	WriteUint16(value uint16)
+	WriteInt16(value int16)
+	WriteUint32(value uint32)
+	WriteInt32(value int32)
+	WriteUint64(value uint64)
+	WriteInt64(value int64)
+	WriteVarUint(value uint)
+	WriteVarInt(value int)
+	WriteVarUint64(value uint64)
+	WriteVarInt64(value int64)
+	WriteVarFloat(value float32)
+	WriteFloat32(value float32)
+	WriteFloat64(value float64)
+	WriteLowpFloat(value float64)
+	WriteString(s string)
+	WriteStringBytes(s []byte)
+	WriteAlphanumeric(s string)
+	WriteAlphanumericBytes(s []byte)
+	WriteByteArray(value []byte)
+	WriteInt8Array(value []int8)
+	WriteInt16Array(value []int16)
+	WriteUInt16Array(value []uint16)
+	WriteInt32Array(value []int32)
+	WriteUInt32Array(value []uint32)
+	WriteFloat32Array(value []float32)
+	WriteInt64Array(value []int64)
+	WriteUInt64Array(value []uint64)
+	WriteFloat64Array(value []float64)
+}
+
+// Reader is the method set generated Decode functions use. *Buffer implements
+// it, whether it was made with NewBuffer, FromBytes or NewReader.
+type Reader interface {
+	ReadBool() bool
+	ReadByte() (byte, error)
+	ReadUint8() uint8
+	ReadInt8() int8
+	ReadUint16() uint16
+	ReadInt16() int16
+	ReadUint32() uint32
+	ReadInt32() int32
+	ReadUint64() uint64
+	ReadInt64() int64
+	ReadVarUint() uint
+	ReadVarInt() int
+	ReadVarUint64() uint64
+	ReadVarInt64() int64
+	ReadVarFloat() float32
+	ReadFloat32() float32
+	ReadFloat64() float64
+	ReadLowpFloat() float32
+	ReadString() string
+	ReadStringBytes() []byte
+	ReadAlphanumeric() string
+	ReadAlphanumericBytes() []byte
+	ReadByteArray() []byte
+	ReadByteArrayInto(dst []byte) []byte
+	ReadByteArrayView() []byte
+	ReadInt8Array() []int8
+	ReadInt8ArrayInto(dst []int8) []int8
+	ReadInt16Array() []int16
+	ReadInt16ArrayInto(dst []int16) []int16
+	ReadUInt16Array() []uint16
+	ReadUInt16ArrayInto(dst []uint16) []uint16
+	ReadInt32Array() []int32
+	ReadInt32ArrayInto(dst []int32) []int32
+	ReadUInt32Array() []uint32
+	ReadUInt32ArrayInto(dst []uint32) []uint32
+	ReadFloat32Array() []float32
+	ReadFloat32ArrayInto(dst []float32) []float32
+	ReadInt64Array() []int64
+	ReadInt64ArrayInto(dst []int64) []int64
+	ReadUInt64Array() []uint64
+	ReadUInt64ArrayInto(dst []uint64) []uint64
+	ReadFloat64Array() []float64
+	ReadFloat64ArrayInto(dst []float64) []float64
+	ReadArrayLength() uint
+	SkipField(message string, field uint8) ([]byte, error)
+	Err() error
+}
+
+var (
+	_ Writer = (*Buffer)(nil)
+	_ Reader = (*Buffer)(nil)
+)
+
+// NewWriter returns a Buffer that writes to w in chunks instead of holding
+// everything in memory. Call Flush when done to write the last chunk.
+// The first error from w is returned by Flush, WriteByte and Err, and
+// everything written after it is dropped.
+func NewWriter(w io.Writer) *Buffer {
+	return &Buffer{
+		data: &bytebufferpool.ByteBuffer{B: make([]byte, 0, streamChunkSize)},
+		sink: w,
+	}
+}
+
+// NewReader returns a Buffer that reads from r as it needs more bytes.
+// Errors from r other than io.EOF are returned by Err.
+//
+// The slices from ReadStringBytes, ReadAlphanumericBytes and ReadByteArrayView
+// are only valid until the next read, since reading can move the bytes that
+// are left to make room.
+func NewReader(r io.Reader) *Buffer {
+	return &Buffer{
+		data:   &bytebufferpool.ByteBuffer{B: make([]byte, 0, streamChunkSize)},
+		source: r,
+	}
+}
+
+// Flush writes everything written so far to the io.Writer of a Buffer from
+// NewWriter. It does nothing for other Buffers.
+func (b *Buffer) Flush() error {
+	if b.sink == nil {
+		return nil
+	}
+
+	if b.err == nil && len(b.data.B) > 0 {
+		if _, err := b.sink.Write(b.data.B); err != nil {
+			b.err = err
+		}
+	}
+
+	b.discarded += uint(len(b.data.B))
+	b.data.Reset()
+	return b.err
+}
+
+func (b *Buffer) write(p []byte) {
+	b.data.Write(p)
+	b.wrote()
+}
+
+func (b *Buffer) writeByte(c byte) {
+	b.data.WriteByte(c)
+	b.wrote()
+}
+
+func (b *Buffer) writeString(s string) {
+	b.data.WriteString(s)
+	b.wrote()
+}
+
+// wrote flushes a Buffer from NewWriter once it has a full chunk.
+func (b *Buffer) wrote() {
+	if b.sink != nil && len(b.data.B) >= streamChunkSize {
+		b.Flush()
+	}
+}
+
+// fill makes sure at least n bytes are left to read, reading more from the
+// io.Reader of a Buffer from NewReader if it has to. It returns false when
+// the data ends first.
+func (b *Buffer) fill(n uint) bool {
+	left := uint(len(b.data.B)) - b.offset
+	if n <= left {
+		return true
+	}
+
+	if b.source == nil || b.err != nil {
+		return false
+	}
+
+	// Drop what has been read already to make room.
+	if b.offset > 0 {
+		copy(b.data.B, b.data.B[b.offset:])
+		b.data.B = b.data.B[:left]
+		b.discarded += b.offset
+		b.offset = 0
+	}
+
+	for uint(len(b.data.B)) < n {
+		if len(b.data.B) == cap(b.data.B) {
+			grown := make([]byte, len(b.data.B), 2*cap(b.data.B)+streamChunkSize)
+			copy(grown, b.data.B)
+			b.data.B = grown
+		}
+
+		read, err := b.source.Read(b.data.B[len(b.data.B):cap(b.data.B)])
+		b.data.B = b.data.B[:len(b.data.B)+read]
+
+		if err == io.EOF {
+			return uint(len(b.data.B)) >= n
+		} else if err != nil {
+			b.err = err
+			return false
+		}
+	}
+
+	return true
+}
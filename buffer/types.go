@@ -0,0 +1,205 @@
+package buffer
+
+import "github.com/valyala/bytebufferpool"
+
+// Types describes the definitions of a schema by how they are laid out on the
+// wire, keyed by name. Generated code has one for its schema, called
+// SchemaTypes.
+//
+// A Buffer given the Types of the schema its data was encoded with can skip
+// message fields that the generated code decoding it doesn't know, which
+// happens when the data comes from a newer version of the schema.
+type Types map[string]*Type
+
+// TypeKind is the kind of a definition in Types.
+type TypeKind byte
+
+const (
+	EnumType TypeKind = iota + 1
+	SmolType
+	StructType
+	MessageType
+	UnionType
+)
+
+// Type is a definition in Types.
+type Type struct {
+	Kind TypeKind
+	// Fields are the fields of a struct in order, the fields of a message or
+	// the members of a union. Enums don't have any.
+	Fields []TypeField
+}
+
+// TypeField is a field of a struct or message, or a member of a union.
+type TypeField struct {
+	// Number is the field number of a message field or the type of a union
+	// member. It isn't used for structs.
+	Number uint
+	// Type is a built-in type like "string", or the name of another
+	// definition in the same Types.
+	Type  string
+	Array bool
+}
+
+// Field returns the message field or union member with the given number.
+func (t *Type) Field(number uint) (TypeField, bool) {
+	for _, field := range t.Fields {
+		if field.Number == number {
+			return field, true
+		}
+	}
+	return TypeField{}, false
+}
+
+// SetTypes sets the Types that SkipField uses. They should be for the same
+// schema as the generated code decoding the Buffer, or a newer version of it.
+func (b *Buffer) SetTypes(types Types) {
+	b.types = types
+}
+
+// SkipField reads the value of a field in message that generated code doesn't
+// know, with the Types from SetTypes. It returns the field number followed by
+// the value, for Encode to write back unchanged. Without Types describing the
+// field, it returns an *UnknownFieldError and the Buffer can't be read further.
+func (b *Buffer) SkipField(message string, field uint8) ([]byte, error) {
+	if t := b.types[message]; t != nil && t.Kind == MessageType {
+		if f, ok := t.Field(uint(field)); ok {
+			out := &Buffer{data: &bytebufferpool.ByteBuffer{}, format: b.format}
+			out.WriteByte(field)
+
+			if err := b.skipField(out, f); err != nil {
+				return nil, err
+			}
+			return out.data.B, b.err
+		}
+	}
+
+	if b.err != nil {
+		return nil, b.err
+	}
+	return nil, &UnknownFieldError{Message: message, Field: field}
+}
+
+// typedArrays are written as their length in bytes followed by the values.
+var typedArrays = map[string]bool{
+	"byte":    true,
+	"int8":    true,
+	"int16":   true,
+	"uint16":  true,
+	"int32":   true,
+	"uint32":  true,
+	"float32": true,
+	"int64":   true,
+	"uint64":  true,
+	"float64": true,
+}
+
+// skipField reads a field and writes it again to out, which has the same wire
+// format, so that out ends up with the same bytes.
+func (b *Buffer) skipField(out *Buffer, field TypeField) error {
+	if !field.Array {
+		return b.skipValue(out, field.Type)
+	}
+
+	if typedArrays[field.Type] {
+		out.WriteByteArray(b.ReadByteArrayView())
+		return b.err
+	}
+
+	// Structs can be empty, so their length can't be checked against what
+	// is left to read.
+	var length uint
+	if t := b.types[field.Type]; t != nil && t.Kind == StructType {
+		length = b.ReadVarUint()
+	} else {
+		length = b.ReadArrayLength()
+	}
+
+	out.WriteVarUint(length)
+	for i := uint(0); i < length && b.err == nil; i++ {
+		if err := b.skipValue(out, field.Type); err != nil {
+			return err
+		}
+	}
+	return b.err
+}
+
+func (b *Buffer) skipValue(out *Buffer, typ string) error {
+	switch typ {
+	case "bool", "byte", "int8", "uint8":
+		out.WriteUint8(b.ReadUint8())
+	case "int16", "uint16":
+		out.WriteUint16(b.ReadUint16())
+	case "int32", "uint32", "float32":
+		out.WriteUint32(b.ReadUint32())
+	case "int64", "uint64", "float64":
+		out.WriteUint64(b.ReadUint64())
+	case "int", "uint", "lowp":
+		out.WriteVarUint(b.ReadVarUint())
+	case "varint64", "varuint64":
+		out.WriteVarUint64(b.ReadVarUint64())
+	case "float":
+		out.WriteVarFloat(b.ReadVarFloat())
+	case "string":
+		out.WriteStringBytes(b.ReadStringBytes())
+	case "alphanumeric":
+		out.WriteAlphanumericBytes(b.ReadAlphanumericBytes())
+	default:
+		return b.skipDefinition(out, typ)
+	}
+	return b.err
+}
+
+func (b *Buffer) skipDefinition(out *Buffer, name string) error {
+	t := b.types[name]
+	if t == nil {
+		return &UnknownTypeError{Name: name}
+	}
+
+	switch t.Kind {
+	case EnumType:
+		out.WriteVarUint(b.ReadVarUint())
+
+	case SmolType:
+		out.WriteUint8(b.ReadUint8())
+
+	case StructType:
+		for _, field := range t.Fields {
+			if err := b.skipField(out, field); err != nil {
+				return err
+			}
+		}
+
+	case MessageType:
+		for {
+			number := b.ReadUint8()
+			out.WriteUint8(number)
+			if number == 0 || b.err != nil {
+				break
+			}
+
+			field, ok := t.Field(uint(number))
+			if !ok {
+				return &UnknownFieldError{Message: name, Field: number}
+			}
+			if err := b.skipField(out, field); err != nil {
+				return err
+			}
+		}
+
+	case UnionType:
+		number := b.ReadUint8()
+		out.WriteUint8(number)
+		if b.err != nil {
+			break
+		}
+
+		member, ok := t.Field(uint(number))
+		if !ok {
+			return &UnknownFieldError{Message: name, Field: number}
+		}
+		return b.skipValue(out, member.Type)
+	}
+
+	return b.err
+}
@@ -0,0 +1,253 @@
+package dynamic
+
+import (
+	"reflect"
+
+	"github.com/jarred-sumner/peechy/buffer"
+	"github.com/jarred-sumner/peechy/schema"
+)
+
+// Decode reads a value of the struct, message or union called root from buf.
+func (c *Codec) Decode(root string, buf buffer.Reader) (interface{}, error) {
+	definition, err := c.root(root)
+	if err != nil {
+		return nil, err
+	}
+	return c.decodeDefinition(buf, definition)
+}
+
+func (c *Codec) decodeField(buf buffer.Reader, field *schema.Field) (interface{}, error) {
+	fieldType := c.resolve(field.Type)
+	if !field.IsArray {
+		return c.decodeValue(buf, fieldType)
+	}
+
+	// Typed arrays are read in one go.
+	var typed interface{}
+	switch fieldType {
+	case "byte":
+		return buf.ReadByteArray(), buf.Err()
+	case "int8":
+		typed = buf.ReadInt8Array()
+	case "int16":
+		typed = buf.ReadInt16Array()
+	case "uint16":
+		typed = buf.ReadUInt16Array()
+	case "int32":
+		typed = buf.ReadInt32Array()
+	case "uint32":
+		typed = buf.ReadUInt32Array()
+	case "float32":
+		typed = buf.ReadFloat32Array()
+	case "int64":
+		typed = buf.ReadInt64Array()
+	case "uint64":
+		typed = buf.ReadUInt64Array()
+	case "float64":
+		typed = buf.ReadFloat64Array()
+	}
+	if typed != nil {
+		if err := buf.Err(); err != nil {
+			return nil, err
+		}
+		items := reflect.ValueOf(typed)
+		result := make([]interface{}, items.Len())
+		for i := range result {
+			result[i] = items.Index(i).Interface()
+		}
+		return result, nil
+	}
+
+	var length uint
+	if c.canBeEmpty(fieldType) {
+		length = buf.ReadVarUint()
+	} else {
+		length = buf.ReadArrayLength()
+	}
+	if err := buf.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]interface{}, length)
+	for i := range result {
+		value, err := c.decodeValue(buf, fieldType)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = value
+	}
+	return result, nil
+}
+
+func (c *Codec) decodeValue(buf buffer.Reader, fieldType string) (interface{}, error) {
+	var value interface{}
+	switch fieldType {
+	case "bool":
+		value = buf.ReadBool()
+	case "byte", "uint8":
+		value = buf.ReadUint8()
+	case "int8":
+		value = buf.ReadInt8()
+	case "int16":
+		value = buf.ReadInt16()
+	case "uint16":
+		value = buf.ReadUint16()
+	case "int32":
+		value = buf.ReadInt32()
+	case "uint32":
+		value = buf.ReadUint32()
+	case "int":
+		value = buf.ReadVarInt()
+	case "uint":
+		value = buf.ReadVarUint()
+	case "float":
+		value = buf.ReadVarFloat()
+	case "float32":
+		value = buf.ReadFloat32()
+	case "lowp":
+		value = buf.ReadLowpFloat()
+	case "int64":
+		value = buf.ReadInt64()
+	case "uint64":
+		value = buf.ReadUint64()
+	case "float64":
+		value = buf.ReadFloat64()
+	case "varint64":
+		value = buf.ReadVarInt64()
+	case "varuint64":
+		value = buf.ReadVarUint64()
+	case "string":
+		value = buf.ReadString()
+	case "alphanumeric":
+		value = buf.ReadAlphanumeric()
+	default:
+		return c.decodeDefinition(buf, c.definitions[fieldType])
+	}
+	if err := buf.Err(); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (c *Codec) decodeDefinition(buf buffer.Reader, definition *schema.Definition) (interface{}, error) {
+	switch definition.Kind {
+	case schema.Enum, schema.Smol:
+		var value uint
+		if definition.Kind == schema.Smol {
+			value = uint(buf.ReadUint8())
+		} else {
+			value = buf.ReadVarUint()
+		}
+		if err := buf.Err(); err != nil {
+			return nil, err
+		}
+		for _, field := range definition.Fields {
+			if uint(field.Value) == value {
+				return field.Name, nil
+			}
+		}
+		return value, nil
+
+	case schema.Struct:
+		result := make(map[string]interface{}, len(definition.Fields))
+		for i := range definition.Fields {
+			field := &definition.Fields[i]
+
+			// Only messages can have deprecated fields, but a pick of one can
+			// copy them. They are never written, so there's nothing to skip.
+			if field.IsDeprecated {
+				continue
+			}
+			value, err := c.decodeField(buf, field)
+			if err != nil {
+				return nil, err
+			}
+			result[field.Name] = value
+		}
+		return result, nil
+
+	case schema.Message:
+		result := map[string]interface{}{}
+		for {
+			fieldType := buf.ReadUint8()
+			if err := buf.Err(); err != nil {
+				return nil, err
+			}
+			if fieldType == 0 {
+				break
+			}
+
+			field := messageField(definition, fieldType)
+			if field == nil {
+				// The field is from a newer schema, which only Types from
+				// SetTypes can say how to skip.
+				if _, err := buf.SkipField(definition.Name, fieldType); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			value, err := c.decodeField(buf, field)
+			if err != nil {
+				return nil, err
+			}
+			if !field.IsDeprecated {
+				result[field.Name] = value
+			}
+		}
+
+		for _, field := range definition.Fields {
+			if _, ok := result[field.Name]; field.IsRequired && !field.IsDeprecated && !ok {
+				return nil, &buffer.MissingFieldError{Message: definition.Name, Field: field.Name}
+			}
+		}
+		return result, nil
+
+	case schema.Union:
+		number := buf.ReadUint8()
+		if err := buf.Err(); err != nil {
+			return nil, err
+		}
+
+		member := unionMember(definition, number)
+		if member == nil {
+			return nil, &buffer.UnknownFieldError{Message: definition.Name, Field: number}
+		}
+		memberType := c.resolve(member.Type)
+		value, err := c.decodeValue(buf, memberType)
+		if err != nil {
+			return nil, err
+		}
+
+		key := discriminator(definition)
+		if key == "" || c.kind(memberType) == schema.Union {
+			value = map[string]interface{}{member.Name: value}
+		}
+		result := value.(map[string]interface{})
+		if key != "" {
+			result[key] = member.Name
+		}
+		return result, nil
+	}
+
+	return nil, &buffer.UnknownTypeError{Name: definition.Name}
+}
+
+func messageField(definition *schema.Definition, number uint8) *schema.Field {
+	for i := range definition.Fields {
+		if definition.Fields[i].Value == int(number) {
+			return &definition.Fields[i]
+		}
+	}
+	return nil
+}
+
+func unionMember(definition *schema.Definition, number uint8) *schema.Field {
+	for i := range definition.Fields {
+		field := &definition.Fields[i]
+		if field.Type != "discriminator" && field.Value == int(number) {
+			return field
+		}
+	}
+	return nil
+}
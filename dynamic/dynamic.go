@@ -0,0 +1,169 @@
+// Package dynamic decodes and encodes peechy data with a schema read at
+// runtime instead of generated code, for tools that handle many schemas.
+//
+// Values are the Go types encoding/json uses for unknown data:
+//
+//   - Built-in types are the Go types generated code uses, like int32 for
+//     "int32", uint for "uint" and float32 for "float" and "lowp".
+//   - Byte arrays are []byte, and other arrays are []interface{}.
+//   - Enums are the name of the value, or the value as a uint if it isn't in
+//     the schema.
+//   - Structs and messages are a map[string]interface{} of their fields.
+//     Messages only have the fields that were set, and deprecated fields are
+//     read and dropped.
+//   - A union is the map of its member with one more key saying which member
+//     it is. For a union with a discriminator that is the discriminator, set
+//     to the name of the member. Other unions are a map with the name of the
+//     member as the only key. A member that is a union itself is always kept
+//     under its name, so that its own key doesn't collide.
+//
+// Encode takes the same values, and is lenient about their types: numbers can
+// be any Go number, a json.Number or a string holding one, as long as they
+// fit, enums can be names or numbers, byte arrays can also be base64 strings,
+// and arrays can be any slice. That way values decoded from JSON with
+// UseNumber can be encoded as they are.
+package dynamic
+
+import (
+	"fmt"
+
+	"github.com/jarred-sumner/peechy/schema"
+)
+
+// Codec decodes and encodes the definitions of one schema.
+type Codec struct {
+	definitions map[string]*schema.Definition
+	aliases     map[string]string
+}
+
+// builtins are the types that aren't definitions.
+var builtins = map[string]bool{
+	"bool":         true,
+	"byte":         true,
+	"float":        true,
+	"int":          true,
+	"uint8":        true,
+	"uint16":       true,
+	"uint32":       true,
+	"int8":         true,
+	"int16":        true,
+	"float32":      true,
+	"int32":        true,
+	"lowp":         true,
+	"string":       true,
+	"uint":         true,
+	"alphanumeric": true,
+	"int64":        true,
+	"uint64":       true,
+	"float64":      true,
+	"varint64":     true,
+	"varuint64":    true,
+}
+
+// New returns a Codec for s, which can come from schema.Parse or
+// schema.DecodeBinary. It fails if a field has a type that isn't defined.
+func New(s *schema.Schema) (*Codec, error) {
+	c := &Codec{
+		definitions: map[string]*schema.Definition{},
+		aliases:     map[string]string{},
+	}
+	for _, definition := range s.Definitions {
+		c.definitions[definition.Name] = definition
+		if definition.Kind == schema.Alias {
+			if len(definition.Fields) == 0 {
+				return nil, fmt.Errorf("peechy: alias %q has no type", definition.Name)
+			}
+			c.aliases[definition.Name] = definition.Fields[0].Name
+		}
+	}
+
+	// Aliases of aliases are resolved down to the type they end up at.
+	for _, definition := range s.Definitions {
+		if definition.Kind != schema.Alias {
+			continue
+		}
+		name := definition.Name
+		seen := map[string]bool{name: true}
+		for c.aliases[c.aliases[name]] != "" {
+			if seen[c.aliases[name]] {
+				return nil, fmt.Errorf("peechy: alias %q refers to itself", name)
+			}
+			seen[c.aliases[name]] = true
+			c.aliases[name] = c.aliases[c.aliases[name]]
+		}
+	}
+
+	for _, definition := range s.Definitions {
+		switch definition.Kind {
+		case schema.Struct, schema.Message, schema.Union:
+		default:
+			continue
+		}
+		for i, field := range definition.Fields {
+			fieldType := c.resolve(field.Type)
+			kind := c.kind(fieldType)
+			switch {
+			case definition.Kind == schema.Union && fieldType == "discriminator" && i == 0:
+			case definition.Kind == schema.Union:
+				if kind != schema.Struct && kind != schema.Message && kind != schema.Union {
+					return nil, fmt.Errorf("peechy: invalid member %q of union %q", field.Name, definition.Name)
+				}
+			case !builtins[fieldType] && kind != schema.Enum && kind != schema.Smol &&
+				kind != schema.Struct && kind != schema.Message && kind != schema.Union:
+				return nil, fmt.Errorf("peechy: invalid type %q for field %q of %q", field.Type, field.Name, definition.Name)
+			}
+		}
+	}
+
+	return c, nil
+}
+
+// root returns the definition called name, which Decode and Encode start at.
+func (c *Codec) root(name string) (*schema.Definition, error) {
+	definition := c.definitions[c.resolve(name)]
+	if definition == nil || (definition.Kind != schema.Struct &&
+		definition.Kind != schema.Message && definition.Kind != schema.Union) {
+		return nil, fmt.Errorf("peechy: invalid root type %q", name)
+	}
+	return definition, nil
+}
+
+func (c *Codec) resolve(fieldType string) string {
+	if alias := c.aliases[fieldType]; alias != "" {
+		return alias
+	}
+	return fieldType
+}
+
+// kind returns the kind of the definition called name, or "" for built-in
+// types.
+func (c *Codec) kind(name string) schema.Kind {
+	if definition := c.definitions[name]; definition != nil {
+		return definition.Kind
+	}
+	return ""
+}
+
+// A struct whose fields are all empty structs encodes to zero bytes, so an
+// array of them can be longer than the bytes left in the buffer.
+func (c *Codec) canBeEmpty(name string) bool {
+	definition := c.definitions[name]
+	if definition == nil || definition.Kind != schema.Struct {
+		return false
+	}
+	for _, field := range definition.Fields {
+		if field.IsArray || !c.canBeEmpty(c.resolve(field.Type)) {
+			return false
+		}
+	}
+	return true
+}
+
+// discriminator returns the name of the discriminator of a union, or "" if it
+// doesn't have one.
+func discriminator(definition *schema.Definition) string {
+	if len(definition.Fields) > 0 && definition.Fields[0].Type == "discriminator" {
+		return definition.Fields[0].Name
+	}
+	return ""
+}
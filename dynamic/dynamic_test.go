@@ -0,0 +1,383 @@
+package dynamic_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jarred-sumner/peechy/buffer"
+	"github.com/jarred-sumner/peechy/dynamic"
+	"github.com/jarred-sumner/peechy/schema"
+	"github.com/jarred-sumner/peechy/test/gotest"
+	"github.com/valyala/bytebufferpool"
+)
+
+func parseCodec(t *testing.T, path string) (*schema.Schema, *dynamic.Codec) {
+	text, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := schema.Parse(string(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	codec, err := dynamic.New(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed, codec
+}
+
+func encode(t *testing.T, codec *dynamic.Codec, root string, value interface{}) []byte {
+	bb := bytebufferpool.Get()
+	defer bytebufferpool.Put(bb)
+
+	if err := codec.Encode(root, buffer.NewBuffer(bb), value); err != nil {
+		t.Fatal(err)
+	}
+	return append([]byte(nil), bb.B...)
+}
+
+// fromJSON returns the value in text the way encoding/json decodes it, to
+// write the expected values more compactly.
+func fromJSON(t *testing.T, text string) interface{} {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+// Every value test/go-fixtures.js has JavaScript encode decodes and encodes
+// back to the same bytes, with a schema that is parsed or read from a binary
+// schema.
+func TestRoundTripJS(t *testing.T) {
+	contents, err := ioutil.ReadFile("../test/go-fixtures.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixtures []struct {
+		Type  string `json:"type"`
+		Bytes []int  `json:"bytes"`
+	}
+	if err := json.Unmarshal(contents, &fixtures); err != nil {
+		t.Fatal(err)
+	}
+
+	var codecs []*dynamic.Codec
+	var definitions []map[string]bool
+	for _, path := range []string{"../test/test-schema.kiwi", "../test/test-go.kiwi"} {
+		parsed, codec := parseCodec(t, path)
+		binary, err := schema.DecodeBinary(schema.EncodeBinary(parsed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		fromBinary, err := dynamic.New(binary)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		names := map[string]bool{}
+		for _, definition := range parsed.Definitions {
+			names[definition.Name] = true
+		}
+		codecs = append(codecs, codec, fromBinary)
+		definitions = append(definitions, names, names)
+	}
+
+	for _, fixture := range fixtures {
+		data := make([]byte, len(fixture.Bytes))
+		for i, b := range fixture.Bytes {
+			data[i] = byte(b)
+		}
+
+		tested := false
+		for i, codec := range codecs {
+			if !definitions[i][fixture.Type] {
+				continue
+			}
+			tested = true
+
+			value, err := codec.Decode(fixture.Type, buffer.FromBytes(data))
+			if err != nil {
+				t.Fatalf("%s %v: %v", fixture.Type, data, err)
+			}
+			if out := encode(t, codec, fixture.Type, value); !bytes.Equal(out, data) {
+				t.Fatalf("%s: expected %v to equal %v", fixture.Type, out, data)
+			}
+		}
+		if !tested {
+			t.Fatalf("%s isn't in test-schema.kiwi or test-go.kiwi", fixture.Type)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	_, codec := parseCodec(t, "../test/test-go.kiwi")
+	text, age, suit, rank := "hi", uint(3), gotest.Suit(9), gotest.RankKing
+
+	for _, test := range []struct {
+		root  string
+		value interface{ Encode(buffer.Writer) error }
+		want  string
+	}{
+		{
+			"ShapeStruct",
+			&gotest.ShapeStruct{Shape: &gotest.Label{Text: &text}, Event: &gotest.Point{X: 3, Y: -4}},
+			`{"shape": {"Label": {"text": "hi"}}, "event": {"kind": "Point", "x": 3, "y": -4}}`,
+		},
+		{
+			"AnyStruct",
+			&gotest.AnyStruct{Value: &gotest.AnyEvent{Value: &gotest.Point{X: 1, Y: 2}}},
+			`{"value": {"Event": {"kind": "Point", "x": 1, "y": 2}}}`,
+		},
+		{
+			// Deprecated fields are read and dropped.
+			"Account",
+			&gotest.AccountV1{Name: &text, Email: &text, Labels: &[]gotest.Label{{}}, Avatar: &gotest.Point{}},
+			`{"name": "hi", "avatar": {"Point": {"x": 0, "y": 0}}}`,
+		},
+		{
+			// Enum values that aren't in the schema are kept as numbers.
+			"Hand",
+			&gotest.Hand{Trump: &suit, Suits: &[]gotest.Suit{gotest.SuitHearts}, High: &rank},
+			`{"trump": 9, "suits": ["Hearts"], "high": "King"}`,
+		},
+		{
+			"Entity",
+			&gotest.Entity{ID: "u1", Tags: []gotest.ID{"a"}, Height: 1.5, Path: []gotest.Meters{0.5}},
+			`{"id": "u1", "tags": ["a"], "height": 1.5, "path": [0.5]}`,
+		},
+		{
+			"Profile",
+			&gotest.Profile{Age: &age, Friends: &[]uint{1, 2}},
+			`{"age": 3, "friends": [1, 2]}`,
+		},
+	} {
+		bb := bytebufferpool.Get()
+		if err := test.value.Encode(buffer.NewBuffer(bb)); err != nil {
+			t.Fatal(err)
+		}
+		got, err := codec.Decode(test.root, buffer.FromBytes(bb.B))
+		if err != nil {
+			t.Fatalf("%s: %v", test.root, err)
+		}
+		bytebufferpool.Put(bb)
+
+		// Compare the JSON, since the numbers have the types of the schema.
+		gotJSON, err := json.Marshal(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := fromJSON(t, test.want); !reflect.DeepEqual(fromJSON(t, string(gotJSON)), want) {
+			t.Errorf("%s: expected %s, got %s", test.root, test.want, gotJSON)
+		}
+	}
+}
+
+func TestDecodeTypes(t *testing.T) {
+	_, codec := parseCodec(t, "../test/test-go.kiwi")
+	point := gotest.Point{X: 1, Y: 2}
+	bb := bytebufferpool.Get()
+	defer bytebufferpool.Put(bb)
+	if err := point.Encode(buffer.NewBuffer(bb)); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := codec.Decode("Point", buffer.FromBytes(bb.B))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]interface{}{"x": 1, "y": 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %#v, got %#v", want, got)
+	}
+}
+
+// Enums in generated code marshal to JSON with the same names, so JSON from
+// either one can be read by the other.
+func TestEnumJSONMatchesGo(t *testing.T) {
+	_, codec := parseCodec(t, "../test/test-go.kiwi")
+	trump := gotest.SuitHearts
+	hand := gotest.Hand{Trump: &trump, Suits: &[]gotest.Suit{gotest.SuitClubs, gotest.SuitSpades}}
+	bb := bytebufferpool.Get()
+	defer bytebufferpool.Put(bb)
+	if err := hand.Encode(buffer.NewBuffer(bb)); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := codec.Decode("Hand", buffer.FromBytes(bb.B))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := got.(map[string]interface{})
+	gotJSON, err := json.Marshal([]interface{}{fields["trump"], fields["suits"]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantJSON, err := json.Marshal([]interface{}{hand.Trump, hand.Suits})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("expected %s, got %s", wantJSON, gotJSON)
+	}
+}
+
+// What Encode writes decodes with generated code, including values with the
+// types encoding/json gives them.
+func TestEncode(t *testing.T) {
+	_, codec := parseCodec(t, "../test/test-go.kiwi")
+
+	data := encode(t, codec, "ShapeStruct", fromJSON(t, `{
+		"shape": {"Point": {"x": -1, "y": 2147483647}},
+		"event": {"kind": 2, "text": "hi", "color": "7"}
+	}`))
+	shapes, err := gotest.DecodeShapeStruct(buffer.FromBytes(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, color := "hi", uint(7)
+	want := gotest.ShapeStruct{Shape: &gotest.Point{X: -1, Y: 2147483647}, Event: &gotest.Label{Text: &text, Color: &color}}
+	if !shapes.Equal(&want) {
+		t.Errorf("expected %+v, got %+v", want, shapes)
+	}
+
+	data = encode(t, codec, "Hand", map[string]interface{}{
+		"trump": "Spades",
+		"suits": []string{"Clubs", "2"},
+		"ranks": []interface{}{1, gotest.RankKing},
+	})
+	hand, err := gotest.DecodeHand(buffer.FromBytes(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *hand.Trump != gotest.SuitSpades || !reflect.DeepEqual(*hand.Suits, []gotest.Suit{gotest.SuitClubs, gotest.SuitDiamonds}) ||
+		hand.High != nil || !reflect.DeepEqual(*hand.Ranks, []gotest.Rank{gotest.RankAce, gotest.RankKing}) {
+		t.Errorf("unexpected %+v", hand)
+	}
+
+	data = encode(t, codec, "Entity", fromJSON(t, `{"id": "u1", "tags": [], "height": 1.5, "path": [0.5, 2]}`))
+	entity, err := gotest.DecodeEntity(buffer.FromBytes(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wantEntity := (gotest.Entity{ID: "u1", Height: 1.5, Path: []gotest.Meters{0.5, 2}}); !entity.Equal(&wantEntity) {
+		t.Errorf("expected %+v, got %+v", wantEntity, entity)
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	_, codec := parseCodec(t, "../test/test-go.kiwi")
+
+	for _, test := range []struct {
+		root  string
+		value string
+		err   string
+	}{
+		{"Suit", `{}`, `peechy: invalid root type "Suit"`},
+		{"Point", `[]`, `peechy: Point: expected a map of the fields of Point, got []interface {}`},
+		{"Point", `{"x": 1}`, `peechy: Point is missing required field "y"`},
+		{"Point", `{"x": 1.5, "y": 0}`, `peechy: Point.x: 1.5 is out of range for a 32-bit integer`},
+		{"Point", `{"x": 2147483648, "y": 0}`, `peechy: Point.x: 2147483648 is out of range for a 32-bit integer`},
+		{"Point", `{"x": "one", "y": 0}`, `peechy: Point.x: expected a number, got "one"`},
+		{"Profile", `{"friends": [1, -1]}`, `peechy: Profile.friends[1]: -1 is out of range for an unsigned 32-bit integer`},
+		{"Profile", `{"username": 1}`, `peechy: Profile.username: expected a string, got json.Number`},
+		{"Account", `{"name": "a"}`, `peechy: Account is missing required field "avatar"`},
+		{"Account", `{"name": "a", "avatar": {"Point": {}, "Label": {}}}`, `peechy: Account.avatar: expected one member of Shape, got 2 keys`},
+		{"Account", `{"name": "a", "avatar": {"Circle": {}}}`, `peechy: Account.avatar: "Circle" isn't a member of Shape`},
+		{"EventArrayStruct", `{"events": [{"x": 1}]}`, `peechy: EventArrayStruct.events[0]: missing the discriminator "kind" of Event`},
+		{"EventArrayStruct", `{"events": [{"kind": 3}]}`, `peechy: EventArrayStruct.events[0].kind: 3 isn't a member of Event`},
+		{"Hand", `{"trump": "Jokers"}`, `peechy: "Jokers" is not a Suit`},
+		{"Hand", `{"high": 256}`, `peechy: Hand.high: 256 is out of range for an unsigned 8-bit integer`},
+		{"CardV2", `{"weights": [true]}`, `peechy: CardV2.weights[0]: expected a number, got bool`},
+	} {
+		err := codec.Encode(test.root, buffer.FromBytes(nil), fromJSON(t, test.value))
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%s %s: expected %q, got %v", test.root, test.value, test.err, err)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	_, codec := parseCodec(t, "../test/test-go.kiwi")
+
+	for _, test := range []struct {
+		root string
+		data []byte
+		err  string
+	}{
+		{"Shape", []byte{3}, `peechy: unknown field 3 in Shape`},
+		{"Card", []byte{2, 0}, `peechy: unknown field 2 in Card`},
+		{"Account", []byte{0}, `peechy: Account is missing required field "name"`},
+		{"Point", []byte{1, 0, 0}, `peechy: unexpected EOF reading 4 bytes at offset 0`},
+	} {
+		_, err := codec.Decode(test.root, buffer.FromBytes(test.data))
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%s %v: expected %q, got %v", test.root, test.data, test.err, err)
+		}
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	for _, test := range []struct {
+		schema *schema.Schema
+		err    string
+	}{
+		{
+			&schema.Schema{Definitions: []*schema.Definition{
+				{Name: "A", Kind: schema.Alias, Fields: []schema.Field{{Name: "B"}}},
+				{Name: "B", Kind: schema.Alias, Fields: []schema.Field{{Name: "A"}}},
+			}},
+			`peechy: alias "A" refers to itself`,
+		},
+		{
+			&schema.Schema{Definitions: []*schema.Definition{
+				{Name: "Point", Kind: schema.Struct, Fields: []schema.Field{{Name: "x", Type: "flaot"}}},
+			}},
+			`peechy: invalid type "flaot" for field "x" of "Point"`,
+		},
+		{
+			&schema.Schema{Definitions: []*schema.Definition{
+				{Name: "Shape", Kind: schema.Union, Fields: []schema.Field{{Name: "x", Type: "float", Value: 1}}},
+			}},
+			`peechy: invalid member "x" of union "Shape"`,
+		},
+	} {
+		if _, err := dynamic.New(test.schema); err == nil || err.Error() != test.err {
+			t.Errorf("expected %q, got %v", test.err, err)
+		}
+	}
+}
+
+// Fields from a newer schema are skipped with the Types of that schema.
+func TestDecodeUnknownFields(t *testing.T) {
+	_, codec := parseCodec(t, "../test/test-go.kiwi")
+	title := "a"
+	card := gotest.CardV2{Title: &title, Corner: &gotest.Point{X: 1, Y: 2}}
+
+	bb := bytebufferpool.Get()
+	defer bytebufferpool.Put(bb)
+	if err := card.Encode(buffer.NewBuffer(bb)); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := buffer.FromBytes(bb.B)
+	types := buffer.Types{}
+	for name, typ := range gotest.SchemaTypes {
+		types[name] = typ
+	}
+	types["Card"] = types["CardV2"]
+	buf.SetTypes(types)
+	got, err := codec.Decode("Card", buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]interface{}{"title": "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,522 @@
+package dynamic
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/jarred-sumner/peechy/buffer"
+	"github.com/jarred-sumner/peechy/schema"
+)
+
+// Encode writes value as the struct, message or union called root to buf.
+// Errors about value say where in it the problem is, like
+// "Player.items[2].count: 300 is out of range for an unsigned 8-bit integer".
+func (c *Codec) Encode(root string, buf buffer.Writer, value interface{}) error {
+	definition, err := c.root(root)
+	if err != nil {
+		return err
+	}
+	return c.encodeDefinition(buf, definition, value, definition.Name)
+}
+
+// valueError is an error about the part of a value at path.
+func valueError(path string, format string, args ...interface{}) error {
+	return fmt.Errorf("peechy: %s: %s", path, fmt.Sprintf(format, args...))
+}
+
+func (c *Codec) encodeField(buf buffer.Writer, field *schema.Field, value interface{}, path string) error {
+	fieldType := c.resolve(field.Type)
+	if !field.IsArray {
+		return c.encodeValue(buf, fieldType, value, path)
+	}
+
+	if fieldType == "byte" {
+		data, err := toBytes(value, path)
+		if err != nil {
+			return err
+		}
+		buf.WriteByteArray(data)
+		return nil
+	}
+
+	items := reflect.ValueOf(value)
+	if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+		return valueError(path, "expected an array, got %T", value)
+	}
+	length := items.Len()
+	item := func(i int) (interface{}, string) {
+		return items.Index(i).Interface(), path + "[" + strconv.Itoa(i) + "]"
+	}
+
+	// Typed arrays are written in one go.
+	switch fieldType {
+	case "int8", "int16", "int32", "int64":
+		bits := map[string]int{"int8": 8, "int16": 16, "int32": 32, "int64": 64}[fieldType]
+		values := make([]int64, length)
+		for i := range values {
+			value, path := item(i)
+			n, err := toInt(value, bits, path)
+			if err != nil {
+				return err
+			}
+			values[i] = n
+		}
+		switch fieldType {
+		case "int8":
+			typed := make([]int8, length)
+			for i, n := range values {
+				typed[i] = int8(n)
+			}
+			buf.WriteInt8Array(typed)
+		case "int16":
+			typed := make([]int16, length)
+			for i, n := range values {
+				typed[i] = int16(n)
+			}
+			buf.WriteInt16Array(typed)
+		case "int32":
+			typed := make([]int32, length)
+			for i, n := range values {
+				typed[i] = int32(n)
+			}
+			buf.WriteInt32Array(typed)
+		default:
+			buf.WriteInt64Array(values)
+		}
+		return nil
+
+	case "uint16", "uint32", "uint64":
+		bits := map[string]int{"uint16": 16, "uint32": 32, "uint64": 64}[fieldType]
+		values := make([]uint64, length)
+		for i := range values {
+			value, path := item(i)
+			n, err := toUint(value, bits, path)
+			if err != nil {
+				return err
+			}
+			values[i] = n
+		}
+		switch fieldType {
+		case "uint16":
+			typed := make([]uint16, length)
+			for i, n := range values {
+				typed[i] = uint16(n)
+			}
+			buf.WriteUInt16Array(typed)
+		case "uint32":
+			typed := make([]uint32, length)
+			for i, n := range values {
+				typed[i] = uint32(n)
+			}
+			buf.WriteUInt32Array(typed)
+		default:
+			buf.WriteUInt64Array(values)
+		}
+		return nil
+
+	case "float32", "float64":
+		values := make([]float64, length)
+		for i := range values {
+			value, path := item(i)
+			f, err := toFloat(value, path)
+			if err != nil {
+				return err
+			}
+			values[i] = f
+		}
+		if fieldType == "float64" {
+			buf.WriteFloat64Array(values)
+			return nil
+		}
+		typed := make([]float32, length)
+		for i, f := range values {
+			typed[i] = float32(f)
+		}
+		buf.WriteFloat32Array(typed)
+		return nil
+	}
+
+	buf.WriteVarUint(uint(length))
+	for i := 0; i < length; i++ {
+		value, path := item(i)
+		if err := c.encodeValue(buf, fieldType, value, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Codec) encodeValue(buf buffer.Writer, fieldType string, value interface{}, path string) error {
+	intBits := varBits(buf)
+	switch fieldType {
+	case "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return valueError(path, "expected a bool, got %T", value)
+		}
+		buf.WriteBool(b)
+
+	case "string", "alphanumeric":
+		s, ok := value.(string)
+		if !ok {
+			return valueError(path, "expected a string, got %T", value)
+		}
+		if fieldType == "string" {
+			buf.WriteString(s)
+		} else {
+			buf.WriteAlphanumeric(s)
+		}
+
+	case "int8", "int16", "int32", "int", "int64", "varint64":
+		bits := map[string]int{"int8": 8, "int16": 16, "int32": 32, "int": intBits, "int64": 64, "varint64": 64}[fieldType]
+		n, err := toInt(value, bits, path)
+		if err != nil {
+			return err
+		}
+		switch fieldType {
+		case "int8":
+			buf.WriteInt8(int8(n))
+		case "int16":
+			buf.WriteInt16(int16(n))
+		case "int32":
+			buf.WriteInt32(int32(n))
+		case "int":
+			buf.WriteVarInt(int(n))
+		case "int64":
+			buf.WriteInt64(n)
+		default:
+			buf.WriteVarInt64(n)
+		}
+
+	case "byte", "uint8", "uint16", "uint32", "uint", "uint64", "varuint64":
+		bits := map[string]int{"byte": 8, "uint8": 8, "uint16": 16, "uint32": 32, "uint": intBits, "uint64": 64, "varuint64": 64}[fieldType]
+		n, err := toUint(value, bits, path)
+		if err != nil {
+			return err
+		}
+		switch fieldType {
+		case "byte", "uint8":
+			buf.WriteUint8(uint8(n))
+		case "uint16":
+			buf.WriteUint16(uint16(n))
+		case "uint32":
+			buf.WriteUint32(uint32(n))
+		case "uint":
+			buf.WriteVarUint(uint(n))
+		case "uint64":
+			buf.WriteUint64(n)
+		default:
+			buf.WriteVarUint64(n)
+		}
+
+	case "float", "float32", "float64", "lowp":
+		f, err := toFloat(value, path)
+		if err != nil {
+			return err
+		}
+		switch fieldType {
+		case "float":
+			buf.WriteVarFloat(float32(f))
+		case "float32":
+			buf.WriteFloat32(float32(f))
+		case "float64":
+			buf.WriteFloat64(f)
+		default:
+			// lowp is written as an int of a thousandth of the value.
+			if _, err := toInt(math.Round(f*1000), intBits, path); err != nil {
+				return valueError(path, "%v is out of range for a lowp", value)
+			}
+			buf.WriteLowpFloat(f)
+		}
+
+	default:
+		return c.encodeDefinition(buf, c.definitions[fieldType], value, path)
+	}
+
+	return nil
+}
+
+func (c *Codec) encodeDefinition(buf buffer.Writer, definition *schema.Definition, value interface{}, path string) error {
+	switch definition.Kind {
+	case schema.Enum, schema.Smol:
+		var number uint64
+		if name, ok := value.(string); ok && !isNumber(name) {
+			field := fieldNamed(definition, name)
+			if field == nil {
+				return &buffer.InvalidEnumError{Enum: definition.Name, Name: name}
+			}
+			number = uint64(field.Value)
+		} else {
+			bits := varBits(buf)
+			if definition.Kind == schema.Smol {
+				bits = 8
+			}
+			n, err := toUint(value, bits, path)
+			if err != nil {
+				return err
+			}
+			number = n
+		}
+		if definition.Kind == schema.Smol {
+			buf.WriteUint8(uint8(number))
+		} else {
+			buf.WriteVarUint(uint(number))
+		}
+		return nil
+
+	case schema.Struct:
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return valueError(path, "expected a map of the fields of %s, got %T", definition.Name, value)
+		}
+		for i := range definition.Fields {
+			field := &definition.Fields[i]
+			if field.IsDeprecated {
+				continue
+			}
+			fieldValue, ok := fields[field.Name]
+			if !ok || fieldValue == nil {
+				return &buffer.MissingFieldError{Message: definition.Name, Field: field.Name}
+			}
+			if err := c.encodeField(buf, field, fieldValue, path+"."+field.Name); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case schema.Message:
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return valueError(path, "expected a map of the fields of %s, got %T", definition.Name, value)
+		}
+		for i := range definition.Fields {
+			field := &definition.Fields[i]
+			if field.IsDeprecated {
+				continue
+			}
+			fieldValue, ok := fields[field.Name]
+			if !ok || fieldValue == nil {
+				if field.IsRequired {
+					return &buffer.MissingFieldError{Message: definition.Name, Field: field.Name}
+				}
+				continue
+			}
+			buf.WriteByte(byte(field.Value))
+			if err := c.encodeField(buf, field, fieldValue, path+"."+field.Name); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(0)
+		return nil
+
+	case schema.Union:
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return valueError(path, "expected a map with a member of %s, got %T", definition.Name, value)
+		}
+
+		var member *schema.Field
+		if key := discriminator(definition); key != "" {
+			switch name := fields[key].(type) {
+			case nil:
+				return valueError(path, "missing the discriminator %q of %s", key, definition.Name)
+			case string:
+				if !isNumber(name) {
+					member = fieldNamed(definition, name)
+					break
+				}
+				number, err := toUint(name, 8, path+"."+key)
+				if err != nil {
+					return err
+				}
+				member = unionMember(definition, uint8(number))
+			default:
+				number, err := toUint(name, 8, path+"."+key)
+				if err != nil {
+					return err
+				}
+				member = unionMember(definition, uint8(number))
+			}
+			if member == nil || member.Type == "discriminator" {
+				return valueError(path+"."+key, "%v isn't a member of %s", fields[key], definition.Name)
+			}
+			if c.kind(c.resolve(member.Type)) == schema.Union {
+				value = fields[member.Name]
+			}
+		} else {
+			if len(fields) != 1 {
+				return valueError(path, "expected one member of %s, got %d keys", definition.Name, len(fields))
+			}
+			for name, memberValue := range fields {
+				member = fieldNamed(definition, name)
+				if member == nil {
+					return valueError(path, "%q isn't a member of %s", name, definition.Name)
+				}
+				value = memberValue
+			}
+		}
+
+		buf.WriteByte(byte(member.Value))
+		return c.encodeValue(buf, c.resolve(member.Type), value, path+"."+member.Name)
+	}
+
+	return &buffer.UnknownTypeError{Name: definition.Name}
+}
+
+// varBits returns the size of int, uint and enums, which are 32 bits unless
+// they are written as varints.
+func varBits(buf buffer.Writer) int {
+	if buf.WireFormat() == buffer.VarintFormat {
+		return strconv.IntSize
+	}
+	return 32
+}
+
+func fieldNamed(definition *schema.Definition, name string) *schema.Field {
+	for i := range definition.Fields {
+		if definition.Fields[i].Name == name {
+			return &definition.Fields[i]
+		}
+	}
+	return nil
+}
+
+// isNumber reports whether s is a number, which strings standing in for enums
+// and union members can be. Only digits count, so that names like "NaN" and
+// "Infinity" still work.
+func isNumber(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
+}
+
+// toInt returns value as a signed integer of the given size.
+func toInt(value interface{}, bits int, path string) (int64, error) {
+	original := value
+	min, max := -math.Ldexp(1, bits-1), math.Ldexp(1, bits-1)
+	switch v := value.(type) {
+	case json.Number, string:
+		s := reflect.ValueOf(v).String()
+		if n, err := strconv.ParseInt(s, 10, bits); err == nil {
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, valueError(path, "expected a number, got %q", s)
+		}
+		value = f
+	}
+
+	n := reflect.ValueOf(value)
+	switch n.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if bits == 64 || (n.Int() >= int64(min) && n.Int() < int64(max)) {
+			return n.Int(), nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n.Uint() < uint64(max) {
+			return int64(n.Uint()), nil
+		}
+	case reflect.Float32, reflect.Float64:
+		f := n.Float()
+		if f == math.Trunc(f) && f >= min && f < max {
+			return int64(f), nil
+		}
+	default:
+		return 0, valueError(path, "expected a number, got %T", value)
+	}
+	return 0, valueError(path, "%v is out of range for a %d-bit integer", original, bits)
+}
+
+// toUint returns value as an unsigned integer of the given size.
+func toUint(value interface{}, bits int, path string) (uint64, error) {
+	original := value
+	max := math.Ldexp(1, bits)
+	switch v := value.(type) {
+	case json.Number, string:
+		s := reflect.ValueOf(v).String()
+		if n, err := strconv.ParseUint(s, 10, bits); err == nil {
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, valueError(path, "expected a number, got %q", s)
+		}
+		value = f
+	}
+
+	n := reflect.ValueOf(value)
+	switch n.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n.Int() >= 0 && (bits == 64 || n.Int() < int64(max)) {
+			return uint64(n.Int()), nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if bits == 64 || n.Uint() < uint64(max) {
+			return n.Uint(), nil
+		}
+	case reflect.Float32, reflect.Float64:
+		f := n.Float()
+		if f == math.Trunc(f) && f >= 0 && f < max {
+			return uint64(f), nil
+		}
+	default:
+		return 0, valueError(path, "expected a number, got %T", value)
+	}
+	return 0, valueError(path, "%v is out of range for an unsigned %d-bit integer", original, bits)
+}
+
+func toFloat(value interface{}, path string) (float64, error) {
+	switch v := value.(type) {
+	case json.Number, string:
+		s := reflect.ValueOf(v).String()
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, valueError(path, "expected a number, got %q", s)
+		}
+		return f, nil
+	}
+
+	n := reflect.ValueOf(value)
+	switch n.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(n.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(n.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return n.Float(), nil
+	}
+	return 0, valueError(path, "expected a number, got %T", value)
+}
+
+// toBytes returns a byte array from a []byte, a base64 string like
+// encoding/json writes, or any slice of numbers.
+func toBytes(value interface{}, path string) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		data, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, valueError(path, "expected a base64 string: %v", err)
+		}
+		return data, nil
+	}
+
+	items := reflect.ValueOf(value)
+	if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+		return nil, valueError(path, "expected a byte array, got %T", value)
+	}
+	data := make([]byte, items.Len())
+	for i := range data {
+		n, err := toUint(items.Index(i).Interface(), 8, path+"["+strconv.Itoa(i)+"]")
+		if err != nil {
+			return nil, err
+		}
+		data[i] = byte(n)
+	}
+	return data, nil
+}
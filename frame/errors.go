@@ -0,0 +1,37 @@
+package frame
+
+import "fmt"
+
+// TooLargeError is returned for a message bigger than MaxSize.
+type TooLargeError struct {
+	Size    uint64
+	MaxSize uint32
+}
+
+func (e *TooLargeError) Error() string {
+	return fmt.Sprintf("peechy: frame of %d bytes is larger than the maximum of %d", e.Size, e.MaxSize)
+}
+
+// ChecksumError is returned by ReadFrame when a frame's CRC-32 doesn't match,
+// or when it has none and Reader.RequireChecksum is set.
+type ChecksumError struct {
+	Type    uint32
+	Missing bool
+}
+
+func (e *ChecksumError) Error() string {
+	if e.Missing {
+		return fmt.Sprintf("peechy: frame of type %d has no checksum", e.Type)
+	}
+	return fmt.Sprintf("peechy: checksum mismatch in frame of type %d", e.Type)
+}
+
+// FlagsError is returned by ReadFrame for a header with unknown flags, which
+// usually means the stream is out of sync.
+type FlagsError struct {
+	Flags byte
+}
+
+func (e *FlagsError) Error() string {
+	return fmt.Sprintf("peechy: unknown frame flags %#x", e.Flags)
+}
@@ -0,0 +1,209 @@
+// Package frame sends several peechy messages over one stream, like a TCP
+// connection or a pipe.
+//
+// Each frame is a 9 byte header followed by the encoded message:
+//
+//	byte   flags, 1 if a checksum follows the message
+//	uint32 type, for the receiver to pick the right DecodeX
+//	uint32 length of the message in bytes
+//	       the message
+//	uint32 CRC-32 (IEEE) of the header and the message, if flags is 1
+//
+// Integers are little-endian.
+package frame
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+
+	"github.com/jarred-sumner/peechy/buffer"
+	"github.com/jarred-sumner/peechy/message"
+	"github.com/valyala/bytebufferpool"
+)
+
+// DefaultMaxSize is the largest message a Reader or Writer accepts unless
+// MaxSize is set.
+const DefaultMaxSize = 4 << 20 // bytes
+
+const headerSize = 9 // bytes
+
+const flagChecksum = 1
+
+// Encoder is implemented by generated message types.
+type Encoder interface {
+	Encode(buf buffer.Writer) error
+}
+
+// Sizer is implemented by generated message types too. WriteFrame uses it to
+// turn down a message larger than MaxSize before encoding any of it.
+type Sizer interface {
+	EncodedSize() int
+}
+
+// Frame is a message read by ReadFrame.
+type Frame struct {
+	Type    uint32
+	Payload []byte
+}
+
+// Buffer returns a Buffer that reads the payload, for passing to DecodeX.
+func (f Frame) Buffer() *buffer.Buffer {
+	return buffer.FromBytes(f.Payload)
+}
+
+// Message decodes the payload as the registered type whose TypeID is the
+// frame's type, for frames written by WriteMessage.
+func (f Frame) Message() (message.Message, error) {
+	return message.Decode(f.Type, f.Buffer())
+}
+
+// Writer writes frames to an io.Writer. It isn't safe for concurrent use.
+type Writer struct {
+	// MaxSize is the largest message WriteFrame writes. Zero means
+	// DefaultMaxSize.
+	MaxSize uint32
+	// Checksum adds a CRC-32 to every frame.
+	Checksum bool
+
+	w io.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// WriteFrame encodes msg and writes it as a single frame with the given type.
+func (w *Writer) WriteFrame(typ uint32, msg Encoder) error {
+	if sizer, ok := msg.(Sizer); ok {
+		if size := sizer.EncodedSize(); uint64(size) > uint64(maxSize(w.MaxSize)) {
+			return &TooLargeError{Size: uint64(size), MaxSize: maxSize(w.MaxSize)}
+		}
+	}
+
+	bb := bytebufferpool.Get()
+	defer bytebufferpool.Put(bb)
+
+	// Leave room for the header, then encode the message right after it.
+	bb.B = append(bb.B[:0], make([]byte, headerSize)...)
+	if err := msg.Encode(buffer.NewBuffer(bb)); err != nil {
+		return err
+	}
+
+	return w.write(typ, bb)
+}
+
+// WriteMessage writes msg as a single frame, with its TypeID as the type.
+func (w *Writer) WriteMessage(msg message.Message) error {
+	return w.WriteFrame(msg.TypeID(), msg)
+}
+
+// WritePayload writes an already encoded message as a single frame.
+func (w *Writer) WritePayload(typ uint32, payload []byte) error {
+	bb := bytebufferpool.Get()
+	defer bytebufferpool.Put(bb)
+
+	bb.B = append(bb.B[:0], make([]byte, headerSize)...)
+	bb.B = append(bb.B, payload...)
+
+	return w.write(typ, bb)
+}
+
+// write fills in the header in front of the message in bb and writes it.
+func (w *Writer) write(typ uint32, bb *bytebufferpool.ByteBuffer) error {
+	size := len(bb.B) - headerSize
+	if uint64(size) > uint64(maxSize(w.MaxSize)) {
+		return &TooLargeError{Size: uint64(size), MaxSize: maxSize(w.MaxSize)}
+	}
+
+	header := bb.B[:headerSize]
+	header[0] = 0
+	binary.LittleEndian.PutUint32(header[1:], typ)
+	binary.LittleEndian.PutUint32(header[5:], uint32(size))
+
+	if w.Checksum {
+		header[0] = flagChecksum
+		var sum [4]byte
+		binary.LittleEndian.PutUint32(sum[:], crc32.ChecksumIEEE(bb.B))
+		bb.B = append(bb.B, sum[:]...)
+	}
+
+	_, err := w.w.Write(bb.B)
+	return err
+}
+
+// Reader reads frames from an io.Reader. It isn't safe for concurrent use.
+type Reader struct {
+	// MaxSize is the largest message ReadFrame accepts. Zero means
+	// DefaultMaxSize.
+	MaxSize uint32
+	// RequireChecksum makes ReadFrame reject frames without a CRC-32.
+	// Frames that have one are always checked.
+	RequireChecksum bool
+
+	r      io.Reader
+	header [headerSize]byte
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: r}
+}
+
+// ReadFrame reads the next frame. It returns io.EOF if the stream ends
+// between frames and io.ErrUnexpectedEOF if it ends inside one.
+// The payload is newly allocated for every frame. After any other error the
+// Reader is out of step with the stream and shouldn't be used again.
+func (r *Reader) ReadFrame() (Frame, error) {
+	if _, err := io.ReadFull(r.r, r.header[:]); err != nil {
+		return Frame{}, err
+	}
+
+	flags := r.header[0]
+	frame := Frame{Type: binary.LittleEndian.Uint32(r.header[1:])}
+	size := binary.LittleEndian.Uint32(r.header[5:])
+
+	if flags&^flagChecksum != 0 {
+		return Frame{}, &FlagsError{Flags: flags}
+	}
+	if size > maxSize(r.MaxSize) {
+		return Frame{}, &TooLargeError{Size: uint64(size), MaxSize: maxSize(r.MaxSize)}
+	}
+	if r.RequireChecksum && flags&flagChecksum == 0 {
+		return Frame{}, &ChecksumError{Type: frame.Type, Missing: true}
+	}
+
+	frame.Payload = make([]byte, size)
+	if _, err := io.ReadFull(r.r, frame.Payload); err != nil {
+		return Frame{}, unexpected(err)
+	}
+
+	if flags&flagChecksum != 0 {
+		var sum [4]byte
+		if _, err := io.ReadFull(r.r, sum[:]); err != nil {
+			return Frame{}, unexpected(err)
+		}
+
+		crc := crc32.Update(crc32.ChecksumIEEE(r.header[:]), crc32.IEEETable, frame.Payload)
+		if crc != binary.LittleEndian.Uint32(sum[:]) {
+			return Frame{}, &ChecksumError{Type: frame.Type}
+		}
+	}
+
+	return frame, nil
+}
+
+func maxSize(size uint32) uint32 {
+	if size == 0 {
+		return DefaultMaxSize
+	}
+	return size
+}
+
+// unexpected turns io.EOF into io.ErrUnexpectedEOF, for reads in the middle
+// of a frame.
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,223 @@
+package frame_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/jarred-sumner/peechy/buffer"
+	"github.com/jarred-sumner/peechy/frame"
+	"github.com/jarred-sumner/peechy/message"
+)
+
+type point struct {
+	X, Y int32
+}
+
+func (p *point) Encode(buf buffer.Writer) error {
+	buf.WriteInt32(p.X)
+	buf.WriteInt32(p.Y)
+	return nil
+}
+
+func decodePoint(buf buffer.Reader) (point, error) {
+	p := point{X: buf.ReadInt32(), Y: buf.ReadInt32()}
+	return p, buf.Err()
+}
+
+func (p *point) Decode(buf buffer.Reader) error {
+	var err error
+	*p, err = decodePoint(buf)
+	return err
+}
+
+func (*point) TypeName() string { return "frame_test.point" }
+func (*point) TypeID() uint32   { return message.ID("frame_test.point") }
+
+func init() {
+	if err := message.Register(func() message.Message { return new(point) }); err != nil {
+		panic(err)
+	}
+}
+
+// sized says how large it is without encoding, like generated types.
+type sized struct {
+	t    *testing.T
+	size int
+}
+
+func (s *sized) EncodedSize() int {
+	return s.size
+}
+
+func (s *sized) Encode(buf buffer.Writer) error {
+	s.t.Fatal("Expected WriteFrame to check the size before encoding")
+	return nil
+}
+
+type name string
+
+func (n name) Encode(buf buffer.Writer) error {
+	buf.WriteString(string(n))
+	return nil
+}
+
+const (
+	pointType uint32 = iota + 1
+	nameType
+)
+
+func TestFrameConn(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	go func() {
+		w := frame.NewWriter(client)
+		w.Checksum = true
+		w.WriteFrame(pointType, &point{X: 1, Y: -2})
+		w.WriteFrame(nameType, name("peechy"))
+		w.WriteFrame(pointType, &point{X: 3, Y: 4})
+		client.Close()
+	}()
+
+	r := frame.NewReader(server)
+	r.RequireChecksum = true
+
+	var points []point
+	var names []string
+	for {
+		f, err := r.ReadFrame()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		switch f.Type {
+		case pointType:
+			p, err := decodePoint(f.Buffer())
+			if err != nil {
+				t.Fatal(err)
+			}
+			points = append(points, p)
+		case nameType:
+			names = append(names, f.Buffer().ReadString())
+		default:
+			t.Fatalf("Unexpected frame type %d", f.Type)
+		}
+	}
+
+	if len(points) != 2 || points[0] != (point{1, -2}) || points[1] != (point{3, 4}) {
+		t.Fatalf("Expected two points, got %v", points)
+	}
+	if len(names) != 1 || names[0] != "peechy" {
+		t.Fatalf("Expected [peechy], got %v", names)
+	}
+}
+
+func TestFrameBytes(t *testing.T) {
+	var out bytes.Buffer
+	if err := frame.NewWriter(&out).WriteFrame(pointType, &point{X: 1, Y: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte{0, 1, 0, 0, 0, 8, 0, 0, 0, 1, 0, 0, 0, 2, 0, 0, 0}
+	if !bytes.Equal(out.Bytes(), expected) {
+		t.Fatalf("Expected %v to equal %v", out.Bytes(), expected)
+	}
+}
+
+func TestFrameChecksum(t *testing.T) {
+	var out bytes.Buffer
+	w := frame.NewWriter(&out)
+	w.Checksum = true
+	w.WritePayload(nameType, []byte("abc"))
+
+	corrupt := append([]byte(nil), out.Bytes()...)
+	corrupt[10] ^= 1
+
+	var checksum *frame.ChecksumError
+	if _, err := frame.NewReader(bytes.NewReader(corrupt)).ReadFrame(); !errors.As(err, &checksum) {
+		t.Fatalf("Expected a ChecksumError, got %v", err)
+	}
+
+	out.Reset()
+	frame.NewWriter(&out).WritePayload(nameType, []byte("abc"))
+	r := frame.NewReader(&out)
+	r.RequireChecksum = true
+	if _, err := r.ReadFrame(); !errors.As(err, &checksum) || !checksum.Missing {
+		t.Fatalf("Expected a missing ChecksumError, got %v", err)
+	}
+}
+
+func TestFrameMaxSize(t *testing.T) {
+	var out bytes.Buffer
+	w := frame.NewWriter(&out)
+	w.MaxSize = 4
+
+	var tooLarge *frame.TooLargeError
+	if err := w.WriteFrame(pointType, &point{}); !errors.As(err, &tooLarge) || tooLarge.Size != 8 {
+		t.Fatalf("Expected a TooLargeError for 8 bytes, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("Expected nothing to be written, got %v", out.Bytes())
+	}
+
+	frame.NewWriter(&out).WriteFrame(pointType, &point{})
+	r := frame.NewReader(&out)
+	r.MaxSize = 4
+	if _, err := r.ReadFrame(); !errors.As(err, &tooLarge) {
+		t.Fatalf("Expected a TooLargeError, got %v", err)
+	}
+}
+
+func TestFrameMaxSizeBeforeEncoding(t *testing.T) {
+	var out bytes.Buffer
+	w := frame.NewWriter(&out)
+
+	var tooLarge *frame.TooLargeError
+	err := w.WriteFrame(pointType, &sized{t: t, size: frame.DefaultMaxSize + 1})
+	if !errors.As(err, &tooLarge) || tooLarge.Size != frame.DefaultMaxSize+1 {
+		t.Fatalf("Expected a TooLargeError for %d bytes, got %v", frame.DefaultMaxSize+1, err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("Expected nothing to be written, got %v", out.Bytes())
+	}
+}
+
+func TestFrameMessage(t *testing.T) {
+	var out bytes.Buffer
+	if err := frame.NewWriter(&out).WriteMessage(&point{X: 5, Y: 6}); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := frame.NewReader(&out).ReadFrame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Type != message.ID("frame_test.point") {
+		t.Fatalf("Expected the TypeID as the frame type, got %d", f.Type)
+	}
+
+	m, err := f.Message()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := m.(*point); !ok || *p != (point{X: 5, Y: 6}) {
+		t.Fatalf("Expected point{5, 6}, got %#v", m)
+	}
+}
+
+func TestFrameTruncated(t *testing.T) {
+	var out bytes.Buffer
+	frame.NewWriter(&out).WritePayload(nameType, []byte("abc"))
+
+	for i := 1; i < out.Len(); i++ {
+		_, err := frame.NewReader(bytes.NewReader(out.Bytes()[:i])).ReadFrame()
+		if err != io.ErrUnexpectedEOF {
+			t.Fatalf("Expected io.ErrUnexpectedEOF after %d bytes, got %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package golang
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jarred-sumner/peechy/schema"
+)
+
+var readMethods = map[string]string{
+	"bool":      "buf.ReadBool()",
+	"uint8":     "buf.ReadUint8()",
+	"byte":      "buf.ReadUint8()", // only used if not array
+	"int16":     "buf.ReadInt16()",
+	"int8":      "buf.ReadInt8()",
+	"int32":     "buf.ReadInt32()",
+	"int":       "buf.ReadVarInt()",
+	"uint16":    "buf.ReadUint16()",
+	"uint32":    "buf.ReadUint32()",
+	"lowp":      "buf.ReadLowpFloat()",
+	"uint":      "buf.ReadVarUint()",
+	"float":     "buf.ReadVarFloat()",
+	"float32":   "buf.ReadFloat32()",
+	"int64":     "buf.ReadInt64()",
+	"uint64":    "buf.ReadUint64()",
+	"float64":   "buf.ReadFloat64()",
+	"varint64":  "buf.ReadVarInt64()",
+	"varuint64": "buf.ReadVarUint64()",
+}
+
+// readValue returns the Go expression that reads one value of fieldType, or
+// the call to its DecodeX function for types that can fail to decode.
+func (c *compiler) readValue(field *schema.Field, fieldType string) string {
+	switch fieldType {
+	case "alphanumeric":
+		if c.StringViews {
+			return "buf.ReadAlphanumericBytes()"
+		}
+		return "buf.ReadAlphanumeric()"
+
+	case "string":
+		if c.StringViews {
+			return "buf.ReadStringBytes()"
+		}
+		return "buf.ReadString()"
+	}
+
+	if code, ok := readMethods[fieldType]; ok {
+		return code
+	}
+
+	definition := c.definitions[fieldType]
+	switch {
+	case definition == nil:
+		fail(
+			"Invalid type "+quote(fieldType)+" for field "+quote(field.Name),
+			field.Line,
+			field.Column,
+		)
+	case definition.Kind == schema.Enum && !c.StrictEnums:
+		return pascalCase(definition.Name) + "(buf.ReadVarUint())"
+	case definition.Kind == schema.Smol && !c.StrictEnums:
+		return pascalCase(definition.Name) + "(buf.ReadUint8())"
+	}
+	return "Decode" + pascalCase(definition.Name) + "(buf)"
+}
+
+// DecodeXInto decodes into an existing value, so that decoding many values in
+// a row can reuse the slices and pointers of the previous one. DecodeX is
+// DecodeXInto on a new value.
+func (c *compiler) compileDecode(definition *schema.Definition) string {
+	name := pascalCase(definition.Name)
+	isMessage := definition.Kind == schema.Message
+	var lines []string
+	push := func(line ...string) { lines = append(lines, line...) }
+	indent := "  "
+	if isMessage {
+		indent = "      "
+	}
+
+	push("func Decode" + name + "(buf buffer.Reader) (" + name + ", error) {")
+	push("  result := " + name + "{}")
+	push("  err := Decode" + name + "Into(buf, &result)")
+	push("  return result, err")
+	push("}")
+	push("")
+
+	if isMessage {
+		push("// Decode" + name + "Into decodes into result, reusing what its fields point to.")
+	} else {
+		push("// Decode" + name + "Into decodes into result, reusing the room in its slices.")
+	}
+	push("func Decode" + name + "Into(buf buffer.Reader, result *" + name + ") error {")
+
+	// Declarations go right after the func line, each before the ones
+	// declared earlier.
+	startLine := len(lines)
+	hasLength, hasErr := false, false
+	declare := func(line string) {
+		lines = append(lines[:startLine], append([]string{"  " + line}, lines[startLine:]...)...)
+	}
+	needLength := func() {
+		if !hasLength {
+			declare("var length uint")
+		}
+		hasLength = true
+	}
+	needErr := func() {
+		if !hasErr {
+			declare("var err error")
+		}
+		hasErr = true
+	}
+
+	if isMessage {
+		// Fields that aren't in this message have to end up nil, so the old
+		// pointers are kept aside and only put back for fields that are read.
+		var kept, targets []string
+		for i := range definition.Fields {
+			field := &definition.Fields[i]
+			if !field.IsDeprecated && c.usesPointer(definition, field) {
+				kept = append(kept, keptName(field))
+				targets = append(targets, "result."+pascalCase(field.Name))
+			}
+		}
+		if len(kept) > 0 {
+			push("  " + strings.Join(kept, ", ") + " := " + strings.Join(targets, ", "))
+		}
+		push("  *result = " + name + "{UnknownFields: result.UnknownFields[:0]}")
+		push("")
+		push("  for {")
+		push("    switch fieldType := buf.ReadUint8(); fieldType {")
+		push("    case 0:")
+		var required []*schema.Field
+		for i := range definition.Fields {
+			field := &definition.Fields[i]
+			if field.IsRequired && !field.IsDeprecated {
+				required = append(required, field)
+			}
+		}
+		if len(required) > 0 {
+			// A read past the end also returns 0, so check for that first.
+			push("      if err := buf.Err(); err != nil {")
+			push("        return err")
+			push("      }")
+			for _, field := range required {
+				push(missingField(definition, field, "result"))
+			}
+		}
+		push("      return buf.Err()")
+		push("")
+	}
+
+	for i := range definition.Fields {
+		field := &definition.Fields[i]
+		fieldType := c.resolve(field.Type)
+
+		// Only messages can have deprecated fields, but a pick of one can copy
+		// them. Encode never writes them, so there's nothing to skip.
+		if field.IsDeprecated && !isMessage {
+			continue
+		}
+
+		// With StrictEnums, enums are decoded with a DecodeX function that can
+		// fail, the same as structs.
+		kind := c.kind(fieldType)
+		isPrimitiveType := typeNames[fieldType] != "" ||
+			(!c.StrictEnums && (kind == schema.Smol || kind == schema.Enum))
+		// Structs and messages are decoded into the value that's already there.
+		decodesInto := !isPrimitiveType && (kind == schema.Struct || kind == schema.Message)
+
+		code := c.readValue(field, fieldType)
+
+		// Typed arrays of a named type are converted as a whole below.
+		if c.namedTypes[field.Type] != "" && !field.IsDeprecated &&
+			!(field.IsArray && typedArrays[fieldType] != "") {
+			code = pascalCase(field.Type) + "(" + code + ")"
+		}
+
+		if isMessage {
+			push("    case " + strconv.Itoa(field.Value) + ":")
+		}
+
+		if field.IsDeprecated {
+			if field.IsArray && typedArrays[fieldType] != "" {
+				// Typed arrays are prefixed with their length in bytes, so they
+				// can all be skipped like a byte array.
+				push(indent + "buf.ReadByteArrayView()")
+				continue
+			}
+
+			loop := ""
+			if field.IsArray {
+				needLength()
+				push(indent + c.readLength(fieldType))
+				push(indent + "for j := uint(0); j < length; j++ {")
+				loop = "  "
+			}
+			if isPrimitiveType {
+				push(indent + loop + "_ = " + code)
+			} else {
+				push(indent + loop + "if _, err := " + code + "; err != nil {")
+				push(indent + loop + "  return err")
+				push(indent + loop + "}")
+			}
+			if field.IsArray {
+				push(indent + "}")
+			}
+			continue
+		}
+
+		// target is where the field is decoded to. Message fields are
+		// pointers, which are reused if the old value had one.
+		target := "result." + pascalCase(field.Name)
+		address := "&" + target
+		if c.usesPointer(definition, field) {
+			kept := keptName(field)
+			goType := c.goElementType(field.Type)
+			if field.IsArray {
+				goType = "[]" + goType
+			}
+			push(indent + "if " + kept + " == nil {")
+			push(indent + "  " + kept + " = new(" + goType + ")")
+			push(indent + "}")
+			push(indent + target + " = " + kept)
+			target = "(*" + kept + ")"
+			address = kept
+		}
+
+		switch {
+		case field.IsArray && typedArrays[fieldType] != "":
+			// Arrays of a named type are still read in one go, as a slice of
+			// the type it names, which has the same layout.
+			if c.namedTypes[field.Type] != "" {
+				target = "*(*[]" + c.goTypeName(fieldType) + ")(unsafe.Pointer(" + address + "))"
+			}
+			if c.StringViews && fieldType == "byte" {
+				push(indent + target + " = buf.ReadByteArrayView()")
+			} else {
+				push(indent + target + " = buf.Read" + typedArrays[fieldType] + "Into(" + target + ")")
+			}
+
+		case field.IsArray:
+			needLength()
+			push(indent + c.readLength(fieldType))
+			push(indent + "if " + target + " == nil || uint(cap(" + target + ")) < length {")
+			push(indent + "  " + target + " = make([]" + c.goElementType(field.Type) + ", length)")
+			push(indent + "} else {")
+			push(indent + "  " + target + " = " + target + "[:length]")
+			push(indent + "}")
+			push(indent + "for j := range " + target + " {")
+			switch {
+			case isPrimitiveType:
+				push(indent + "  " + target + "[j] = " + code)
+			case decodesInto:
+				needErr()
+				push(indent + "  if err = Decode" + pascalCase(fieldType) + "Into(buf, &" + target + "[j]); err != nil {")
+				push(indent + "    return err")
+				push(indent + "  }")
+			default:
+				needErr()
+				push(indent + "  if " + target + "[j], err = " + code + "; err != nil {")
+				push(indent + "    return err")
+				push(indent + "  }")
+			}
+			push(indent + "}")
+
+		case isPrimitiveType:
+			push(indent + target + " = " + code)
+
+		case decodesInto:
+			needErr()
+			push(indent + "if err = Decode" + pascalCase(fieldType) + "Into(buf, " + address + "); err != nil {")
+			push(indent + "  return err")
+			push(indent + "}")
+
+		default:
+			needErr()
+			push(indent + "if " + target + ", err = " + code + "; err != nil {")
+			push(indent + "  return err")
+			push(indent + "}")
+		}
+
+		if isMessage {
+			push("")
+		}
+	}
+
+	if isMessage {
+		push("    default:")
+		push("      unknown, err := buf.SkipField(" + quote(definition.Name) + ", fieldType)")
+		push("      if err != nil {")
+		push("        return err")
+		push("      }")
+		push("      result.UnknownFields = append(result.UnknownFields, unknown...)")
+		push("    }")
+		push("  }")
+	} else {
+		push("  return buf.Err()")
+	}
+
+	push("}")
+
+	return strings.Join(lines, "\n")
+}
+
+// readLength returns the line that reads the length of an array of fieldType.
+func (c *compiler) readLength(fieldType string) string {
+	if c.canBeEmpty(fieldType) {
+		return "length = buf.ReadVarUint()"
+	}
+	return "length = buf.ReadArrayLength()"
+}
+
+// keptName is the local a message keeps the old pointer of field in.
+func keptName(field *schema.Field) string {
+	return snakeCase(field.Name) + "_" + strconv.Itoa(field.Value)
+}
+
+// missingField returns the check a message makes for a field marked with [!]
+// in the schema, which is a pointer or a union that's nil when it's missing.
+// DecodeXInto and Encode both return it as their error.
+func missingField(definition *schema.Definition, field *schema.Field, value string) string {
+	return strings.Join([]string{
+		"  if " + value + "." + pascalCase(field.Name) + " == nil {",
+		"    return &buffer.MissingFieldError{Message: " + quote(definition.Name) + ", Field: " + quote(field.Name) + "}",
+		"  }",
+	}, "\n")
+}
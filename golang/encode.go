@@ -0,0 +1,342 @@
+package golang
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jarred-sumner/peechy/schema"
+)
+
+var writeMethods = map[string]string{
+	"bool":      "buf.WriteBool(%s);",
+	"byte":      "buf.WriteByte(%s);; // only used if not arr",
+	"int":       "buf.WriteVarInt(%s);",
+	"int8":      "buf.WriteInt8(%s);",
+	"int16":     "buf.WriteInt16(%s);",
+	"int32":     "buf.WriteInt32(%s);",
+	"uint":      "buf.WriteVarUint(%s);",
+	"lowp":      "buf.WriteLowpFloat(float64(%s));",
+	"uint8":     "buf.WriteByte(%s);",
+	"uint16":    "buf.WriteUint16(%s);",
+	"uint32":    "buf.WriteUint32(%s);",
+	"float":     "buf.WriteVarFloat(%s);",
+	"float32":   "buf.WriteFloat32(%s);",
+	"int64":     "buf.WriteInt64(%s);",
+	"uint64":    "buf.WriteUint64(%s);",
+	"float64":   "buf.WriteFloat64(%s);",
+	"varint64":  "buf.WriteVarInt64(%s);",
+	"varuint64": "buf.WriteVarUint64(%s);",
+}
+
+// Encode reserves the whole size up front. Nested values are written with the
+// unexported encode, so their sizes aren't worked out again.
+func (c *compiler) compileEncode(definition *schema.Definition) string {
+	name := pascalCase(definition.Name)
+	isMessage := definition.Kind == schema.Message
+	var lines []string
+	push := func(line ...string) { lines = append(lines, line...) }
+
+	push("func (i *" + name + ") Encode(buf buffer.Writer) error {")
+	push("  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))")
+	push("  return i.encode(buf)")
+	push("}")
+	push("")
+	push("func (i *" + name + ") encode(buf buffer.Writer) error {")
+
+	if isMessage {
+		for j := range definition.Fields {
+			field := &definition.Fields[j]
+			if field.IsRequired && !field.IsDeprecated {
+				push(missingField(definition, field, "i"))
+			}
+		}
+	}
+
+	// Declarations go after the line following the func, each before the
+	// ones declared earlier.
+	startLine := len(lines)
+	hasN, hasErr := false, false
+	declare := func(line string) {
+		at := startLine + 1
+		lines = append(lines[:at], append([]string{line}, lines[at:]...)...)
+	}
+
+	for j := range definition.Fields {
+		field := &definition.Fields[j]
+		fieldName := pascalCase(field.Name)
+
+		if field.IsDeprecated {
+			continue
+		}
+
+		fieldType := c.resolve(field.Type)
+
+		valueName := "i." + fieldName
+		if field.IsArray && typedArrays[fieldType] == "" {
+			if isMessage {
+				valueName = "(*i." + fieldName + ")[j]"
+			} else {
+				valueName = "i." + fieldName + "[j]"
+			}
+		}
+		if isMessage && !field.IsArray {
+			valueName = "*" + valueName
+		}
+		if c.namedTypes[field.Type] != "" && !(field.IsArray && typedArrays[fieldType] != "") {
+			valueName = c.goTypeName(fieldType) + "(" + valueName + ")"
+		}
+
+		var code string
+		switch fieldType {
+		case "alphanumeric":
+			if c.StringViews {
+				code = "buf.WriteAlphanumericBytes(" + valueName + ");"
+			} else {
+				code = "buf.WriteAlphanumeric(" + valueName + ");"
+			}
+
+		case "string":
+			if c.StringViews {
+				code = "buf.WriteStringBytes(" + valueName + ");"
+			} else {
+				code = "buf.WriteString(" + valueName + ");"
+			}
+
+		case "discriminator":
+			panic(errors.New("Discriminator not implmeneted"))
+
+		default:
+			if method, ok := writeMethods[fieldType]; ok {
+				code = strings.Replace(method, "%s", valueName, 1)
+				break
+			}
+
+			// value is the field, or the element of it that's written.
+			value := "i." + fieldName
+			if field.IsArray && isMessage {
+				value = "(*i." + fieldName + ")[j]"
+			} else if field.IsArray {
+				value = "i." + fieldName + "[j]"
+			} else if isMessage {
+				value = "*i." + fieldName
+			}
+
+			definition := c.definitions[fieldType]
+			switch {
+			case definition == nil:
+				panic(errors.New("Invalid type " + quote(fieldType) + " for field " + quote(field.Name)))
+			case definition.Kind == schema.Enum:
+				code = "buf.WriteVarUint(uint(" + value + "))"
+			case definition.Kind == schema.Smol:
+				code = "buf.WriteByte(byte(" + value + "))"
+			case definition.Kind == schema.Union:
+				// Unions are interfaces, which messages don't point to.
+				if !field.IsArray {
+					value = "i." + fieldName
+				}
+				code = "encode" + pascalCase(definition.Name) + "(buf, " + value + ")"
+			default:
+				code = strings.TrimPrefix(value, "*") + ".encode(buf)"
+			}
+		}
+
+		push("")
+
+		if isMessage {
+			push("  if i." + fieldName + " != nil {")
+			push("    buf.WriteByte(" + strconv.Itoa(field.Value) + ");")
+		}
+
+		kind := c.kind(fieldType)
+		switch {
+		case field.IsArray && typedArrays[fieldType] != "":
+			if c.namedTypes[field.Type] != "" {
+				// Arrays of a named type are written as a slice of the type it
+				// names, which has the same layout.
+				address := "&" + valueName
+				if isMessage {
+					address = valueName
+				}
+				valueName = "*(*[]" + c.goTypeName(fieldType) + ")(unsafe.Pointer(" + address + "))"
+			} else if isMessage {
+				valueName = "*" + valueName
+			}
+			push("   buf.Write" + typedArrays[fieldType] + "(" + valueName + ");")
+
+		case field.IsArray:
+			if !hasN {
+				declare("    var n uint;")
+				hasN = true
+			}
+			if isMessage {
+				push("    n = uint(len(*i." + fieldName + "))")
+			} else {
+				push("    n = uint(len(i." + fieldName + "))")
+			}
+
+			push("    buf.WriteVarUint(n);")
+			push("    for j := uint(0); j < n; j++ {")
+
+			if typeNames[fieldType] == "" &&
+				(kind == schema.Struct || kind == schema.Message || kind == schema.Union) {
+				push("      err := " + code)
+				push("      if err != nil {\nreturn err;\n}\n")
+			} else {
+				push("      " + code)
+			}
+			push("    }")
+
+		case c.isPrimitive(fieldType):
+			push("    " + code)
+
+		default:
+			if !hasErr {
+				declare("var err error;")
+				hasErr = true
+			}
+			push("    err =" + code)
+			push("    if err != nil {\n return err\n}\n")
+		}
+
+		if isMessage {
+			push("   }")
+		}
+	}
+
+	// A field id of zero is reserved to indicate the end of the message
+	if isMessage {
+		push("  buf.Write(i.UnknownFields);")
+		push("  buf.WriteByte(0);")
+	}
+
+	push("  return nil")
+	push("}")
+
+	return strings.Join(lines, "\n")
+}
+
+var isNumber = regexp.MustCompile(`^\d+$`)
+
+// sizeOf returns the Go expression for how many bytes encode writes for
+// value, which has the resolved type fieldType.
+func (c *compiler) sizeOf(fieldType, value string) string {
+	if size, ok := fixedSizes[fieldType]; ok {
+		return strconv.Itoa(size)
+	}
+
+	switch fieldType {
+	case "int":
+		return "format.VarIntSize(" + value + ")"
+	case "uint":
+		return "format.VarUintSize(" + value + ")"
+	case "lowp":
+		return "format.LowpFloatSize(float64(" + value + "))"
+	case "float":
+		return "buffer.VarFloatSize(" + value + ")"
+	case "varint64":
+		return "buffer.VarInt64Size(" + value + ")"
+	case "varuint64":
+		return "buffer.VarUint64Size(" + value + ")"
+	case "string":
+		return "format.BytesSize(len(" + value + "))"
+	case "alphanumeric":
+		return "len(" + value + ") + 1"
+	}
+
+	switch c.kind(fieldType) {
+	case schema.Enum:
+		return "format.VarUintSize(uint(" + value + "))"
+	case schema.Smol:
+		return "1"
+	case schema.Union:
+		return "EncodedSize" + pascalCase(fieldType) + "(format, " + value + ")"
+	default:
+		return value + ".EncodedSizeIn(format)"
+	}
+}
+
+// EncodedSize works out exactly how many bytes Encode writes, without
+// encoding, so that Encode can reserve them in one go and callers can check a
+// size limit first.
+func (c *compiler) compileEncodedSize(definition *schema.Definition) string {
+	name := pascalCase(definition.Name)
+	isMessage := definition.Kind == schema.Message
+	var lines []string
+	push := func(line ...string) { lines = append(lines, line...) }
+
+	push("// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.")
+	push("func (i *" + name + ") EncodedSize() int {")
+	push("  return i.EncodedSizeIn(buffer.FixedWidthFormat)")
+	push("}")
+	push("")
+	push("// EncodedSizeIn returns how many bytes Encode writes with format.")
+	push("func (i *" + name + ") EncodedSizeIn(format buffer.WireFormat) int {")
+
+	// Messages end with their unknown fields and the zero field number.
+	if isMessage {
+		push("  n := len(i.UnknownFields) + 1")
+	} else {
+		push("  n := 0")
+	}
+
+	for j := range definition.Fields {
+		field := &definition.Fields[j]
+		if field.IsDeprecated {
+			continue
+		}
+
+		fieldName := pascalCase(field.Name)
+		fieldType := c.resolve(field.Type)
+
+		isUnion := typeNames[fieldType] == "" && c.kind(fieldType) == schema.Union
+		value := "i." + fieldName
+		if isMessage && (field.IsArray || !isUnion) {
+			value = "(*i." + fieldName + ")"
+		}
+		indent := "  "
+
+		if isMessage {
+			push("  if i." + fieldName + " != nil {")
+			push("    n++")
+			indent = "    "
+		}
+
+		switch {
+		case field.IsArray && typedArrays[fieldType] != "":
+			push(indent + "n += format.BytesSize(len(" + value + ") * " + strconv.Itoa(fixedSizes[fieldType]) + ")")
+
+		case field.IsArray:
+			push(indent + "n += format.VarUintSize(uint(len(" + value + ")))")
+			element := value + "[j]"
+			if c.namedTypes[field.Type] != "" {
+				element = c.goTypeName(fieldType) + "(" + element + ")"
+			}
+			size := c.sizeOf(fieldType, element)
+			if isNumber.MatchString(size) {
+				push(indent + "n += len(" + value + ") * " + size)
+			} else {
+				push(indent + "for j := range " + value + " {")
+				push(indent + "  n += " + size)
+				push(indent + "}")
+			}
+
+		default:
+			element := value
+			if c.namedTypes[field.Type] != "" {
+				element = c.goTypeName(fieldType) + "(" + element + ")"
+			}
+			push(indent + "n += " + c.sizeOf(fieldType, element))
+		}
+
+		if isMessage {
+			push("  }")
+		}
+	}
+
+	push("  return n")
+	push("}")
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,146 @@
+package golang
+
+import (
+	"strings"
+
+	"github.com/jarred-sumner/peechy/schema"
+)
+
+const enumMethods = `
+// ${name}Values returns every ${name} in the order of the schema.
+func ${name}Values() []${name} {
+  return []${name}{${constants}}
+}
+
+// IsValid reports whether s is one of the ${name} constants.
+func (s ${name}) IsValid() bool {
+  _, ok := ${name}ToString[s]
+  return ok
+}
+
+// String returns the name s has in the schema.
+func (s ${name}) String() string {
+  if name, ok := ${name}ToString[s]; ok {
+    return name
+  }
+  return "${name}(" + strconv.FormatUint(uint64(s), 10) + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler. It writes what MarshalJSON
+// does without the quotes, so that maps keyed by ${name} marshal too.
+func (s ${name}) MarshalText() ([]byte, error) {
+  if name, ok := ${name}ToString[s]; ok {
+    return []byte(name), nil
+  }
+  ${unknown}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the name of
+// one of the values in the schema, or ${numbers}.
+func (s *${name}) UnmarshalText(text []byte) error {
+  if value, ok := ${name}ToID[string(text)]; ok {
+    *s = value
+    return nil
+  }
+  value, err := strconv.ParseUint(string(text), 10, ${bits})
+  if err != nil {
+    return &buffer.InvalidEnumError{Enum: ${quoted}, Name: string(text)}
+  }
+${check}  *s = ${name}(value)
+  return nil
+}
+
+// MarshalJSON marshals the enum as its name in the schema, the same as
+// JavaScript, or ${unknownDoc}.
+func (s ${name}) MarshalJSON() ([]byte, error) {
+  if name, ok := ${name}ToString[s]; ok {
+    return []byte(` + "`\"` + name + `\"`" + `), nil
+  }
+  ${unknown}
+}
+
+// UnmarshalJSON unmarshals a quoted json string, which has to be the name of
+// one of the values in the schema, or ${numbers}.
+func (s *${name}) UnmarshalJSON(b []byte) error {
+  if string(b) == "null" {
+    return nil
+  }
+
+  var j string
+  if err := json.Unmarshal(b, &j); err == nil {
+    return s.UnmarshalText([]byte(j))
+  }
+
+  var value ${underlying}
+  if err := json.Unmarshal(b, &value); err != nil {
+    return err
+  }
+${check}  *s = ${name}(value)
+  return nil
+}
+${strict}`
+
+const strictEnumDecode = `
+// Decode${name} reads a ${name}, and fails for values that aren't one of the
+// constants.
+func Decode${name}(buf buffer.Reader) (${name}, error) {
+  value := ${name}(${read})
+  if err := buf.Err(); err != nil {
+    return value, err
+  }
+  if !value.IsValid() {
+    return value, &buffer.InvalidEnumError{Enum: ${quoted}, Value: uint(value)}
+  }
+  return value, nil
+}
+`
+
+// strictEnumCheck rejects a number that isn't one of the constants when
+// unmarshaling text or JSON.
+const strictEnumCheck = `  if !${name}(value).IsValid() {
+    return &buffer.InvalidEnumError{Enum: ${quoted}, Value: uint(value)}
+  }
+`
+
+// String, text and JSON methods for an enum. Values that aren't one of the
+// constants, which DecodeX keeps unless StrictEnums is set, are written to
+// text and JSON as numbers so that they aren't lost. With StrictEnums, the
+// text and JSON methods reject them the same as DecodeX.
+func (c *compiler) compileEnumMethods(definition *schema.Definition) string {
+	name := pascalCase(definition.Name)
+	var constants []string
+	for _, field := range definition.Fields {
+		constants = append(constants, name+pascalCase(field.Name))
+	}
+	underlying, bits, read := "uint", "0", "buf.ReadVarUint()"
+	if definition.Kind == schema.Smol {
+		underlying, bits, read = "byte", "8", "buf.ReadUint8()"
+	}
+	strict, check := "", ""
+	unknown := "return []byte(strconv.FormatUint(uint64(s), 10)), nil"
+	unknownDoc, numbers := "as a number if it isn't one of the constants", "a number"
+	if c.StrictEnums {
+		strict = strictEnumDecode
+		check = strictEnumCheck
+		unknown = "return nil, &buffer.InvalidEnumError{Enum: ${quoted}, Value: uint(s)}"
+		unknownDoc, numbers = "fails if it isn't one of the constants", "the number of one of them"
+	}
+
+	// What depends on StrictEnums goes in first, so that its placeholders
+	// are replaced too.
+	code := strings.NewReplacer(
+		"${strict}", strict,
+		"${check}", check,
+		"${unknown}", unknown,
+		"${unknownDoc}", unknownDoc,
+		"${numbers}", numbers,
+	).Replace(enumMethods)
+	return strings.NewReplacer(
+		"${name}", name,
+		"${constants}", strings.Join(constants, ", "),
+		"${quoted}", quote(definition.Name),
+		"${underlying}", underlying,
+		"${bits}", bits,
+		"${read}", read,
+	).Replace(code)
+}
@@ -0,0 +1,562 @@
+// Package golang generates Go code from a schema, the same as js/go.ts. The
+// code it returns is byte for byte what the JavaScript generator returns, so
+// the two can be used interchangeably.
+package golang
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jarred-sumner/peechy/schema"
+)
+
+// Options are the choices js/cli.ts has --go-strings, --go-aliases and
+// --go-enums for.
+type Options struct {
+	// StringViews decodes strings as []byte slices of the buffer being
+	// decoded instead of copies. They are only valid as long as the buffer is.
+	StringViews bool
+	// TypeAliases makes aliases of built-in types type aliases instead of
+	// named types.
+	TypeAliases bool
+	// StrictEnums makes decoding fail for enum values that aren't in the
+	// schema, instead of keeping them.
+	StrictEnums bool
+}
+
+var typeNames = map[string]string{
+	"bool":         "bool",
+	"byte":         "byte",
+	"float":        "float32",
+	"int":          "int",
+	"uint8":        "uint8",
+	"uint16":       "uint16",
+	"uint32":       "uint32",
+	"int8":         "int8",
+	"int16":        "int16",
+	"float32":      "float32",
+	"int32":        "int32",
+	"lowp":         "float32",
+	"string":       "string",
+	"uint":         "uint",
+	"alphanumeric": "string",
+	"int64":        "int64",
+	"uint64":       "uint64",
+	"float64":      "float64",
+	"varint64":     "int64",
+	"varuint64":    "uint64",
+}
+
+// Arrays of these types are read and written in one call, with the buffer
+// methods named here.
+var typedArrays = map[string]string{
+	"byte":    "ByteArray",
+	"int8":    "Int8Array",
+	"int16":   "Int16Array",
+	"uint16":  "UInt16Array",
+	"int32":   "Int32Array",
+	"uint32":  "UInt32Array",
+	"float32": "Float32Array",
+	"int64":   "Int64Array",
+	"uint64":  "UInt64Array",
+	"float64": "Float64Array",
+}
+
+var fixedSizes = map[string]int{
+	"bool":    1,
+	"byte":    1,
+	"int8":    1,
+	"uint8":   1,
+	"int16":   2,
+	"uint16":  2,
+	"int32":   4,
+	"uint32":  4,
+	"float32": 4,
+	"int64":   8,
+	"uint64":  8,
+	"float64": 8,
+}
+
+var typeKinds = map[schema.Kind]string{
+	schema.Enum:    "buffer.EnumType",
+	schema.Smol:    "buffer.SmolType",
+	schema.Struct:  "buffer.StructType",
+	schema.Message: "buffer.MessageType",
+	schema.Union:   "buffer.UnionType",
+}
+
+// compiler is the state go.ts passes to each of its functions.
+type compiler struct {
+	Options
+	schema      *schema.Schema
+	definitions map[string]*schema.Definition
+	aliases     map[string]string
+	namedTypes  map[string]string
+}
+
+// Compile returns the Go code for s. Errors in the schema are a *schema.Error.
+func Compile(s *schema.Schema, options Options) (code string, err error) {
+	// Like the parser, the generator stops at the first error by panicking
+	// with it.
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(error)
+			if !ok {
+				panic(r)
+			}
+			code, err = "", e
+		}
+	}()
+
+	c := &compiler{
+		Options:     options,
+		schema:      s,
+		definitions: map[string]*schema.Definition{},
+		aliases:     map[string]string{},
+		namedTypes:  map[string]string{},
+	}
+	return c.compileSchema(), nil
+}
+
+// CompileText parses src and returns the Go code for it.
+func CompileText(src string, options Options) (string, error) {
+	s, err := schema.Parse(src)
+	if err != nil {
+		return "", err
+	}
+	return Compile(s, options)
+}
+
+func fail(message string, line, column int) {
+	panic(&schema.Error{Message: message, Line: line, Column: column})
+}
+
+func (c *compiler) compileSchema() string {
+	s := c.schema
+	var goCode []string
+	push := func(lines ...string) { goCode = append(goCode, lines...) }
+
+	if s.Package != "" {
+		push("package " + s.Package)
+	} else {
+		push("package Schema")
+	}
+	push("")
+	var aliasNames []string
+	for _, definition := range s.Definitions {
+		c.definitions[definition.Name] = definition
+		if definition.Kind == schema.Alias {
+			if _, ok := c.aliases[definition.Name]; !ok {
+				aliasNames = append(aliasNames, definition.Name)
+			}
+			c.aliases[definition.Name] = definition.Fields[0].Name
+		}
+	}
+
+	// Aliases of aliases are resolved down to the type they end up at.
+	for _, name := range aliasNames {
+		seen := []string{name}
+		for c.aliases[c.aliases[name]] != "" {
+			if contains(seen, c.aliases[name]) {
+				fail(
+					"Alias "+quote(name)+" refers to itself",
+					c.definitions[name].Line,
+					c.definitions[name].Column,
+				)
+			}
+			seen = append(seen, c.aliases[name])
+			c.aliases[name] = c.aliases[c.aliases[name]]
+		}
+
+		// Aliases of built-in types become named types, unless TypeAliases is
+		// set. Aliases of definitions are always type aliases, so they keep
+		// the methods of what they alias.
+		if !c.TypeAliases && typeNames[c.aliases[name]] != "" {
+			c.namedTypes[name] = c.aliases[name]
+		}
+	}
+
+	// Only import what the generated code uses, or it won't compile.
+	hasKind := func(kinds ...schema.Kind) bool {
+		for _, definition := range s.Definitions {
+			for _, kind := range kinds {
+				if definition.Kind == kind {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	push("import (")
+	if hasKind(schema.Union) {
+		push(` "errors"`)
+	}
+	if hasKind(schema.Enum, schema.Smol) {
+		push(` "encoding/json"`)
+		push(` "strconv"`)
+	}
+	if c.hasFloats() {
+		push(` "math"`)
+	}
+	if c.hasNamedArrays() {
+		push(` "unsafe"`)
+	}
+	push(` "github.com/jarred-sumner/peechy/buffer"`)
+	var messages []*schema.Definition
+	for _, definition := range s.Definitions {
+		if definition.Kind == schema.Struct || definition.Kind == schema.Message {
+			messages = append(messages, definition)
+		}
+	}
+	if len(messages) > 0 {
+		push(` "github.com/jarred-sumner/peechy/message"`)
+	}
+	push(")")
+
+	for _, definition := range s.Definitions {
+		switch definition.Kind {
+		case schema.Alias:
+			target := definition.Fields[0].Name
+			equals := "= "
+			if c.namedTypes[definition.Name] != "" {
+				equals = ""
+			}
+			push("type " + pascalCase(definition.Name) + " " + equals + c.goElementType(target))
+			push("")
+
+		case schema.Smol, schema.Enum:
+			name := pascalCase(definition.Name)
+			underlying := "uint"
+			if definition.Kind == schema.Smol {
+				underlying = "byte"
+			}
+			push("type " + name + " " + underlying)
+			push("")
+
+			push("const (")
+			var constantValues, stringValues, invertValues []string
+			for _, field := range definition.Fields {
+				intName := name + pascalCase(field.Name)
+				constantValues = append(constantValues, "  "+intName+" "+name+" = "+strconv.Itoa(field.Value))
+				// The names are the schema's, which JavaScript and peechyjson
+				// use too.
+				stringValues = append(stringValues, "  "+quote(field.Name)+": "+intName+",")
+				invertValues = append(invertValues, "  "+intName+": "+quote(field.Name)+",")
+			}
+			push(constantValues...)
+			push("")
+			push(")")
+			push("")
+
+			push("var " + name + "ToString = map[" + name + "]string{")
+			push(invertValues...)
+			push("")
+			push("}")
+			push("")
+
+			push("var " + name + "ToID = map[string]" + name + "{")
+			push(stringValues...)
+			push("")
+			push("}")
+			push("")
+
+			push(c.compileEnumMethods(definition))
+
+		case schema.Union:
+			push(c.compileUnion(definition))
+			push("")
+
+		case schema.Struct, schema.Message:
+			push("type " + pascalCase(definition.Name) + " struct {")
+			for _, field := range definition.Fields {
+				// Deprecated fields are skipped when decoding and never written.
+				if field.IsDeprecated {
+					continue
+				}
+
+				typeName := c.goElementType(field.Type)
+				if field.IsArray {
+					typeName = "[]" + typeName
+				}
+				if c.usesPointer(definition, &field) {
+					typeName = "*" + typeName
+				}
+
+				jsonName := camelCase(field.Name)
+				push(pascalCase(field.Name) + "    " + typeName + "     `json:\"" + jsonName + "\" redis:\"" + jsonName + "\"`")
+			}
+			if definition.Kind == schema.Message {
+				push("")
+				push("// UnknownFields are fields from a newer schema that DecodeX skipped, which Encode writes back.")
+				push("UnknownFields []byte `json:\"-\" redis:\"-\"`")
+			}
+			push("}")
+
+			push("")
+			push(c.compileDecode(definition))
+			push("")
+			push(c.compileEncode(definition))
+			push("")
+			push(c.compileMessageMethods(definition))
+			push("")
+			push(c.compileReset(definition))
+			push("")
+			push(c.compileEqual(definition))
+			push("")
+			push(c.compileClone(definition))
+			push("")
+			push(c.compileEncodedSize(definition))
+			push("")
+			if definition.PickFrom != "" {
+				push(c.compilePick(definition))
+				push("")
+			}
+
+		default:
+			fail(
+				"Invalid definition kind "+quote(string(definition.Kind)),
+				definition.Line,
+				definition.Column,
+			)
+		}
+	}
+
+	push(c.compileTypes())
+	push("")
+
+	// Types with a package register themselves, and two registered types
+	// with the same name means two schemas have the same package, which is a
+	// bug. The names of types without one could be in any other schema too,
+	// so those are only registered when the caller asks.
+	if len(messages) > 0 {
+		fail := "panic(err)"
+		if s.Package != "" {
+			push("func init() {")
+		} else {
+			fail = "return err"
+			push("// Register registers the types in the schema with package message. It isn't")
+			push("// done on import, since the schema has no package and other schemas could")
+			push("// have types with the same names. It returns a *message.RegisterError if one")
+			push("// of them is already registered.")
+			push("func Register() error {")
+		}
+		push("  for _, newMessage := range []func() message.Message{")
+		for _, definition := range messages {
+			push("    func() message.Message { return new(" + pascalCase(definition.Name) + ") },")
+		}
+		push("  } {")
+		push("    if err := message.Register(newMessage); err != nil {")
+		push("      " + fail)
+		push("    }")
+		push("  }")
+		if s.Package == "" {
+			push("  return nil")
+		}
+		push("}")
+		push("")
+	}
+
+	return strings.Join(goCode, "\n")
+}
+
+// goElementType returns the Go type of a single value of a field, which is
+// the alias itself for fields that use one.
+func (c *compiler) goElementType(fieldType string) string {
+	if name := c.goTypeName(fieldType); name != "" {
+		return name
+	}
+	return pascalCase(fieldType)
+}
+
+// With StringViews, string fields are []byte slices of the buffer being
+// decoded instead of copies.
+func (c *compiler) goTypeName(fieldType string) string {
+	if c.StringViews && (fieldType == "string" || fieldType == "alphanumeric") {
+		return "[]byte"
+	}
+	return typeNames[fieldType]
+}
+
+// hasFloats reports whether a struct or message has a float field, which
+// Equal compares with the math package.
+func (c *compiler) hasFloats() bool {
+	for _, definition := range c.schema.Definitions {
+		if definition.Kind != schema.Struct && definition.Kind != schema.Message {
+			continue
+		}
+		for _, field := range definition.Fields {
+			switch typeNames[c.resolve(field.Type)] {
+			case "float32", "float64":
+				if !field.IsDeprecated {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// hasNamedArrays returns whether a struct or message has an array of a named
+// number type. Those are read and written in one go as a slice of the number
+// type, which needs package unsafe.
+func (c *compiler) hasNamedArrays() bool {
+	for _, definition := range c.schema.Definitions {
+		if definition.Kind != schema.Struct && definition.Kind != schema.Message {
+			continue
+		}
+		for _, field := range definition.Fields {
+			if field.IsArray && !field.IsDeprecated && c.namedTypes[field.Type] != "" &&
+				typedArrays[c.namedTypes[field.Type]] != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolve returns the type an alias ends up at, or fieldType itself.
+func (c *compiler) resolve(fieldType string) string {
+	if alias := c.aliases[fieldType]; alias != "" {
+		return alias
+	}
+	return fieldType
+}
+
+// kind returns the kind of the definition called name, or "" for built-in
+// types.
+func (c *compiler) kind(name string) schema.Kind {
+	if definition := c.definitions[name]; definition != nil {
+		return definition.Kind
+	}
+	return ""
+}
+
+// isPrimitive reports whether values of fieldType are written directly
+// instead of with a method.
+func (c *compiler) isPrimitive(fieldType string) bool {
+	kind := c.kind(fieldType)
+	return typeNames[fieldType] != "" || kind == schema.Smol || kind == schema.Enum
+}
+
+// Message fields are pointers so that missing fields can be told apart.
+// Unions are interfaces, which can already be nil.
+func (c *compiler) usesPointer(definition *schema.Definition, field *schema.Field) bool {
+	return definition.Kind == schema.Message &&
+		(field.IsArray || c.kind(field.Type) != schema.Union)
+}
+
+func (c *compiler) isDiscriminatedUnion(name string) bool {
+	definition := c.definitions[name]
+	return definition != nil && len(definition.Fields) > 0 &&
+		definition.Fields[0].Type == "discriminator"
+}
+
+// A struct whose fields are all empty structs encodes to zero bytes, so an
+// array of them can be longer than the bytes left in the buffer.
+func (c *compiler) canBeEmpty(name string) bool {
+	definition := c.definitions[name]
+	if definition == nil || definition.Kind != schema.Struct {
+		return false
+	}
+	for _, field := range definition.Fields {
+		if field.IsArray || !c.canBeEmpty(field.Type) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// quote quotes text like JSON.stringify.
+func quote(text string) string {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(text)
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// The case conversions of the change-case package go.ts uses. Words are split
+// at changes from lower to upper case and at anything that isn't a letter or
+// a digit.
+var (
+	lowerUpper = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+	upperUpper = regexp.MustCompile(`([A-Z])([A-Z][a-z])`)
+	separators = regexp.MustCompile(`[^A-Za-z0-9]+`)
+)
+
+func words(text string) []string {
+	text = lowerUpper.ReplaceAllString(text, "$1 $2")
+	text = upperUpper.ReplaceAllString(text, "$1 $2")
+	var result []string
+	for _, word := range separators.Split(text, -1) {
+		if word != "" {
+			result = append(result, word)
+		}
+	}
+	return result
+}
+
+func pascalWord(word string, index int) string {
+	first, rest := word[:1], strings.ToLower(word[1:])
+	if index > 0 && first[0] >= '0' && first[0] <= '9' {
+		return "_" + first + rest
+	}
+	return strings.ToUpper(first) + rest
+}
+
+// initialisms are the words Go spells in one case, like the ID in
+// message.TypeID. Names are pascal cased with these upper case, so "userId"
+// becomes UserID, but camelCase leaves them alone to match JavaScript.
+var initialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true,
+	"DNS": true, "EOF": true, "GUID": true, "HTML": true, "HTTP": true,
+	"HTTPS": true, "ID": true, "IP": true, "JSON": true, "QPS": true,
+	"RAM": true, "RPC": true, "SQL": true, "SSH": true, "TCP": true,
+	"TLS": true, "TTL": true, "UDP": true, "UI": true, "UID": true,
+	"UUID": true, "URI": true, "URL": true, "UTF8": true, "VM": true,
+	"XML": true,
+}
+
+func pascalCase(text string) string {
+	var out strings.Builder
+	for index, word := range words(text) {
+		if upper := strings.ToUpper(word); initialisms[upper] {
+			out.WriteString(upper)
+		} else {
+			out.WriteString(pascalWord(word, index))
+		}
+	}
+	return out.String()
+}
+
+func camelCase(text string) string {
+	var out strings.Builder
+	for index, word := range words(text) {
+		if index == 0 {
+			out.WriteString(strings.ToLower(word))
+		} else {
+			out.WriteString(pascalWord(word, index))
+		}
+	}
+	return out.String()
+}
+
+func snakeCase(text string) string {
+	list := words(text)
+	for index, word := range list {
+		list[index] = strings.ToLower(word)
+	}
+	return strings.Join(list, "_")
+}
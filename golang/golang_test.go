@@ -0,0 +1,107 @@
+package golang_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/jarred-sumner/peechy/golang"
+	"github.com/jarred-sumner/peechy/schema"
+)
+
+// The checked-in generated code comes from js/go.ts, which Compile has to
+// match exactly.
+func TestCompileMatchesJS(t *testing.T) {
+	for _, test := range []struct {
+		schema, code string
+		options      golang.Options
+	}{
+		{"../js/simple-schema.kiwi", "../js/test_schema.go", golang.Options{}},
+		{"../test/test-go.kiwi", "../test/gotest/schema.go", golang.Options{}},
+		{"../test/test-go-strict.kiwi", "../test/gostrict/schema.go", golang.Options{StrictEnums: true}},
+	} {
+		src, err := ioutil.ReadFile(test.schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := ioutil.ReadFile(test.code)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := golang.CompileText(string(src), test.options)
+		if err != nil {
+			t.Errorf("%s: %v", test.schema, err)
+			continue
+		}
+		if got != string(want) {
+			t.Errorf("%s: generated code differs from %s", test.schema, test.code)
+		}
+	}
+}
+
+func TestCompileNames(t *testing.T) {
+	code, err := golang.CompileText(`package names;
+enum HTTPStatus { NOT_FOUND = 1; }
+struct Vector3D { float x_2; string userId; }`, golang.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"type HTTPStatus uint",
+		"HTTPStatusNotFound HTTPStatus = 1",
+		"func DecodeVector3D(buf buffer.Reader) (Vector3D, error) {",
+		"X_2    float32     `json:\"x_2\" redis:\"x_2\"`",
+		"UserID    string     `json:\"userId\" redis:\"userId\"`",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("expected %q in the generated code", want)
+		}
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	_, err := golang.CompileText("alias A = B;\nalias B = A;", golang.Options{})
+
+	var e *schema.Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected a *schema.Error, got %v", err)
+	}
+	if want := (schema.Error{Message: `Alias "A" refers to itself`, Line: 1, Column: 7}); *e != want {
+		t.Errorf("expected %v, got %v", want, *e)
+	}
+}
+
+func TestCompileUnionWrapperError(t *testing.T) {
+	_, err := golang.CompileText("struct AnyShape { int x; }\nunion Shape = AnyShape;\nunion Any = Shape;", golang.Options{})
+
+	var e *schema.Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected a *schema.Error, got %v", err)
+	}
+	if want := `The member "Shape" of "Any" needs the Go type "AnyShape", which is already used by "AnyShape"`; e.Message != want {
+		t.Errorf("expected %q, got %q", want, e.Message)
+	}
+}
+
+// Types in schemas without a package aren't registered on import, since
+// their names could clash with another schema's. They get a Register function
+// to call instead.
+func TestCompileRegister(t *testing.T) {
+	for _, test := range []struct {
+		src, want string
+	}{
+		{"package game;\nstruct Point { int x; }", "func init() {"},
+		{"struct Point { int x; }", "func Register() error {"},
+	} {
+		code, err := golang.CompileText(test.src, golang.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(code, test.want) || !strings.Contains(code, "message.Register(newMessage)") {
+			t.Errorf("%q: expected the types to be registered from %q", test.src, test.want)
+		}
+	}
+}
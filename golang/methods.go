@@ -0,0 +1,389 @@
+package golang
+
+import (
+	"hash/crc32"
+	"strconv"
+	"strings"
+
+	"github.com/jarred-sumner/peechy/schema"
+)
+
+// Reset empties a value for reuse, for example from a sync.Pool, keeping the
+// room in its slices for DecodeXInto. Message fields are pointers that have to
+// go back to nil, so only DecodeXInto on a value that wasn't reset can reuse
+// what they point to.
+func (c *compiler) compileReset(definition *schema.Definition) string {
+	name := pascalCase(definition.Name)
+	var lines, kept []string
+
+	lines = append(lines, "// Reset empties i, keeping the room in its slices for Decode"+name+"Into.")
+	lines = append(lines, "func (i *"+name+") Reset() {")
+
+	if definition.Kind == schema.Message {
+		kept = append(kept, "UnknownFields: i.UnknownFields[:0]")
+	} else {
+		for _, field := range definition.Fields {
+			if field.IsDeprecated {
+				continue
+			}
+
+			fieldName := pascalCase(field.Name)
+			fieldType := c.resolve(field.Type)
+
+			if field.IsArray {
+				kept = append(kept, fieldName+": i."+fieldName+"[:0]")
+			} else if typeNames[fieldType] == "" && c.kind(fieldType) == schema.Struct {
+				lines = append(lines, "  i."+fieldName+".Reset()")
+				kept = append(kept, fieldName+": i."+fieldName)
+			}
+		}
+	}
+
+	lines = append(lines, "  *i = "+name+"{"+strings.Join(kept, ", ")+"}")
+	lines = append(lines, "}")
+
+	return strings.Join(lines, "\n")
+}
+
+// notEqual returns the Go condition for two values of fieldType differing.
+// named is whether they have the named type of an alias instead.
+func (c *compiler) notEqual(fieldType, a, b string, named bool) string {
+	if c.StringViews && (fieldType == "string" || fieldType == "alphanumeric") {
+		return "string(" + a + ") != string(" + b + ")"
+	}
+	// Floats are compared by their bits, so that NaN equals itself and a
+	// value equals its Clone.
+	switch goType := typeNames[fieldType]; goType {
+	case "float32", "float64":
+		if named {
+			a, b = goType+"("+a+")", goType+"("+b+")"
+		}
+		bits := "math.Float32bits"
+		if goType == "float64" {
+			bits = "math.Float64bits"
+		}
+		return bits + "(" + a + ") != " + bits + "(" + b + ")"
+	}
+	if typeNames[fieldType] != "" {
+		return a + " != " + b
+	}
+
+	switch c.kind(fieldType) {
+	case schema.Enum, schema.Smol:
+		return a + " != " + b
+	case schema.Union:
+		return "!Equal" + pascalCase(fieldType) + "(" + a + ", " + b + ")"
+	default:
+		return "!" + a + ".Equal(&" + b + ")"
+	}
+}
+
+// Equal compares values field by field. Nil and empty slices are equal, since
+// they encode the same, but a message field that isn't set only equals
+// another one that isn't set. Floats are equal when their bits are.
+func (c *compiler) compileEqual(definition *schema.Definition) string {
+	name := pascalCase(definition.Name)
+	isMessage := definition.Kind == schema.Message
+	var lines []string
+	push := func(line ...string) { lines = append(lines, line...) }
+
+	if isMessage {
+		push("// Equal reports whether i and other have the same fields set to the same values.")
+	} else {
+		push("// Equal reports whether i and other have the same fields.")
+	}
+	push("func (i *" + name + ") Equal(other *" + name + ") bool {")
+	push("  if i == nil || other == nil {")
+	push("    return i == other")
+	push("  }")
+
+	for j := range definition.Fields {
+		field := &definition.Fields[j]
+		if field.IsDeprecated {
+			continue
+		}
+
+		fieldName := pascalCase(field.Name)
+		fieldType := c.resolve(field.Type)
+
+		a := "i." + fieldName
+		b := "other." + fieldName
+		named := c.namedTypes[field.Type] != ""
+		outer := len(lines)
+		isPointer := c.usesPointer(definition, field)
+		if isPointer {
+			push("  if (" + a + " == nil) != (" + b + " == nil) {")
+			push("    return false")
+			push("  }")
+			push("  if " + a + " != nil {")
+			a = "(*" + a + ")"
+			b = "(*" + b + ")"
+		}
+
+		if field.IsArray {
+			push("  if len(" + a + ") != len(" + b + ") {")
+			push("    return false")
+			push("  }")
+			push("  for j := range " + a + " {")
+			push("    if " + c.notEqual(fieldType, a+"[j]", b+"[j]", named) + " {")
+			push("      return false")
+			push("    }")
+			push("  }")
+		} else {
+			push("  if " + c.notEqual(fieldType, a, b, named) + " {")
+			push("    return false")
+			push("  }")
+		}
+
+		if isPointer {
+			// Indent what's inside the nil check.
+			for k := outer + 4; k < len(lines); k++ {
+				lines[k] = "  " + lines[k]
+			}
+			push("  }")
+		}
+	}
+
+	if isMessage {
+		push("  return string(i.UnknownFields) == string(other.UnknownFields)")
+	} else {
+		push("  return true")
+	}
+	push("}")
+
+	return strings.Join(lines, "\n")
+}
+
+// cloneValue returns the Go expression for a deep copy of a value of
+// fieldType, or "" if copying it with = is enough.
+func (c *compiler) cloneValue(fieldType, value string) string {
+	if c.StringViews && (fieldType == "string" || fieldType == "alphanumeric") {
+		return "append(" + value + "[:0:0], " + value + "...)"
+	}
+	if typeNames[fieldType] != "" {
+		return ""
+	}
+
+	switch c.kind(fieldType) {
+	case schema.Enum, schema.Smol:
+		return ""
+	case schema.Union:
+		return "Clone" + pascalCase(fieldType) + "(" + value + ")"
+	default:
+		return value + ".clone()"
+	}
+}
+
+// Clone makes a deep copy, so that nothing is shared with the original. With
+// StringViews, that includes the bytes the string fields point into.
+func (c *compiler) compileClone(definition *schema.Definition) string {
+	name := pascalCase(definition.Name)
+	var lines []string
+	push := func(line ...string) { lines = append(lines, line...) }
+
+	push("// Clone returns a deep copy of i.")
+	push("func (i *" + name + ") Clone() *" + name + " {")
+	push("  if i == nil {")
+	push("    return nil")
+	push("  }")
+	push("  c := i.clone()")
+	push("  return &c")
+	push("}")
+	push("")
+
+	push("func (i *" + name + ") clone() " + name + " {")
+	push("  c := *i")
+
+	for j := range definition.Fields {
+		field := &definition.Fields[j]
+		if field.IsDeprecated {
+			continue
+		}
+
+		fieldName := pascalCase(field.Name)
+		fieldType := c.resolve(field.Type)
+
+		isPointer := c.usesPointer(definition, field)
+		value, target, indent, assign := "i."+fieldName, "c."+fieldName, "  ", "="
+		if isPointer {
+			value, target, indent, assign = "(*i."+fieldName+")", keptName(field), "    ", ":="
+			push("  if i." + fieldName + " != nil {")
+		}
+
+		if field.IsArray {
+			push(indent + target + " " + assign + " append(" + value + "[:0:0], " + value + "...)")
+			if element := c.cloneValue(fieldType, target+"[j]"); element != "" {
+				push(indent + "for j := range " + target + " {")
+				push(indent + "  " + target + "[j] = " + element)
+				push(indent + "}")
+			}
+		} else {
+			element := c.cloneValue(fieldType, value)
+			if isPointer {
+				if element == "" {
+					element = value
+				}
+				push(indent + target + " := " + element)
+			} else if element != "" {
+				push(indent + target + " = " + element)
+			}
+		}
+
+		if isPointer {
+			push(indent + "c." + fieldName + " = &" + target)
+			push("  }")
+		}
+	}
+
+	if definition.Kind == schema.Message {
+		push("  c.UnknownFields = append([]byte(nil), i.UnknownFields...)")
+	}
+	push("  return c")
+	push("}")
+
+	return strings.Join(lines, "\n")
+}
+
+// The methods of message.Message that aren't generated elsewhere. TypeName has
+// the schema's package in front so that types from different schemas can be
+// registered together.
+func (c *compiler) compileMessageMethods(definition *schema.Definition) string {
+	name := pascalCase(definition.Name)
+	typeName := definition.Name
+	if c.schema.Package != "" {
+		typeName = c.schema.Package + "." + definition.Name
+	}
+
+	return strings.Join([]string{
+		"func (*" + name + ") TypeName() string {",
+		"  return " + quote(typeName),
+		"}",
+		"",
+		"func (*" + name + ") TypeID() uint32 {",
+		"  return " + strconv.FormatUint(uint64(crc32.ChecksumIEEE([]byte(typeName))), 10),
+		"}",
+		"",
+		"func (i *" + name + ") Decode(buf buffer.Reader) error {",
+		"  return Decode" + name + "Into(buf, i)",
+		"}",
+	}, "\n")
+}
+
+// copyValue returns the statements that set target to a deep copy of value,
+// a field of fieldType, the same way clone copies it.
+func (c *compiler) copyValue(field *schema.Field, fieldType, target, assign, value, indent string) []string {
+	if field.IsArray {
+		lines := []string{indent + target + " " + assign + " append(" + value + "[:0:0], " + value + "...)"}
+		if element := c.cloneValue(fieldType, target+"[j]"); element != "" {
+			lines = append(lines,
+				indent+"for j := range "+target+" {",
+				indent+"  "+target+"[j] = "+element,
+				indent+"}",
+			)
+		}
+		return lines
+	}
+
+	element := c.cloneValue(fieldType, value)
+	if element == "" {
+		element = value
+	}
+	return []string{indent + target + " " + assign + " " + element}
+}
+
+// A pick is a struct, plus helpers to copy its fields from and back to the
+// definition it picked them from. Like Clone, they share nothing with the
+// value they copy from.
+func (c *compiler) compilePick(definition *schema.Definition) string {
+	name := pascalCase(definition.Name)
+	parent := c.definitions[definition.PickFrom]
+	parentName := pascalCase(parent.Name)
+	var lines []string
+	push := func(line ...string) { lines = append(lines, line...) }
+
+	var parentFields []*schema.Field
+	for _, field := range definition.Fields {
+		if !field.IsDeprecated {
+			parentFields = append(parentFields, parent.Field(field.Name))
+		}
+	}
+	copyField := func(field *schema.Field, target, assign, value, indent string) {
+		push(c.copyValue(field, c.resolve(field.Type), target, assign, value, indent)...)
+	}
+
+	push("// To" + name + " returns a deep copy of the fields " + name + " picks from " + parentName + ".")
+	push("func (i *" + parentName + ") To" + name + "() " + name + " {")
+	push("  result := " + name + "{}")
+	for _, field := range parentFields {
+		fieldName := pascalCase(field.Name)
+		if c.usesPointer(parent, field) {
+			push("  if i." + fieldName + " != nil {")
+			copyField(field, "result."+fieldName, "=", "(*i."+fieldName+")", "    ")
+			push("  }")
+		} else {
+			copyField(field, "result."+fieldName, "=", "i."+fieldName, "  ")
+		}
+	}
+	push("  return result")
+	push("}")
+	push("")
+
+	push("// ApplyTo deep copies the fields of " + name + " to the " + parentName + " it was picked from.")
+	push("func (i *" + name + ") ApplyTo(to *" + parentName + ") {")
+	for _, field := range parentFields {
+		fieldName := pascalCase(field.Name)
+		if c.usesPointer(parent, field) {
+			copied := snakeCase(field.Name) + "_value"
+			copyField(field, copied, ":=", "i."+fieldName, "  ")
+			push("  to." + fieldName + " = &" + copied)
+		} else {
+			copyField(field, "to."+fieldName, "=", "i."+fieldName, "  ")
+		}
+	}
+	push("}")
+
+	return strings.Join(lines, "\n")
+}
+
+// The wire layout of every definition, including deprecated fields, which
+// older senders still write.
+func (c *compiler) compileTypes() string {
+	var lines []string
+
+	lines = append(lines, "// SchemaTypes describes this schema for buffer.Buffer.SetTypes, which lets")
+	lines = append(lines, "// code generated from an older version of it skip fields it doesn't know.")
+	lines = append(lines, "var SchemaTypes = buffer.Types{")
+	for _, definition := range c.schema.Definitions {
+		kind, ok := typeKinds[definition.Kind]
+		if !ok {
+			continue
+		}
+
+		var fields []string
+		for _, field := range definition.Fields {
+			// Picks can copy deprecated fields, which are never written in
+			// structs.
+			if field.Type == "discriminator" || (definition.Kind == schema.Struct && field.IsDeprecated) {
+				continue
+			}
+			var parts []string
+			if definition.Kind != schema.Struct {
+				parts = append(parts, "Number: "+strconv.Itoa(field.Value))
+			}
+			parts = append(parts, "Type: "+quote(c.resolve(field.Type)))
+			if field.IsArray {
+				parts = append(parts, "Array: true")
+			}
+			fields = append(fields, "{"+strings.Join(parts, ", ")+"}")
+		}
+
+		if len(fields) > 0 && definition.Kind != schema.Enum && definition.Kind != schema.Smol {
+			lines = append(lines, "  "+quote(definition.Name)+": {Kind: "+kind+", Fields: []buffer.TypeField{"+strings.Join(fields, ", ")+"}},")
+		} else {
+			lines = append(lines, "  "+quote(definition.Name)+": {Kind: "+kind+"},")
+		}
+	}
+	lines = append(lines, "}")
+
+	return strings.Join(lines, "\n")
+}
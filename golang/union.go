@@ -0,0 +1,226 @@
+package golang
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jarred-sumner/peechy/schema"
+)
+
+// A union is a sealed interface that only its members implement, so decoded
+// values can be used in a type switch. On the wire it is a type byte followed
+// by the member, the same for both union styles.
+//
+// A member that is a union itself is wrapped in a struct, like AnyShape for
+// Shape in Any. A struct or message can be in more than one of those unions,
+// and the wrapper keeps which one it was decoded as, so that it is encoded
+// with the same type byte again.
+func (c *compiler) compileUnion(definition *schema.Definition) string {
+	name := pascalCase(definition.Name)
+	var fields []*schema.Field
+	for i := range definition.Fields {
+		if definition.Fields[i].Type != "discriminator" {
+			fields = append(fields, &definition.Fields[i])
+		}
+	}
+	var lines []string
+	push := func(line ...string) { lines = append(lines, line...) }
+	isUnion := func(field *schema.Field) bool {
+		return c.kind(field.Type) == schema.Union
+	}
+	// goName is the Go type of a member, which the interface holds a pointer to.
+	goName := func(field *schema.Field) string {
+		if isUnion(field) {
+			return name + pascalCase(field.Name)
+		}
+		return pascalCase(field.Name)
+	}
+
+	for _, field := range fields {
+		if !isUnion(field) {
+			continue
+		}
+		wrapper := goName(field)
+		for _, other := range c.schema.Definitions {
+			if pascalCase(other.Name) == wrapper {
+				fail(
+					"The member "+quote(field.Name)+" of "+quote(definition.Name)+
+						" needs the Go type "+quote(wrapper)+", which is already used by "+quote(other.Name),
+					field.Line,
+					field.Column,
+				)
+			}
+		}
+	}
+
+	var names []string
+	for _, field := range fields {
+		names = append(names, "*"+goName(field))
+	}
+	list := strings.Join(names, ", ")
+	if last := strings.LastIndex(list, ", "); last >= 0 {
+		list = list[:last] + " or " + list[last+2:]
+	}
+	push("// " + name + " is one of " + list + ".")
+	if c.isDiscriminatedUnion(definition.Name) {
+		push("// The " + quote(definition.Fields[0].Name) + " discriminator JavaScript sets is the concrete type here.")
+	}
+	push("type " + name + " interface {")
+	push("  is" + name + "()")
+	push("}")
+	push("")
+
+	push("type " + name + "Type byte")
+	push("")
+	push("const (")
+	for _, field := range fields {
+		push("  " + name + "Type" + pascalCase(field.Name) + " " + name + "Type = " + strconv.Itoa(field.Value))
+	}
+	push(")")
+	push("")
+
+	for _, field := range fields {
+		if !isUnion(field) {
+			continue
+		}
+		member := pascalCase(field.Name)
+		push("// " + goName(field) + " holds the " + member + " member of " + name + ".")
+		push("type " + goName(field) + " struct {")
+		push("  Value " + member + " `json:\"value\"`")
+		push("}")
+		push("")
+	}
+
+	for _, field := range fields {
+		push("func (*" + goName(field) + ") is" + name + "() {}")
+	}
+	push("")
+
+	push("func Encode" + name + "(buf buffer.Writer, value " + name + ") error {")
+	push("  buf.Grow(EncodedSize" + name + "(buf.WireFormat(), value))")
+	push("  return encode" + name + "(buf, value)")
+	push("}")
+	push("")
+
+	// Nil pointers to a member are as invalid as a nil interface.
+	push("func encode" + name + "(buf buffer.Writer, value " + name + ") error {")
+	push("  switch value := value.(type) {")
+	for _, field := range fields {
+		if field.IsDeprecated {
+			continue
+		}
+		member := pascalCase(field.Name)
+		push("  case *" + goName(field) + ":")
+		push("    if value != nil {")
+		push("      buf.WriteByte(byte(" + name + "Type" + member + "))")
+		if isUnion(field) {
+			push("      return encode" + member + "(buf, value.Value)")
+		} else {
+			push("      return value.encode(buf)")
+		}
+		push("    }")
+	}
+	push("  }")
+	push(`  return errors.New("attempted to encode invalid union");`)
+	push("}")
+	push("")
+
+	push("// EncodedSize" + name + " returns how many bytes Encode" + name + " writes with format.")
+	push("func EncodedSize" + name + "(format buffer.WireFormat, value " + name + ") int {")
+	push("  switch value := value.(type) {")
+	for _, field := range fields {
+		if field.IsDeprecated {
+			continue
+		}
+		member := pascalCase(field.Name)
+		push("  case *" + goName(field) + ":")
+		push("    if value != nil {")
+		if isUnion(field) {
+			push("      return 1 + EncodedSize" + member + "(format, value.Value)")
+		} else {
+			push("      return 1 + value.EncodedSizeIn(format)")
+		}
+		push("    }")
+	}
+	push("  }")
+	push("  return 0")
+	push("}")
+	push("")
+
+	push("// Equal" + name + " reports whether a and b are the same member with the same fields.")
+	push("func Equal" + name + "(a, b " + name + ") bool {")
+	push("  if a == nil || b == nil {")
+	push("    return a == b")
+	push("  }")
+	push("  switch a := a.(type) {")
+	for _, field := range fields {
+		if field.IsDeprecated {
+			continue
+		}
+		member := pascalCase(field.Name)
+		push("  case *" + goName(field) + ":")
+		push("    b, ok := b.(*" + goName(field) + ")")
+		if isUnion(field) {
+			push("    if !ok || a == nil || b == nil {")
+			push("      return ok && a == b")
+			push("    }")
+			push("    return Equal" + member + "(a.Value, b.Value)")
+		} else {
+			push("    return ok && a.Equal(b)")
+		}
+	}
+	push("  default:")
+	push("    return false")
+	push("  }")
+	push("}")
+	push("")
+
+	push("// Clone" + name + " returns a deep copy of value.")
+	push("func Clone" + name + "(value " + name + ") " + name + " {")
+	push("  switch value := value.(type) {")
+	for _, field := range fields {
+		if field.IsDeprecated {
+			continue
+		}
+		member := pascalCase(field.Name)
+		push("  case *" + goName(field) + ":")
+		if isUnion(field) {
+			push("    if value == nil {")
+			push("      return value")
+			push("    }")
+			push("    return &" + goName(field) + "{Value: Clone" + member + "(value.Value)}")
+		} else {
+			push("    return value.Clone()")
+		}
+	}
+	push("  default:")
+	push("    return value")
+	push("  }")
+	push("}")
+	push("")
+
+	push("func Decode" + name + "(buf buffer.Reader) (" + name + ", error) {")
+	push("  switch " + name + "Type(buf.ReadUint8()) {")
+	for _, field := range fields {
+		member := pascalCase(field.Name)
+		push("  case " + name + "Type" + member + ":")
+		push("    value, err := Decode" + member + "(buf)")
+		if isUnion(field) {
+			push("    if value == nil {")
+			push("      return nil, err")
+			push("    }")
+			push("    return &" + goName(field) + "{Value: value}, err")
+		} else {
+			push("    return &value, err")
+		}
+	}
+	push("  default:")
+	push("    if err := buf.Err(); err != nil {")
+	push("      return nil, err")
+	push("    }")
+	push(`    return nil, errors.New("attempted to parse invalid union");`)
+	push("  }")
+	push("}")
+
+	return strings.Join(lines, "\n")
+}
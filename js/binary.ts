@@ -15,6 +15,14 @@ let types: (string | null)[] = [
   "float32",
   "string",
   "uint",
+  "int64",
+  "uint64",
+  "float64",
+  "varint64",
+  "varuint64",
+  "lowp",
+  "alphanumeric",
+  "discriminator",
 ];
 let kinds: DefinitionKind[] = [
   "ENUM",
@@ -25,6 +25,12 @@ let usage = [
   "  --schema [PATH]       The schema file to use.",
   "  --js [PATH]           Generate JavaScript code.",
   "  --go [PATH]           Generate Go code.",
+  "  --go-strings [TYPE]   Decode Go strings as \"string\" (default) or as \"bytes\",",
+  "                        []byte slices of the buffer that aren't copied.",
+  "  --go-aliases [KIND]   Generate Go aliases as \"named\" types (default) or as",
+  "                        type \"alias\" declarations.",
+  "  --go-enums [POLICY]   Keep enum values that aren't in the schema when decoding",
+  "                        Go (\"preserve\", default) or fail (\"strict\").",
   "  --zig [PATH]          Generate Zig code.",
   "  --esm [PATH]          Generate JavaScript code as a ECMAScript module.",
   "  --js-allocator [PATH] Allow passing an allocator to import in the code.",
@@ -67,6 +73,9 @@ export function main(args: string[]): number {
     "--schema": null,
     "--js": null,
     "--go": null,
+    "--go-strings": null,
+    "--go-aliases": null,
+    "--go-enums": null,
     "--esm": null,
     "--ts": null,
     "--zig": null,
@@ -174,8 +183,31 @@ export function main(args: string[]): number {
     writeFileString(flags["--ts"], compileSchemaTypeScript(parsed));
   }
 
+  let goStrings = flags["--go-strings"];
+  if (goStrings !== null && goStrings !== "string" && goStrings !== "bytes") {
+    throw new Error("Invalid --go-strings: " + JSON.stringify(goStrings));
+  }
+
+  let goAliases = flags["--go-aliases"];
+  if (goAliases !== null && goAliases !== "named" && goAliases !== "alias") {
+    throw new Error("Invalid --go-aliases: " + JSON.stringify(goAliases));
+  }
+
+  let goEnums = flags["--go-enums"];
+  if (goEnums !== null && goEnums !== "preserve" && goEnums !== "strict") {
+    throw new Error("Invalid --go-enums: " + JSON.stringify(goEnums));
+  }
+
   if (flags["--go"] !== null) {
-    writeFileString(flags["--go"], compileSchemaGo(parsed));
+    writeFileString(
+      flags["--go"],
+      compileSchemaGo(
+        parsed,
+        goStrings === "bytes",
+        goAliases === "alias",
+        goEnums === "strict"
+      )
+    );
   }
 
   if (flags["--zig"] !== null) {
@@ -1,9 +1,33 @@
-//@ts-ignore
-import { camelCase, pascalCase, snakeCase } from "change-case";
+import {
+  camelCase,
+  pascalCase as changeCasePascalCase,
+  pascalCaseTransform,
+  snakeCase,
+  //@ts-ignore
+} from "change-case";
 import { parseSchema } from "./parser";
-import { Definition, Schema } from "./schema";
+import { Definition, Field, Schema } from "./schema";
 import { error, quote } from "./util";
 
+// The words Go spells in one case, like the ID in message.TypeID. Names are
+// pascal cased with these upper case, so "userId" becomes UserID, but
+// camelCase leaves them alone to match JavaScript.
+const INITIALISMS = new Set(
+  (
+    "ACL API ASCII CPU CSS DNS EOF GUID HTML HTTP HTTPS ID IP JSON QPS RAM " +
+    "RPC SQL SSH TCP TLS TTL UDP UI UID UUID URI URL UTF8 VM XML"
+  ).split(" ")
+);
+
+function pascalCase(text: string): string {
+  return changeCasePascalCase(text, {
+    transform: (word: string, index: number) =>
+      INITIALISMS.has(word.toUpperCase())
+        ? word.toUpperCase()
+        : pascalCaseTransform(word, index),
+  });
+}
+
 const TYPE_NAMES = {
   bool: "bool",
   byte: "byte",
@@ -20,6 +44,26 @@ const TYPE_NAMES = {
   string: "string",
   uint: "uint",
   alphanumeric: "string",
+  int64: "int64",
+  uint64: "uint64",
+  float64: "float64",
+  varint64: "int64",
+  varuint64: "uint64",
+};
+
+// Arrays of these types are read and written in one call, with the buffer
+// methods named here.
+const TYPED_ARRAYS = {
+  byte: "ByteArray",
+  int8: "Int8Array",
+  int16: "Int16Array",
+  uint16: "UInt16Array",
+  int32: "Int32Array",
+  uint32: "UInt32Array",
+  float32: "Float32Array",
+  int64: "Int64Array",
+  uint64: "UInt64Array",
+  float64: "Float64Array",
 };
 
 function isDiscriminatedUnion(
@@ -31,435 +75,583 @@ function isDiscriminatedUnion(
   return definitions[name].fields[0].type === "discriminator";
 }
 
+// A struct whose fields are all empty structs encodes to zero bytes, so an
+// array of them can be longer than the bytes left in the buffer.
+function canBeEmpty(
+  name: string,
+  definitions: { [name: string]: Definition }
+): boolean {
+  const definition = definitions[name];
+  return (
+    !!definition &&
+    definition.kind === "STRUCT" &&
+    definition.fields.every(
+      (field) => !field.isArray && canBeEmpty(field.type, definitions)
+    )
+  );
+}
+
 type AliasMap = { [name: string]: string };
 
-function compileDecode(
+// String, text and JSON methods for an enum.
+function compileEnumMethods(
   definition: Definition,
-  definitions: { [name: string]: Definition },
-  aliases: AliasMap
+  strictEnums: boolean
 ): string {
-  let lines: string[] = [];
-  let indent = "  ";
-
-  // if (definition.kind === "UNION") {
-  //   const hasDiscriminator = isDiscriminatedUnion(definition.name, definitions);
-  //   lines.push("");
-
-  //   if (hasDiscriminator) {
-  //     lines.push("  switch (buf.ReadVarUint()) {");
-  //     indent = "      ";
-  //     for (let i = 1; i < definition.fields.length; i++) {
-  //       let field = definition.fields[i];
-  //       lines.push(
-  //         `    case ${field.value}:`,
-  //         indent + `var result = " + ("decode" + field.name) + "(bb);`,
-  //         indent +
-  //           `result[${quote(definition.fields[0].name)}] = ${field.value};`,
-  //         indent + `return result;`
-  //       );
-  //     }
-  //   } else {
-  //     lines.push("  switch (type) {");
-  //     indent = "      ";
-  //     for (let i = 0; i < definition.fields.length; i++) {
-  //       let field = definition.fields[i];
-  //       lines.push(
-  //         `    case ${field.value}:`,
-  //         indent + `return ${"decode" + field.name}(bb)`
-  //       );
-  //     }
-  //   }
-  // } else {
-  lines.push(
-    `func Decode${pascalCase(
-      definition.name
-    )}(buf *buffer.Buffer) (${pascalCase(definition.name)}, error) {`
+  const name = pascalCase(definition.name);
+  const constants = definition.fields.map(
+    (field) => `${name}${pascalCase(field.name)}`
   );
+  const underlying = definition.kind === "SMOL" ? "byte" : "uint";
+  const bits = definition.kind === "SMOL" ? "8" : "0";
+  const read =
+    definition.kind === "SMOL" ? "buf.ReadUint8()" : "buf.ReadVarUint()";
+  const quoted = quote(definition.name);
+
+  // Values that aren't one of the constants, which DecodeX keeps unless
+  // strictEnums is set, are written to text and JSON as numbers so that they
+  // aren't lost. With strictEnums, the text and JSON methods reject them the
+  // same as DecodeX.
+  const unknown = strictEnums
+    ? `return nil, &buffer.InvalidEnumError{Enum: ${quoted}, Value: uint(s)}`
+    : "return []byte(strconv.FormatUint(uint64(s), 10)), nil";
+  const unknownDoc = strictEnums
+    ? "fails if it isn't one of the constants"
+    : "as a number if it isn't one of the constants";
+  const numbers = strictEnums ? "the number of one of them" : "a number";
+  const check = strictEnums
+    ? `  if !${name}(value).IsValid() {
+    return &buffer.InvalidEnumError{Enum: ${quoted}, Value: uint(value)}
+  }
+`
+    : "";
 
-  let hasLength = false;
+  return `
+// ${name}Values returns every ${name} in the order of the schema.
+func ${name}Values() []${name} {
+  return []${name}{${constants.join(", ")}}
+}
 
-  // if (!withAllocator) {
-  lines.push(`   result := ${pascalCase(definition.name)}{}`);
-  let startLine = lines.length;
-  // } else {
-  //   lines.push(
-  //     "  var result = Allocator[" + quote(definition.name) + "].alloc();"
-  //   );
-  // }
+// IsValid reports whether s is one of the ${name} constants.
+func (s ${name}) IsValid() bool {
+  _, ok := ${name}ToString[s]
+  return ok
+}
 
-  lines.push("");
-  var hasErr = false;
+// String returns the name s has in the schema.
+func (s ${name}) String() string {
+  if name, ok := ${name}ToString[s]; ok {
+    return name
+  }
+  return "${name}(" + strconv.FormatUint(uint64(s), 10) + ")"
+}
 
-  if (definition.kind === "MESSAGE") {
-    lines.push(`var fieldType uint;`);
+// MarshalText implements encoding.TextMarshaler. It writes what MarshalJSON
+// does without the quotes, so that maps keyed by ${name} marshal too.
+func (s ${name}) MarshalText() ([]byte, error) {
+  if name, ok := ${name}ToString[s]; ok {
+    return []byte(name), nil
+  }
+  ${unknown}
+}
 
-    lines.push("  for {");
-    lines.push("    switch fieldType = buf.ReadVarUint(); fieldType {");
-    lines.push("    case 0:");
-    lines.push("      return result, nil;");
-    lines.push("");
-    indent = "      ";
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the name of
+// one of the values in the schema, or ${numbers}.
+func (s *${name}) UnmarshalText(text []byte) error {
+  if value, ok := ${name}ToID[string(text)]; ok {
+    *s = value
+    return nil
+  }
+  value, err := strconv.ParseUint(string(text), 10, ${bits})
+  if err != nil {
+    return &buffer.InvalidEnumError{Enum: ${quoted}, Name: string(text)}
   }
+${check}  *s = ${name}(value)
+  return nil
+}
 
-  for (let i = 0; i < definition.fields.length; i++) {
-    let field = definition.fields[i];
-    let code: string;
-    let fieldType = field.type;
-    if (aliases[fieldType]) fieldType = aliases[fieldType];
+// MarshalJSON marshals the enum as its name in the schema, the same as
+// JavaScript, or ${unknownDoc}.
+func (s ${name}) MarshalJSON() ([]byte, error) {
+  if name, ok := ${name}ToString[s]; ok {
+    return []byte(\`"\` + name + \`"\`), nil
+  }
+  ${unknown}
+}
 
-    const isPrimitiveType =
-      TYPE_NAMES[fieldType] ||
-      ["SMOL", "ENUM"].includes(definitions[fieldType].kind);
+// UnmarshalJSON unmarshals a quoted json string, which has to be the name of
+// one of the values in the schema, or ${numbers}.
+func (s *${name}) UnmarshalJSON(b []byte) error {
+  if string(b) == "null" {
+    return nil
+  }
 
-    switch (fieldType) {
-      case "bool": {
-        code = "buf.ReadBool()";
-        break;
-      }
+  var j string
+  if err := json.Unmarshal(b, &j); err == nil {
+    return s.UnmarshalText([]byte(j))
+  }
 
-      case "uint8":
-      case "byte": {
-        code = "buf.ReadByte()"; // only used if not array
-        break;
-      }
+  var value ${underlying}
+  if err := json.Unmarshal(b, &value); err != nil {
+    return err
+  }
+${check}  *s = ${name}(value)
+  return nil
+}
+${
+  strictEnums
+    ? `
+// Decode${name} reads a ${name}, and fails for values that aren't one of the
+// constants.
+func Decode${name}(buf buffer.Reader) (${name}, error) {
+  value := ${name}(${read})
+  if err := buf.Err(); err != nil {
+    return value, err
+  }
+  if !value.IsValid() {
+    return value, &buffer.InvalidEnumError{Enum: ${quoted}, Value: uint(value)}
+  }
+  return value, nil
+}
+`
+    : ""
+}`;
+}
 
-      case "int16": {
-        code = "buf.ReadInt16()";
-        break;
-      }
+// Returns the check a message makes for a field marked with [!] in the
+// schema, which is a pointer or a union that's nil when it's missing.
+// DecodeXInto and Encode both return it as their error.
+function missingField(
+  definition: Definition,
+  field: Field,
+  value: string
+): string {
+  return [
+    `  if ${value}.${pascalCase(field.name)} == nil {`,
+    `    return &buffer.MissingFieldError{Message: ${quote(
+      definition.name
+    )}, Field: ${quote(field.name)}}`,
+    `  }`,
+  ].join("\n");
+}
 
-      case "alphanumeric": {
-        code = "buf.ReadAlphanumeric()";
-        break;
-      }
+// Returns the Go type of a single value of a field, which is the alias
+// itself for fields that use one.
+function goElementType(type: string, stringViews: boolean): string {
+  return goTypeName(type, stringViews) || pascalCase(type);
+}
 
-      case "int8": {
-        code = "buf.ReadInt8()";
-        break;
-      }
+// With stringViews, string fields are []byte slices of the buffer being
+// decoded instead of copies. They are only valid as long as the buffer is.
+function goTypeName(type: string, stringViews: boolean): string {
+  if (stringViews && (type === "string" || type === "alphanumeric")) {
+    return "[]byte";
+  }
 
-      case "int32": {
-        code = "buf.ReadInt32()";
-        break;
-      }
+  return TYPE_NAMES[type];
+}
 
-      case "int": {
-        code = "buf.ReadVarInt()";
-        break;
-      }
+// readValue returns the Go expression that reads one value of fieldType, or
+// the call to its DecodeX function for types that can fail to decode.
+function readValue(
+  field: Field,
+  fieldType: string,
+  definitions: { [name: string]: Definition },
+  stringViews: boolean,
+  strictEnums: boolean
+): string {
+  let code: string;
 
-      case "uint16": {
-        code = "buf.ReadUint16()";
-        break;
-      }
+  switch (fieldType) {
+    case "bool": {
+      code = "buf.ReadBool()";
+      break;
+    }
 
-      case "uint32": {
-        code = "buf.ReadUint32()";
-        break;
-      }
+    case "uint8":
+    case "byte": {
+      code = "buf.ReadUint8()"; // only used if not array
+      break;
+    }
 
-      case "lowp": {
-        code = "buf.ReadLowPrecisionFloat()";
-        break;
-      }
+    case "int16": {
+      code = "buf.ReadInt16()";
+      break;
+    }
 
-      case "uint": {
-        code = "buf.ReadVarUint()";
-        break;
-      }
+    case "alphanumeric": {
+      code = stringViews
+        ? "buf.ReadAlphanumericBytes()"
+        : "buf.ReadAlphanumeric()";
+      break;
+    }
 
-      case "float": {
-        code = "buf.ReadVarFloat()";
-        break;
-      }
+    case "int8": {
+      code = "buf.ReadInt8()";
+      break;
+    }
 
-      case "float32": {
-        code = "buf.ReadFloat32()";
-        break;
-      }
+    case "int32": {
+      code = "buf.ReadInt32()";
+      break;
+    }
 
-      case "string": {
-        code = "buf.ReadString()";
-        break;
-      }
+    case "int": {
+      code = "buf.ReadVarInt()";
+      break;
+    }
 
-      default: {
-        let type = definitions[fieldType!];
-        if (!type) {
-          error(
-            "Invalid type " +
-              quote(fieldType!) +
-              " for field " +
-              quote(field.name),
-            field.line,
-            field.column
-          );
-        } else if (type.kind === "ENUM") {
-          code = pascalCase(type.name) + "(buf.ReadVarUint())";
-        } else if (type.kind === "SMOL") {
-          code = pascalCase(type.name) + "(buf.ReadByte())";
-        } else {
-          code = "Decode" + pascalCase(type.name) + "(buf)";
-        }
-      }
+    case "uint16": {
+      code = "buf.ReadUint16()";
+      break;
     }
 
-    if (definition.kind === "MESSAGE") {
-      lines.push("    case " + field.value + ":");
+    case "uint32": {
+      code = "buf.ReadUint32()";
+      break;
     }
 
-    if (field.isArray) {
-      if (field.isDeprecated) {
-        if (fieldType === "byte") {
-          lines.push(indent + `buf.ReadByteArray();`);
-        } else {
-          lines.push(indent + `var length = buf.ReadVarUint();`);
-          lines.push(indent + `for (length > 0) { ${code}; length++; }`);
-        }
+    case "lowp": {
+      code = "buf.ReadLowpFloat()";
+      break;
+    }
+
+    case "uint": {
+      code = "buf.ReadVarUint()";
+      break;
+    }
+
+    case "float": {
+      code = "buf.ReadVarFloat()";
+      break;
+    }
+
+    case "float32": {
+      code = "buf.ReadFloat32()";
+      break;
+    }
+
+    case "string": {
+      code = stringViews ? "buf.ReadStringBytes()" : "buf.ReadString()";
+      break;
+    }
+
+    case "int64": {
+      code = "buf.ReadInt64()";
+      break;
+    }
+
+    case "uint64": {
+      code = "buf.ReadUint64()";
+      break;
+    }
+
+    case "float64": {
+      code = "buf.ReadFloat64()";
+      break;
+    }
+
+    case "varint64": {
+      code = "buf.ReadVarInt64()";
+      break;
+    }
+
+    case "varuint64": {
+      code = "buf.ReadVarUint64()";
+      break;
+    }
+
+    default: {
+      let type = definitions[fieldType!];
+      if (!type) {
+        error(
+          "Invalid type " +
+            quote(fieldType!) +
+            " for field " +
+            quote(field.name),
+          field.line,
+          field.column
+        );
+      } else if (type.kind === "ENUM" && !strictEnums) {
+        code = pascalCase(type.name) + "(buf.ReadVarUint())";
+      } else if (type.kind === "SMOL" && !strictEnums) {
+        code = pascalCase(type.name) + "(buf.ReadUint8())";
       } else {
-        switch (fieldType) {
-          case "byte": {
-            // if (!hasErr) {
-            //   lines.splice(startLine, 1, lines[startLine], "var err error;");
-            //   hasErr = true;
-            // }
-
-            lines.push(
-              indent +
-                ` result.${pascalCase(field.name)} = buf.ReadByteArray();`
-              // indent + `  if err != nil {`,
-              // indent + `    return result, err`,
-              // indent + `  }`
-            );
-            break;
-          }
-          case "uint16": {
-            lines.push(
-              indent +
-                `result.${pascalCase(field.name)} = buf.ReadUint16Array();`
-            );
-            break;
-          }
-          case "uint32": {
-            lines.push(
-              indent +
-                `result.${pascalCase(field.name)} = buf.ReadUint32Array();`
-            );
-            break;
-          }
-          case "int8": {
-            lines.push(
-              indent + `result.${pascalCase(field.name)} = buf.ReadInt8Array();`
-            );
-            break;
-          }
-          case "int16": {
-            lines.push(
-              indent +
-                `result.${pascalCase(field.name)} = buf.ReadInt16Array();`
-            );
-            break;
-          }
-          case "int32": {
-            lines.push(
-              indent +
-                `result.${pascalCase(field.name)} = buf.ReadInt32Array();`
-            );
-            break;
-          }
-          case "float32": {
-            lines.push(
-              indent +
-                `result.${pascalCase(field.name)} = buf.ReadFloat32Array();`
-            );
-            break;
-          }
-          default: {
-            if (!hasLength) {
-              lines.splice(
-                startLine,
-                1,
-                lines[startLine],
-                indent + `var length uint;`
-              );
-              hasLength = true;
-            }
-            lines.push(indent + `length = buf.ReadVarUint();`);
-
-            let arrayName = "";
-            if (definition.kind === "MESSAGE") {
-              arrayName = `${pascalCase(field.name)}_a_${i}`;
-              lines.push(
-                indent +
-                  `${arrayName} := make([]${
-                    TYPE_NAMES[fieldType]
-                      ? TYPE_NAMES[fieldType]
-                      : pascalCase(fieldType)
-                  }, length)`,
-                indent + `result.${pascalCase(field.name)} = &${arrayName}`
-              );
-            } else {
-              arrayName = `result.${pascalCase(field.name)}`;
-              lines.push(
-                indent +
-                  `${arrayName} = make([]${
-                    TYPE_NAMES[fieldType]
-                      ? TYPE_NAMES[fieldType]
-                      : pascalCase(fieldType)
-                  }, length)`
-              );
-            }
-
-            if (isPrimitiveType) {
-              if (definition.kind === "MESSAGE") {
-                lines.push(
-                  indent +
-                    `var ${snakeCase(field.name)}_${i} ${
-                      TYPE_NAMES[field.type]
-                    };`
-                );
-
-                lines.push(indent + `for j := uint(0); j < length; j++ {`);
-                lines.push(
-                  indent + `   ${snakeCase(field.name)}_${i} = ${code}`
-                );
-
-                lines.push(
-                  `     ${arrayName}[j] = ${snakeCase(field.name)}_${i}`
-                );
-                lines.push(indent + "}");
-              } else {
-                lines.push(
-                  indent +
-                    `for j := uint(0); j < length; j++ { ${arrayName}[j] = ` +
-                    code +
-                    "; }"
-                );
-              }
-            } else {
-              if (definition.kind === "MESSAGE") {
-                lines.push(indent + `var err error;`);
-
-                lines.push(
-                  // indent + `var val *${field.type};`,
-                  indent + `for j := uint(0); j < length; j++ {\n`,
-                  indent + ` ${arrayName}[j], err = ${code}`,
-                  indent + `if (err != nil) {`,
-                  indent + `return result, err;`,
-                  indent + `}`,
-                  // `          = val`,
-                  indent + `}`
-                );
-              } else {
-                if (!hasErr) {
-                  lines.splice(
-                    startLine,
-                    1,
-                    lines[startLine],
-                    indent + `var err error;`
-                  );
-                  hasErr = true;
-                }
-
-                lines.push(
-                  indent +
-                    `for j := uint(0); j < length; j++ {\n ${arrayName}[j], err = ` +
-                    code +
-                    ";\n if (err != nil) {\n return result, err;\n}\n}"
-                );
-              }
-            }
-
-            break;
-          }
-        }
+        code = "Decode" + pascalCase(type.name) + "(buf)";
       }
-    } else if (fieldType && isDiscriminatedUnion(fieldType, definitions)) {
-      // lines.push(
-      //   indent +
-      //     result.${pascalCase(field.name)} =
-      //     "] = " +
-      //     `${"decode" + fieldType}(bb);`
-      // );
-    } else if (
-      fieldType &&
-      definitions[fieldType] &&
-      definitions[fieldType].kind === "UNION"
+    }
+  }
+
+
+  return code;
+}
+
+// DecodeXInto decodes into an existing value, so that decoding many values in
+// a row can reuse the slices and pointers of the previous one. DecodeX is
+// DecodeXInto on a new value.
+function compileDecode(
+  definition: Definition,
+  definitions: { [name: string]: Definition },
+  aliases: AliasMap,
+  namedTypes: AliasMap,
+  stringViews: boolean,
+  strictEnums: boolean
+): string {
+  const name = pascalCase(definition.name);
+  const isMessage = definition.kind === "MESSAGE";
+  let lines: string[] = [];
+  let indent = isMessage ? "      " : "  ";
+
+  lines.push(`func Decode${name}(buf buffer.Reader) (${name}, error) {`);
+  lines.push(`  result := ${name}{}`);
+  lines.push(`  err := Decode${name}Into(buf, &result)`);
+  lines.push("  return result, err");
+  lines.push("}");
+  lines.push("");
+
+  if (isMessage) {
+    lines.push(
+      `// Decode${name}Into decodes into result, reusing what its fields point to.`
+    );
+  } else {
+    lines.push(
+      `// Decode${name}Into decodes into result, reusing the room in its slices.`
+    );
+  }
+  lines.push(
+    `func Decode${name}Into(buf buffer.Reader, result *${name}) error {`
+  );
+
+  const startLine = lines.length;
+  let hasLength = false;
+  let hasErr = false;
+  const declare = (line: string) => {
+    lines.splice(startLine, 0, "  " + line);
+  };
+  const needLength = () => {
+    if (!hasLength) declare("var length uint");
+    hasLength = true;
+  };
+  const needErr = () => {
+    if (!hasErr) declare("var err error");
+    hasErr = true;
+  };
+
+  if (isMessage) {
+    // Fields that aren't in this message have to end up nil, so the old
+    // pointers are kept aside and only put back for fields that are read.
+    const kept = definition.fields.filter(
+      (field) =>
+        !field.isDeprecated && usesPointer(definition, field, definitions)
+    );
+    if (kept.length) {
+      lines.push(
+        `  ${kept
+          .map((field) => snakeCase(field.name) + "_" + field.value)
+          .join(", ")} := ${kept
+          .map((field) => "result." + pascalCase(field.name))
+          .join(", ")}`
+      );
+    }
+    lines.push(
+      `  *result = ${name}{UnknownFields: result.UnknownFields[:0]}`
+    );
+    lines.push("");
+    lines.push("  for {");
+    lines.push("    switch fieldType := buf.ReadUint8(); fieldType {");
+    lines.push("    case 0:");
+    const required = definition.fields.filter(
+      (field) => field.isRequired && !field.isDeprecated
+    );
+    if (required.length) {
+      // A read past the end also returns 0, so check for that first.
+      lines.push("      if err := buf.Err(); err != nil {");
+      lines.push("        return err");
+      lines.push("      }");
+      lines.push(
+        ...required.map((field) => missingField(definition, field, "result"))
+      );
+    }
+    lines.push("      return buf.Err()");
+    lines.push("");
+  }
+
+  for (let field of definition.fields) {
+    let fieldType = field.type;
+    if (aliases[fieldType]) fieldType = aliases[fieldType];
+
+    // Only messages can have deprecated fields, but a pick of one can copy
+    // them. Encode never writes them, so there's nothing to skip.
+    if (field.isDeprecated && !isMessage) continue;
+
+    // With strictEnums, enums are decoded with a DecodeX function that can
+    // fail, the same as structs.
+    const type = definitions[fieldType];
+    const isPrimitiveType =
+      TYPE_NAMES[fieldType] ||
+      (!strictEnums && ["SMOL", "ENUM"].includes(type.kind));
+    // Structs and messages are decoded into the value that's already there.
+    const decodesInto =
+      !isPrimitiveType && ["STRUCT", "MESSAGE"].includes(type.kind);
+
+    let code = readValue(field, fieldType, definitions, stringViews, strictEnums);
+
+    // Typed arrays of a named type are converted as a whole below.
+    if (
+      namedTypes[field.type] &&
+      !field.isDeprecated &&
+      !(field.isArray && TYPED_ARRAYS[fieldType])
     ) {
-      // const key = quote(field.name + "Type");
-      // lines.push(
-      //   indent + `result[" + key + "] = " + "buf.ReadVarUint()" + ";`,
-      //   indent +
-      //     result.${pascalCase(field.name)} =
-      //     "] = " +
-      //     `${"decode" + fieldType}(bb, result[${key}]);`
-      // );
-    } else if (isPrimitiveType) {
-      if (field.isDeprecated) {
-        lines.push(indent + code + ";");
-      } else if (definition.kind === "MESSAGE") {
-        lines.push(indent + `${snakeCase(field.name)}_${i} := ${code}`);
+      code = `${pascalCase(field.type)}(${code})`;
+    }
+
+    if (isMessage) {
+      lines.push("    case " + field.value + ":");
+    }
+
+    if (field.isDeprecated) {
+      if (field.isArray && TYPED_ARRAYS[fieldType]) {
+        // Typed arrays are prefixed with their length in bytes, so they can
+        // all be skipped like a byte array.
+        lines.push(indent + "buf.ReadByteArrayView()");
+        continue;
+      }
+
+      let loop = "";
+      if (field.isArray) {
+        needLength();
         lines.push(
           indent +
-            `result.${pascalCase(field.name)} = &${snakeCase(field.name)}_${i}`
+            (canBeEmpty(fieldType, definitions)
+              ? "length = buf.ReadVarUint()"
+              : "length = buf.ReadArrayLength()")
         );
+        lines.push(indent + "for j := uint(0); j < length; j++ {");
+        loop = "  ";
+      }
+      if (isPrimitiveType) {
+        lines.push(indent + loop + `_ = ${code}`);
       } else {
-        lines.push(indent + `result.${pascalCase(field.name)} = ${code}`);
+        lines.push(indent + loop + `if _, err := ${code}; err != nil {`);
+        lines.push(indent + loop + "  return err");
+        lines.push(indent + loop + "}");
       }
-    } else {
-      // if (!hasErr) {
-      //   lines.splice(startLine, 1, lines[startLine], "var err error;");
-      //   hasErr = true;
-      // }
-      if (field.isDeprecated) {
-        lines.push(indent + code + ";");
-      } else if (definition.kind === "MESSAGE") {
+      if (field.isArray) {
+        lines.push(indent + "}");
+      }
+      continue;
+    }
+
+    // target is where the field is decoded to. Message fields are pointers,
+    // which are reused if the old value had one.
+    let target = `result.${pascalCase(field.name)}`;
+    let address = `&${target}`;
+    const isPointer = usesPointer(definition, field, definitions);
+    if (isPointer) {
+      const kept = snakeCase(field.name) + "_" + field.value;
+      const goType =
+        (field.isArray ? "[]" : "") + goElementType(field.type, stringViews);
+      lines.push(indent + `if ${kept} == nil {`);
+      lines.push(indent + `  ${kept} = new(${goType})`);
+      lines.push(indent + "}");
+      lines.push(indent + `${target} = ${kept}`);
+      target = `(*${kept})`;
+      address = kept;
+    }
+
+    if (field.isArray && TYPED_ARRAYS[fieldType]) {
+      // Arrays of a named type are still read in one go, as a slice of the
+      // type it names, which has the same layout.
+      if (namedTypes[field.type]) {
+        target = `*(*[]${goTypeName(
+          fieldType,
+          stringViews
+        )})(unsafe.Pointer(${address}))`;
+      }
+      if (stringViews && fieldType === "byte") {
+        lines.push(indent + `${target} = buf.ReadByteArrayView()`);
+      } else {
         lines.push(
           indent +
-            `var ${snakeCase(field.name)}_${i} ${
-              TYPE_NAMES[fieldType] || definitions[fieldType].name
-            };`
+            `${target} = buf.Read${TYPED_ARRAYS[fieldType]}Into(${target})`
         );
-        lines.push(indent + `${snakeCase(field.name)}_${i}, err = ${code}`);
+      }
+    } else if (field.isArray) {
+      needLength();
+      lines.push(
+        indent +
+          (canBeEmpty(fieldType, definitions)
+            ? "length = buf.ReadVarUint()"
+            : "length = buf.ReadArrayLength()")
+      );
+      lines.push(indent + `if ${target} == nil || uint(cap(${target})) < length {`);
+      lines.push(
+        indent +
+          `  ${target} = make([]${goElementType(
+            field.type,
+            stringViews
+          )}, length)`
+      );
+      lines.push(indent + "} else {");
+      lines.push(indent + `  ${target} = ${target}[:length]`);
+      lines.push(indent + "}");
+      lines.push(indent + `for j := range ${target} {`);
+      if (isPrimitiveType) {
+        lines.push(indent + `  ${target}[j] = ${code}`);
+      } else if (decodesInto) {
+        needErr();
         lines.push(
           indent +
-            `result.${pascalCase(field.name)} = &${snakeCase(field.name)}_${i}`
+            `  if err = Decode${pascalCase(type.name)}Into(buf, &${target}[j]); err != nil {`
         );
+        lines.push(indent + "    return err");
+        lines.push(indent + "  }");
       } else {
-        lines.push(indent + `result.${pascalCase(field.name)}, err = ${code}`);
-      }
-
-      if (!hasErr) {
-        lines.splice(startLine, 1, lines[startLine], "var err error;");
-        hasErr = true;
+        needErr();
+        lines.push(indent + `  if ${target}[j], err = ${code}; err != nil {`);
+        lines.push(indent + "    return err");
+        lines.push(indent + "  }");
       }
-
-      lines.push(indent + `if err != nil {`);
-      lines.push(indent + `  return result, err;`);
-      lines.push(indent + `}`);
+      lines.push(indent + "}");
+    } else if (isPrimitiveType) {
+      lines.push(indent + `${target} = ${code}`);
+    } else if (decodesInto) {
+      needErr();
+      lines.push(
+        indent +
+          `if err = Decode${pascalCase(type.name)}Into(buf, ${address}); err != nil {`
+      );
+      lines.push(indent + "  return err");
+      lines.push(indent + "}");
+    } else {
+      needErr();
+      lines.push(indent + `if ${target}, err = ${code}; err != nil {`);
+      lines.push(indent + "  return err");
+      lines.push(indent + "}");
     }
 
-    if (definition.kind === "MESSAGE") {
-      // lines.push("      break;");
+    if (isMessage) {
       lines.push("");
     }
   }
 
-  if (definition.kind === "MESSAGE") {
+  if (isMessage) {
     lines.push("    default:");
     lines.push(
-      '      return result, errors.New("attempted to parse invalid message");'
+      `      unknown, err := buf.SkipField(${quote(
+        definition.name
+      )}, fieldType)`
     );
-    lines.push("    }");
-    lines.push("  }");
-  } else if (definition.kind === "UNION") {
-    lines.push("    default:");
+    lines.push("      if err != nil {");
+    lines.push("        return err");
+    lines.push("      }");
     lines.push(
-      `      return result, errors.New("attempted to parse invalid union");`
+      "      result.UnknownFields = append(result.UnknownFields, unknown...)"
     );
+    lines.push("    }");
     lines.push("  }");
   } else {
-    lines.push("  return result, nil;");
+    lines.push("  return buf.Err()");
   }
 
   lines.push("}");
@@ -470,18 +662,39 @@ function compileDecode(
 function compileEncode(
   definition: Definition,
   definitions: { [name: string]: Definition },
-  aliases: AliasMap
+  aliases: AliasMap,
+  namedTypes: AliasMap,
+  stringViews: boolean
 ): string {
   let lines: string[] = [];
 
+  // Encode reserves the whole size up front. Nested values are written with
+  // the unexported encode, so their sizes aren't worked out again.
+  lines.push(
+    `func (i *${pascalCase(
+      definition.name
+    )}) Encode(buf buffer.Writer) error {`
+  );
+  lines.push("  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))");
+  lines.push("  return i.encode(buf)");
+  lines.push("}");
+  lines.push("");
   lines.push(
     `func (i *${pascalCase(
       definition.name
-    )}) Encode(buf *buffer.Buffer) error {`
+    )}) encode(buf buffer.Writer) error {`
   );
 
   let hasN = false;
 
+  if (definition.kind === "MESSAGE") {
+    for (let field of definition.fields) {
+      if (field.isRequired && !field.isDeprecated) {
+        lines.push(missingField(definition, field, "i"));
+      }
+    }
+  }
+
   let startLine = lines.length;
   let hasErr = false;
 
@@ -503,9 +716,7 @@ function compileEncode(
 
     let valueName =
       field.isArray &&
-      !["int16", "uint16", "uint32", "int32", "float32", "byte"].includes(
-        fieldType
-      )
+      !TYPED_ARRAYS[fieldType]
         ? definition.kind === "MESSAGE"
           ? `(*i.${fieldName})[j]`
           : `i.${fieldName}[j]`
@@ -514,6 +725,9 @@ function compileEncode(
     } else if (definition.kind === "MESSAGE") {
       valueName = "*" + valueName;
     }
+    if (namedTypes[field.type] && !(field.isArray && TYPED_ARRAYS[fieldType])) {
+      valueName = `${goTypeName(fieldType, stringViews)}(${valueName})`;
+    }
 
     switch (fieldType) {
       case "bool": {
@@ -537,7 +751,9 @@ function compileEncode(
       }
 
       case "alphanumeric": {
-        code = `buf.WriteAlphanumeric(${valueName});`;
+        code = stringViews
+          ? `buf.WriteAlphanumericBytes(${valueName});`
+          : `buf.WriteAlphanumeric(${valueName});`;
         break;
       }
 
@@ -557,7 +773,7 @@ function compileEncode(
       }
 
       case "lowp": {
-        code = `buf.WriteLowPrecisionFloat(${valueName});`;
+        code = `buf.WriteLowpFloat(float64(${valueName}));`;
         break;
       }
 
@@ -587,20 +803,47 @@ function compileEncode(
       }
 
       case "string": {
-        code = `buf.WriteString(${valueName});`;
+        code = stringViews
+          ? `buf.WriteStringBytes(${valueName});`
+          : `buf.WriteString(${valueName});`;
         break;
       }
 
-      case "discriminator": {
-        throw "Discriminator not implmeneted";
-        code = `buf.WriteVarUint(type);`;
+      case "int64": {
+        code = `buf.WriteInt64(${valueName});`;
         break;
       }
 
-      default: {
-        let type = definitions[fieldType!];
-        if (!type) {
-          throw new Error(
+      case "uint64": {
+        code = `buf.WriteUint64(${valueName});`;
+        break;
+      }
+
+      case "float64": {
+        code = `buf.WriteFloat64(${valueName});`;
+        break;
+      }
+
+      case "varint64": {
+        code = `buf.WriteVarInt64(${valueName});`;
+        break;
+      }
+
+      case "varuint64": {
+        code = `buf.WriteVarUint64(${valueName});`;
+        break;
+      }
+
+      case "discriminator": {
+        throw "Discriminator not implmeneted";
+        code = `buf.WriteVarUint(type);`;
+        break;
+      }
+
+      default: {
+        let type = definitions[fieldType!];
+        if (!type) {
+          throw new Error(
             "Invalid type " +
               quote(fieldType!) +
               " for field " +
@@ -614,36 +857,37 @@ function compileEncode(
           }
         } else if (type.kind === "SMOL" && !field.isArray) {
           if (definition.kind === "MESSAGE") {
-            code = `buf.WriteByte(byte(&i.${fieldName}))`;
+            code = `buf.WriteByte(byte(*i.${fieldName}))`;
           } else {
             code = `buf.WriteByte(byte(i.${fieldName}))`;
           }
         } else if (type.kind === "ENUM" && field.isArray) {
           if (definition.kind === "MESSAGE") {
-            code = `buf.WriteVarUint(uint(i.${fieldName}[j]))`;
+            code = `buf.WriteVarUint(uint((*i.${fieldName})[j]))`;
           } else {
             code = `buf.WriteVarUint(uint(i.${fieldName}[j]))`;
           }
         } else if (type.kind === "SMOL" && field.isArray) {
           if (definition.kind === "MESSAGE") {
-            code = `buf.WriteByte(byte(i.${fieldName}[j]))`;
+            code = `buf.WriteByte(byte((*i.${fieldName})[j]))`;
           } else {
             code = `buf.WriteByte(byte(i.${fieldName}[j]))`;
           }
-        } else if (
-          type.kind === "UNION" &&
-          isDiscriminatedUnion(type.name, definitions)
-        ) {
-          throw "Unsupported";
         } else if (type.kind === "UNION") {
-          throw "Unsupported";
+          if (field.isArray && definition.kind === "MESSAGE") {
+            code = `encode${pascalCase(type.name)}(buf, (*i.${fieldName})[j])`;
+          } else if (field.isArray) {
+            code = `encode${pascalCase(type.name)}(buf, i.${fieldName}[j])`;
+          } else {
+            code = `encode${pascalCase(type.name)}(buf, i.${fieldName})`;
+          }
         } else {
           if (field.isArray && definition.kind === "MESSAGE") {
-            code = `(*i.${pascalCase(field.name)})[j].Encode(buf)`;
+            code = `(*i.${pascalCase(field.name)})[j].encode(buf)`;
           } else if (field.isArray) {
-            code = `i.${pascalCase(field.name)}[j].Encode(buf)`;
+            code = `i.${pascalCase(field.name)}[j].encode(buf)`;
           } else {
-            code = `i.${pascalCase(field.name)}.Encode(buf)`;
+            code = `i.${pascalCase(field.name)}.encode(buf)`;
           }
         }
       }
@@ -658,41 +902,37 @@ function compileEncode(
     }
 
     if (definition.kind === "MESSAGE") {
-      lines.push(`    buf.WriteVarUint(${field.value});`);
+      lines.push(`    buf.WriteByte(${field.value});`);
     }
 
     if (field.isArray) {
       let indent = "   ";
       switch (fieldType) {
-        case "byte": {
-          if (definition.kind === "MESSAGE") {
+        case "byte":
+        case "int8":
+        case "int16":
+        case "uint16":
+        case "int32":
+        case "uint32":
+        case "float32":
+        case "int64":
+        case "uint64":
+        case "float64": {
+          if (namedTypes[field.type]) {
+            // Arrays of a named type are written as a slice of the type it
+            // names, which has the same layout.
+            const address =
+              definition.kind === "MESSAGE" ? valueName : "&" + valueName;
+            valueName = `*(*[]${goTypeName(
+              fieldType,
+              stringViews
+            )})(unsafe.Pointer(${address}))`;
+          } else if (definition.kind === "MESSAGE") {
             valueName = "*" + valueName;
           }
-          lines.push(indent + `buf.WriteByteArray(${valueName});`);
-          break;
-        }
-        case "uint16": {
-          lines.push(indent + `buf.WriteUint16Array(${valueName});`);
-          break;
-        }
-        case "uint32": {
-          lines.push(indent + `buf.WriteUint32Array(${valueName});`);
-          break;
-        }
-        case "int8": {
-          lines.push(indent + `buf.WriteInt8Array(${valueName});`);
-          break;
-        }
-        case "int16": {
-          lines.push(indent + `buf.WriteInt16Array(${valueName});`);
-          break;
-        }
-        case "int32": {
-          lines.push(indent + `buf.WriteInt32Array(${valueName});`);
-          break;
-        }
-        case "float32": {
-          lines.push(indent + `buf.WriteFloat32Array(${valueName});`);
+          lines.push(
+            indent + `buf.Write${TYPED_ARRAYS[fieldType]}(${valueName});`
+          );
           break;
         }
         default: {
@@ -711,7 +951,7 @@ function compileEncode(
 
           if (
             !TYPE_NAMES[fieldType] &&
-            ["STRUCT", "MESSAGE"].includes(definitions[fieldType].kind)
+            ["STRUCT", "MESSAGE", "UNION"].includes(definitions[fieldType].kind)
           ) {
             lines.push(`      err := ${code}`);
             lines.push(`      if err != nil {\nreturn err;\n}\n`);
@@ -723,7 +963,7 @@ function compileEncode(
       }
     } else if (TYPE_NAMES[fieldType]) {
       lines.push("    " + code);
-    } else if (["ENUM", "SMOL"].includes(definitions[field.type].kind)) {
+    } else if (["ENUM", "SMOL"].includes(definitions[fieldType].kind)) {
       lines.push("    " + code);
     } else {
       if (!hasErr) {
@@ -742,7 +982,8 @@ function compileEncode(
   // A field id of zero is reserved to indicate the end of the message
   if (definition.kind === "MESSAGE") {
     // lines.push("  }");
-    lines.push("  buf.WriteVarUint(0);");
+    lines.push("  buf.Write(i.UnknownFields);");
+    lines.push("  buf.WriteByte(0);");
   }
 
   lines.push("  return nil");
@@ -751,9 +992,897 @@ function compileEncode(
   return lines.join("\n");
 }
 
-export function compileSchema(schema: Schema): string {
+// Reset empties a value for reuse, for example from a sync.Pool, keeping the
+// room in its slices for DecodeXInto. Message fields are pointers that have to
+// go back to nil, so only DecodeXInto on a value that wasn't reset can reuse
+// what they point to.
+function compileReset(
+  definition: Definition,
+  definitions: { [name: string]: Definition },
+  aliases: AliasMap
+): string {
+  const name = pascalCase(definition.name);
+  let lines: string[] = [];
+  let kept: string[] = [];
+
+  lines.push(`// Reset empties i, keeping the room in its slices for Decode${name}Into.`);
+  lines.push(`func (i *${name}) Reset() {`);
+
+  if (definition.kind === "MESSAGE") {
+    kept.push("UnknownFields: i.UnknownFields[:0]");
+  } else {
+    for (let field of definition.fields) {
+      if (field.isDeprecated) continue;
+
+      const fieldName = pascalCase(field.name);
+      let fieldType = field.type;
+      if (aliases[fieldType]) fieldType = aliases[fieldType];
+
+      if (field.isArray) {
+        kept.push(`${fieldName}: i.${fieldName}[:0]`);
+      } else if (
+        !TYPE_NAMES[fieldType] &&
+        definitions[fieldType].kind === "STRUCT"
+      ) {
+        lines.push(`  i.${fieldName}.Reset()`);
+        kept.push(`${fieldName}: i.${fieldName}`);
+      }
+    }
+  }
+
+  lines.push(`  *i = ${name}{${kept.join(", ")}}`);
+  lines.push("}");
+
+  return lines.join("\n");
+}
+
+// notEqual returns the Go condition for two values of fieldType differing.
+// named is whether they have the named type of an alias instead.
+function notEqual(
+  fieldType: string,
+  a: string,
+  b: string,
+  named: boolean,
+  definitions: { [name: string]: Definition },
+  stringViews: boolean
+): string {
+  if (
+    stringViews &&
+    (fieldType === "string" || fieldType === "alphanumeric")
+  ) {
+    return `string(${a}) != string(${b})`;
+  }
+  // Floats are compared by their bits, so that NaN equals itself and a
+  // value equals its Clone.
+  const goType = TYPE_NAMES[fieldType];
+  if (goType === "float32" || goType === "float64") {
+    if (named) {
+      a = `${goType}(${a})`;
+      b = `${goType}(${b})`;
+    }
+    const bits =
+      goType === "float64" ? "math.Float64bits" : "math.Float32bits";
+    return `${bits}(${a}) != ${bits}(${b})`;
+  }
+  if (TYPE_NAMES[fieldType]) {
+    return `${a} != ${b}`;
+  }
+
+  const type = definitions[fieldType];
+  switch (type.kind) {
+    case "ENUM":
+    case "SMOL":
+      return `${a} != ${b}`;
+    case "UNION":
+      return `!Equal${pascalCase(type.name)}(${a}, ${b})`;
+    default:
+      return `!${a}.Equal(&${b})`;
+  }
+}
+
+// Equal compares values field by field. Nil and empty slices are equal, since
+// they encode the same, but a message field that isn't set only equals
+// another one that isn't set. Floats are equal when their bits are.
+function compileEqual(
+  definition: Definition,
+  definitions: { [name: string]: Definition },
+  aliases: AliasMap,
+  namedTypes: AliasMap,
+  stringViews: boolean
+): string {
+  const name = pascalCase(definition.name);
+  const isMessage = definition.kind === "MESSAGE";
+  let lines: string[] = [];
+
+  lines.push(
+    `// Equal reports whether i and other have the same fields${
+      isMessage ? " set to the same values" : ""
+    }.`
+  );
+  lines.push(`func (i *${name}) Equal(other *${name}) bool {`);
+  lines.push("  if i == nil || other == nil {");
+  lines.push("    return i == other");
+  lines.push("  }");
+
+  for (let field of definition.fields) {
+    if (field.isDeprecated) continue;
+
+    const fieldName = pascalCase(field.name);
+    let fieldType = field.type;
+    if (aliases[fieldType]) fieldType = aliases[fieldType];
+
+    let a = `i.${fieldName}`;
+    let b = `other.${fieldName}`;
+    const named = !!namedTypes[field.type];
+    const outer = lines.length;
+    if (usesPointer(definition, field, definitions)) {
+      lines.push(`  if (${a} == nil) != (${b} == nil) {`);
+      lines.push("    return false");
+      lines.push("  }");
+      lines.push(`  if ${a} != nil {`);
+      a = `(*${a})`;
+      b = `(*${b})`;
+    }
+
+    if (field.isArray) {
+      lines.push(`  if len(${a}) != len(${b}) {`);
+      lines.push("    return false");
+      lines.push("  }");
+      lines.push(`  for j := range ${a} {`);
+      lines.push(
+        `    if ${notEqual(
+          fieldType,
+          `${a}[j]`,
+          `${b}[j]`,
+          named,
+          definitions,
+          stringViews
+        )} {`
+      );
+      lines.push("      return false");
+      lines.push("    }");
+      lines.push("  }");
+    } else {
+      lines.push(
+        `  if ${notEqual(
+          fieldType,
+          a,
+          b,
+          named,
+          definitions,
+          stringViews
+        )} {`
+      );
+      lines.push("    return false");
+      lines.push("  }");
+    }
+
+    if (usesPointer(definition, field, definitions)) {
+      // Indent what's inside the nil check.
+      for (let k = outer + 4; k < lines.length; k++) {
+        lines[k] = "  " + lines[k];
+      }
+      lines.push("  }");
+    }
+  }
+
+  lines.push(
+    isMessage
+      ? "  return string(i.UnknownFields) == string(other.UnknownFields)"
+      : "  return true"
+  );
+  lines.push("}");
+
+  return lines.join("\n");
+}
+
+// cloneValue returns the Go expression for a deep copy of a value of
+// fieldType, or nothing if copying it with = is enough.
+function cloneValue(
+  fieldType: string,
+  value: string,
+  definitions: { [name: string]: Definition },
+  stringViews: boolean
+): string {
+  if (
+    stringViews &&
+    (fieldType === "string" || fieldType === "alphanumeric")
+  ) {
+    return `append(${value}[:0:0], ${value}...)`;
+  }
+  if (TYPE_NAMES[fieldType]) {
+    return "";
+  }
+
+  const type = definitions[fieldType];
+  switch (type.kind) {
+    case "ENUM":
+    case "SMOL":
+      return "";
+    case "UNION":
+      return `Clone${pascalCase(type.name)}(${value})`;
+    default:
+      return `${value}.clone()`;
+  }
+}
+
+// Clone makes a deep copy, so that nothing is shared with the original. With
+// stringViews, that includes the bytes the string fields point into.
+function compileClone(
+  definition: Definition,
+  definitions: { [name: string]: Definition },
+  aliases: AliasMap,
+  stringViews: boolean
+): string {
+  const name = pascalCase(definition.name);
+  let lines: string[] = [];
+
+  lines.push(`// Clone returns a deep copy of i.`);
+  lines.push(`func (i *${name}) Clone() *${name} {`);
+  lines.push("  if i == nil {");
+  lines.push("    return nil");
+  lines.push("  }");
+  lines.push("  c := i.clone()");
+  lines.push("  return &c");
+  lines.push("}");
+  lines.push("");
+
+  lines.push(`func (i *${name}) clone() ${name} {`);
+  lines.push("  c := *i");
+
+  for (let field of definition.fields) {
+    if (field.isDeprecated) continue;
+
+    const fieldName = pascalCase(field.name);
+    let fieldType = field.type;
+    if (aliases[fieldType]) fieldType = aliases[fieldType];
+
+    const isPointer = usesPointer(definition, field, definitions);
+    const value = isPointer ? `(*i.${fieldName})` : `i.${fieldName}`;
+    const copy = isPointer
+      ? `${snakeCase(field.name)}_${field.value}`
+      : `c.${fieldName}`;
+    const indent = isPointer ? "    " : "  ";
+
+    if (isPointer) {
+      lines.push(`  if i.${fieldName} != nil {`);
+    }
+
+    if (field.isArray) {
+      lines.push(
+        indent +
+          `${copy} ${isPointer ? ":=" : "="} append(${value}[:0:0], ${value}...)`
+      );
+      const element = cloneValue(
+        fieldType,
+        `${copy}[j]`,
+        definitions,
+        stringViews
+      );
+      if (element) {
+        lines.push(indent + `for j := range ${copy} {`);
+        lines.push(indent + `  ${copy}[j] = ${element}`);
+        lines.push(indent + "}");
+      }
+    } else {
+      const element = cloneValue(fieldType, value, definitions, stringViews);
+      if (isPointer) {
+        lines.push(indent + `${copy} := ${element || value}`);
+      } else if (element) {
+        lines.push(indent + `${copy} = ${element}`);
+      }
+    }
+
+    if (isPointer) {
+      lines.push(indent + `c.${fieldName} = &${copy}`);
+      lines.push("  }");
+    }
+  }
+
+  if (definition.kind === "MESSAGE") {
+    lines.push("  c.UnknownFields = append([]byte(nil), i.UnknownFields...)");
+  }
+  lines.push("  return c");
+  lines.push("}");
+
+  return lines.join("\n");
+}
+
+// The same as crc32.ChecksumIEEE in Go, for message.ID.
+function crc32(text: string): number {
+  let crc = 0xffffffff;
+  for (let byte of new TextEncoder().encode(text)) {
+    crc ^= byte;
+    for (let k = 0; k < 8; k++) {
+      crc = crc & 1 ? (crc >>> 1) ^ 0xedb88320 : crc >>> 1;
+    }
+  }
+  return (crc ^ 0xffffffff) >>> 0;
+}
+
+// The methods of message.Message that aren't generated elsewhere. TypeName has
+// the schema's package in front so that types from different schemas can be
+// registered together.
+function compileMessageMethods(definition: Definition, schema: Schema): string {
+  const name = pascalCase(definition.name);
+  const typeName = schema.package
+    ? `${schema.package}.${definition.name}`
+    : definition.name;
+
+  return [
+    `func (*${name}) TypeName() string {`,
+    `  return ${quote(typeName)}`,
+    "}",
+    "",
+    `func (*${name}) TypeID() uint32 {`,
+    `  return ${crc32(typeName)}`,
+    "}",
+    "",
+    `func (i *${name}) Decode(buf buffer.Reader) error {`,
+    `  return Decode${name}Into(buf, i)`,
+    "}",
+  ].join("\n");
+}
+
+const FIXED_SIZES: { [type: string]: number } = {
+  bool: 1,
+  byte: 1,
+  int8: 1,
+  uint8: 1,
+  int16: 2,
+  uint16: 2,
+  int32: 4,
+  uint32: 4,
+  float32: 4,
+  int64: 8,
+  uint64: 8,
+  float64: 8,
+};
+
+// sizeOf returns the Go expression for how many bytes encode writes for
+// value, which has the resolved type fieldType.
+function sizeOf(
+  fieldType: string,
+  value: string,
+  definitions: { [name: string]: Definition }
+): string {
+  if (FIXED_SIZES[fieldType]) {
+    return String(FIXED_SIZES[fieldType]);
+  }
+
+  switch (fieldType) {
+    case "int":
+      return `format.VarIntSize(${value})`;
+    case "uint":
+      return `format.VarUintSize(${value})`;
+    case "lowp":
+      return `format.LowpFloatSize(float64(${value}))`;
+    case "float":
+      return `buffer.VarFloatSize(${value})`;
+    case "varint64":
+      return `buffer.VarInt64Size(${value})`;
+    case "varuint64":
+      return `buffer.VarUint64Size(${value})`;
+    case "string":
+      return `format.BytesSize(len(${value}))`;
+    case "alphanumeric":
+      return `len(${value}) + 1`;
+  }
+
+  const type = definitions[fieldType];
+  switch (type.kind) {
+    case "ENUM":
+      return `format.VarUintSize(uint(${value}))`;
+    case "SMOL":
+      return "1";
+    case "UNION":
+      return `EncodedSize${pascalCase(type.name)}(format, ${value})`;
+    default:
+      return `${value}.EncodedSizeIn(format)`;
+  }
+}
+
+// EncodedSize works out exactly how many bytes Encode writes, without
+// encoding, so that Encode can reserve them in one go and callers can check
+// a size limit first.
+function compileEncodedSize(
+  definition: Definition,
+  definitions: { [name: string]: Definition },
+  aliases: AliasMap,
+  namedTypes: AliasMap,
+  stringViews: boolean
+): string {
+  const name = pascalCase(definition.name);
+  const isMessage = definition.kind === "MESSAGE";
+  let lines: string[] = [];
+
+  lines.push(
+    `// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.`
+  );
+  lines.push(`func (i *${name}) EncodedSize() int {`);
+  lines.push("  return i.EncodedSizeIn(buffer.FixedWidthFormat)");
+  lines.push("}");
+  lines.push("");
+  lines.push(`// EncodedSizeIn returns how many bytes Encode writes with format.`);
+  lines.push(
+    `func (i *${name}) EncodedSizeIn(format buffer.WireFormat) int {`
+  );
+
+  // Messages end with their unknown fields and the zero field number.
+  lines.push(isMessage ? "  n := len(i.UnknownFields) + 1" : "  n := 0");
+
+  for (let field of definition.fields) {
+    if (field.isDeprecated) continue;
+
+    const fieldName = pascalCase(field.name);
+    let fieldType = field.type;
+    if (aliases[fieldType]) fieldType = aliases[fieldType];
+
+    const isUnion = !TYPE_NAMES[fieldType] && definitions[fieldType].kind === "UNION";
+    const deref = isMessage && (field.isArray || !isUnion);
+    const value = deref ? `(*i.${fieldName})` : `i.${fieldName}`;
+    let indent = "  ";
+
+    if (isMessage) {
+      lines.push(`  if i.${fieldName} != nil {`);
+      lines.push("    n++");
+      indent = "    ";
+    }
+
+    if (field.isArray && TYPED_ARRAYS[fieldType]) {
+      lines.push(
+        indent +
+          `n += format.BytesSize(len(${value}) * ${FIXED_SIZES[fieldType]})`
+      );
+    } else if (field.isArray) {
+      lines.push(indent + `n += format.VarUintSize(uint(len(${value})))`);
+      let element = `${value}[j]`;
+      if (namedTypes[field.type]) {
+        element = `${goTypeName(fieldType, stringViews)}(${element})`;
+      }
+      const size = sizeOf(fieldType, element, definitions);
+      if (/^\d+$/.test(size)) {
+        lines.push(indent + `n += len(${value}) * ${size}`);
+      } else {
+        lines.push(indent + `for j := range ${value} {`);
+        lines.push(indent + `  n += ${size}`);
+        lines.push(indent + "}");
+      }
+    } else {
+      let element = value;
+      if (namedTypes[field.type]) {
+        element = `${goTypeName(fieldType, stringViews)}(${element})`;
+      }
+      lines.push(indent + `n += ${sizeOf(fieldType, element, definitions)}`);
+    }
+
+    if (isMessage) {
+      lines.push("  }");
+    }
+  }
+
+  lines.push("  return n");
+  lines.push("}");
+
+  return lines.join("\n");
+}
+
+// Message fields are pointers so that missing fields can be told apart.
+// Unions are interfaces, which can already be nil.
+function usesPointer(
+  definition: Definition,
+  field: Field,
+  definitions: { [name: string]: Definition }
+): boolean {
+  return (
+    definition.kind === "MESSAGE" &&
+    (field.isArray || definitions[field.type]?.kind !== "UNION")
+  );
+}
+
+// copyValue returns the statements that set target to a deep copy of value,
+// a field of fieldType, the same way clone copies it.
+function copyValue(
+  field: Field,
+  fieldType: string,
+  target: string,
+  assign: string,
+  value: string,
+  indent: string,
+  definitions: { [name: string]: Definition },
+  stringViews: boolean
+): string[] {
+  if (field.isArray) {
+    const lines = [
+      indent + `${target} ${assign} append(${value}[:0:0], ${value}...)`,
+    ];
+    const element = cloneValue(
+      fieldType,
+      `${target}[j]`,
+      definitions,
+      stringViews
+    );
+    if (element) {
+      lines.push(indent + `for j := range ${target} {`);
+      lines.push(indent + `  ${target}[j] = ${element}`);
+      lines.push(indent + "}");
+    }
+    return lines;
+  }
+
+  const element = cloneValue(fieldType, value, definitions, stringViews);
+  return [indent + `${target} ${assign} ${element || value}`];
+}
+
+// A pick is a struct, plus helpers to copy its fields from and back to the
+// definition it picked them from. Like Clone, they share nothing with the
+// value they copy from.
+function compilePick(
+  definition: Definition,
+  definitions: { [name: string]: Definition },
+  aliases: AliasMap,
+  stringViews: boolean
+): string {
+  const name = pascalCase(definition.name);
+  const parent = definitions[definition.pickFrom];
+  const parentName = pascalCase(parent.name);
+  let lines: string[] = [];
+
+  const parentFields = definition.fields
+    .filter((field) => !field.isDeprecated)
+    .map((field) =>
+      parent.fields.find((parentField) => parentField.name === field.name)
+    );
+  const copyField = (
+    field: Field,
+    target: string,
+    assign: string,
+    value: string,
+    indent: string
+  ) =>
+    copyValue(
+      field,
+      aliases[field.type] || field.type,
+      target,
+      assign,
+      value,
+      indent,
+      definitions,
+      stringViews
+    );
+
+  lines.push(
+    `// To${name} returns a deep copy of the fields ${name} picks from ${parentName}.`
+  );
+  lines.push(`func (i *${parentName}) To${name}() ${name} {`);
+  lines.push(`  result := ${name}{}`);
+  for (let field of parentFields) {
+    const fieldName = pascalCase(field.name);
+    if (usesPointer(parent, field, definitions)) {
+      lines.push(`  if i.${fieldName} != nil {`);
+      lines.push(
+        ...copyField(field, `result.${fieldName}`, "=", `(*i.${fieldName})`, "    ")
+      );
+      lines.push(`  }`);
+    } else {
+      lines.push(
+        ...copyField(field, `result.${fieldName}`, "=", `i.${fieldName}`, "  ")
+      );
+    }
+  }
+  lines.push(`  return result`);
+  lines.push(`}`);
+  lines.push("");
+
+  lines.push(
+    `// ApplyTo deep copies the fields of ${name} to the ${parentName} it was picked from.`
+  );
+  lines.push(`func (i *${name}) ApplyTo(to *${parentName}) {`);
+  for (let field of parentFields) {
+    const fieldName = pascalCase(field.name);
+    if (usesPointer(parent, field, definitions)) {
+      const copied = `${snakeCase(field.name)}_value`;
+      lines.push(...copyField(field, copied, ":=", `i.${fieldName}`, "  "));
+      lines.push(`  to.${fieldName} = &${copied}`);
+    } else {
+      lines.push(...copyField(field, `to.${fieldName}`, "=", `i.${fieldName}`, "  "));
+    }
+  }
+  lines.push(`}`);
+
+  return lines.join("\n");
+}
+
+const TYPE_KINDS = {
+  ENUM: "buffer.EnumType",
+  SMOL: "buffer.SmolType",
+  STRUCT: "buffer.StructType",
+  MESSAGE: "buffer.MessageType",
+  UNION: "buffer.UnionType",
+};
+
+// The wire layout of every definition, including deprecated fields, which
+// older senders still write.
+function compileTypes(schema: Schema, aliases: AliasMap): string {
+  let lines: string[] = [];
+
+  lines.push(
+    "// SchemaTypes describes this schema for buffer.Buffer.SetTypes, which lets"
+  );
+  lines.push(
+    "// code generated from an older version of it skip fields it doesn't know."
+  );
+  lines.push("var SchemaTypes = buffer.Types{");
+  for (let definition of schema.definitions) {
+    if (!TYPE_KINDS[definition.kind]) continue;
+
+    const fields = definition.fields
+      .filter((field) => field.type !== "discriminator")
+      // Picks can copy deprecated fields, which are never written in structs.
+      .filter((field) => definition.kind !== "STRUCT" || !field.isDeprecated)
+      .map((field) => {
+        let parts = [];
+        if (definition.kind !== "STRUCT") {
+          parts.push(`Number: ${field.value}`);
+        }
+        parts.push(`Type: ${quote(aliases[field.type] || field.type)}`);
+        if (field.isArray) {
+          parts.push("Array: true");
+        }
+        return `{${parts.join(", ")}}`;
+      });
+
+    if (fields.length && !["ENUM", "SMOL"].includes(definition.kind)) {
+      lines.push(
+        `  ${quote(definition.name)}: {Kind: ${
+          TYPE_KINDS[definition.kind]
+        }, Fields: []buffer.TypeField{${fields.join(", ")}}},`
+      );
+    } else {
+      lines.push(
+        `  ${quote(definition.name)}: {Kind: ${TYPE_KINDS[definition.kind]}},`
+      );
+    }
+  }
+  lines.push("}");
+
+  return lines.join("\n");
+}
+
+// A union is a sealed interface that only its members implement, so decoded
+// values can be used in a type switch. On the wire it is a type byte followed
+// by the member, the same for both union styles.
+//
+// A member that is a union itself is wrapped in a struct, like AnyShape for
+// Shape in Any. A struct or message can be in more than one of those unions,
+// and the wrapper keeps which one it was decoded as, so that it is encoded
+// with the same type byte again.
+function compileUnion(
+  definition: Definition,
+  definitions: { [name: string]: Definition }
+): string {
+  const name = pascalCase(definition.name);
+  const fields = definition.fields.filter(
+    (field) => field.type !== "discriminator"
+  );
+  const isUnion = (field: Field) => definitions[field.type].kind === "UNION";
+  // goName is the Go type of a member, which the interface holds a pointer to.
+  const goName = (field: Field) =>
+    isUnion(field) ? name + pascalCase(field.name) : pascalCase(field.name);
+  let lines: string[] = [];
+
+  for (let field of fields) {
+    if (!isUnion(field)) continue;
+    const wrapper = goName(field);
+    for (let other in definitions) {
+      if (pascalCase(other) === wrapper) {
+        error(
+          "The member " +
+            quote(field.name) +
+            " of " +
+            quote(definition.name) +
+            " needs the Go type " +
+            quote(wrapper) +
+            ", which is already used by " +
+            quote(other),
+          field.line,
+          field.column
+        );
+      }
+    }
+  }
+
+  lines.push(
+    `// ${name} is one of ${fields
+      .map((field) => "*" + goName(field))
+      .join(", ")
+      .replace(/, ([^,]*)$/, " or $1")}.`
+  );
+  if (isDiscriminatedUnion(definition.name, definitions)) {
+    lines.push(
+      `// The ${quote(
+        definition.fields[0].name
+      )} discriminator JavaScript sets is the concrete type here.`
+    );
+  }
+  lines.push(`type ${name} interface {`);
+  lines.push(`  is${name}()`);
+  lines.push(`}`);
+  lines.push("");
+
+  lines.push(`type ${name}Type byte`);
+  lines.push("");
+  lines.push("const (");
+  for (let field of fields) {
+    lines.push(
+      `  ${name}Type${pascalCase(field.name)} ${name}Type = ${field.value}`
+    );
+  }
+  lines.push(")");
+  lines.push("");
+
+  for (let field of fields) {
+    if (!isUnion(field)) continue;
+    const member = pascalCase(field.name);
+    lines.push(
+      `// ${goName(field)} holds the ${member} member of ${name}.`
+    );
+    lines.push(`type ${goName(field)} struct {`);
+    lines.push(`  Value ${member} \`json:"value"\``);
+    lines.push("}");
+    lines.push("");
+  }
+
+  for (let field of fields) {
+    lines.push(`func (*${goName(field)}) is${name}() {}`);
+  }
+  lines.push("");
+
+  lines.push(`func Encode${name}(buf buffer.Writer, value ${name}) error {`);
+  lines.push(`  buf.Grow(EncodedSize${name}(buf.WireFormat(), value))`);
+  lines.push(`  return encode${name}(buf, value)`);
+  lines.push("}");
+  lines.push("");
+
+  // Nil pointers to a member are as invalid as a nil interface.
+  lines.push(`func encode${name}(buf buffer.Writer, value ${name}) error {`);
+  lines.push("  switch value := value.(type) {");
+  for (let field of fields) {
+    if (field.isDeprecated) continue;
+    const member = pascalCase(field.name);
+
+    lines.push(`  case *${goName(field)}:`);
+    lines.push("    if value != nil {");
+    lines.push(`      buf.WriteByte(byte(${name}Type${member}))`);
+    lines.push(
+      isUnion(field)
+        ? `      return encode${member}(buf, value.Value)`
+        : "      return value.encode(buf)"
+    );
+    lines.push("    }");
+  }
+  lines.push("  }");
+  lines.push('  return errors.New("attempted to encode invalid union");');
+  lines.push("}");
+  lines.push("");
+
+  lines.push(
+    `// EncodedSize${name} returns how many bytes Encode${name} writes with format.`
+  );
+  lines.push(
+    `func EncodedSize${name}(format buffer.WireFormat, value ${name}) int {`
+  );
+  lines.push("  switch value := value.(type) {");
+  for (let field of fields) {
+    if (field.isDeprecated) continue;
+    const member = pascalCase(field.name);
+
+    lines.push(`  case *${goName(field)}:`);
+    lines.push("    if value != nil {");
+    lines.push(
+      isUnion(field)
+        ? `      return 1 + EncodedSize${member}(format, value.Value)`
+        : "      return 1 + value.EncodedSizeIn(format)"
+    );
+    lines.push("    }");
+  }
+  lines.push("  }");
+  lines.push("  return 0");
+  lines.push("}");
+  lines.push("");
+
+  lines.push(
+    `// Equal${name} reports whether a and b are the same member with the same fields.`
+  );
+  lines.push(`func Equal${name}(a, b ${name}) bool {`);
+  lines.push("  if a == nil || b == nil {");
+  lines.push("    return a == b");
+  lines.push("  }");
+  lines.push("  switch a := a.(type) {");
+  for (let field of fields) {
+    if (field.isDeprecated) continue;
+    const member = pascalCase(field.name);
+
+    lines.push(`  case *${goName(field)}:`);
+    lines.push(`    b, ok := b.(*${goName(field)})`);
+    if (isUnion(field)) {
+      lines.push("    if !ok || a == nil || b == nil {");
+      lines.push("      return ok && a == b");
+      lines.push("    }");
+      lines.push(`    return Equal${member}(a.Value, b.Value)`);
+    } else {
+      lines.push("    return ok && a.Equal(b)");
+    }
+  }
+  lines.push("  default:");
+  lines.push("    return false");
+  lines.push("  }");
+  lines.push("}");
+  lines.push("");
+
+  lines.push(`// Clone${name} returns a deep copy of value.`);
+  lines.push(`func Clone${name}(value ${name}) ${name} {`);
+  lines.push("  switch value := value.(type) {");
+  for (let field of fields) {
+    if (field.isDeprecated) continue;
+    const member = pascalCase(field.name);
+
+    lines.push(`  case *${goName(field)}:`);
+    if (isUnion(field)) {
+      lines.push("    if value == nil {");
+      lines.push("      return value");
+      lines.push("    }");
+      lines.push(
+        `    return &${goName(field)}{Value: Clone${member}(value.Value)}`
+      );
+    } else {
+      lines.push("    return value.Clone()");
+    }
+  }
+  lines.push("  default:");
+  lines.push("    return value");
+  lines.push("  }");
+  lines.push("}");
+  lines.push("");
+
+  lines.push(`func Decode${name}(buf buffer.Reader) (${name}, error) {`);
+  lines.push(`  switch ${name}Type(buf.ReadUint8()) {`);
+  for (let field of fields) {
+    const member = pascalCase(field.name);
+
+    lines.push(`  case ${name}Type${member}:`);
+    lines.push(`    value, err := Decode${member}(buf)`);
+    if (isUnion(field)) {
+      lines.push("    if value == nil {");
+      lines.push("      return nil, err");
+      lines.push("    }");
+      lines.push(`    return &${goName(field)}{Value: value}, err`);
+    } else {
+      lines.push("    return &value, err");
+    }
+  }
+  lines.push("  default:");
+  lines.push("    if err := buf.Err(); err != nil {");
+  lines.push("      return nil, err");
+  lines.push("    }");
+  lines.push(
+    '    return nil, errors.New("attempted to parse invalid union");'
+  );
+  lines.push("  }");
+  lines.push("}");
+
+  return lines.join("\n");
+}
+
+export function compileSchema(
+  schema: Schema,
+  stringViews: boolean = false,
+  typeAliases: boolean = false,
+  strictEnums: boolean = false
+): string {
   let definitions: { [name: string]: Definition } = {};
   let aliases: { [name: string]: string } = {};
+  let namedTypes: { [name: string]: string } = {};
   let name = schema.package;
   let go: string[] = [];
   const exportsList = [];
@@ -761,13 +1890,6 @@ export function compileSchema(schema: Schema): string {
 
   go.push(`package ${schema.package || "Schema"}`);
   go.push("");
-  go.push("import (");
-  go.push(` "errors"`);
-  go.push(` "bytes"`);
-  go.push(` "encoding/json"`);
-  go.push(` "github.com/jarred-sumner/peechy/buffer"`);
-  go.push(")");
-
   for (let i = 0; i < schema.definitions.length; i++) {
     let definition = schema.definitions[i];
     definitions[definition.name] = definition;
@@ -782,11 +1904,93 @@ export function compileSchema(schema: Schema): string {
     // }
   }
 
+  // Aliases of aliases are resolved down to the type they end up at.
+  for (let name in aliases) {
+    let seen = [name];
+    while (aliases[aliases[name]]) {
+      if (seen.includes(aliases[name])) {
+        error(
+          "Alias " + quote(name) + " refers to itself",
+          definitions[name].line,
+          definitions[name].column
+        );
+      }
+      seen.push(aliases[name]);
+      aliases[name] = aliases[aliases[name]];
+    }
+
+    // Aliases of built-in types become named types, unless typeAliases is
+    // set. Aliases of definitions are always type aliases, so they keep the
+    // methods of what they alias.
+    if (!typeAliases && TYPE_NAMES[aliases[name]]) {
+      namedTypes[name] = aliases[name];
+    }
+  }
+
+  // Only import what the generated code uses, or it won't compile.
+  const kinds = schema.definitions.map((definition) => definition.kind);
+  go.push("import (");
+  if (kinds.includes("UNION")) {
+    go.push(` "errors"`);
+  }
+  if (kinds.includes("ENUM") || kinds.includes("SMOL")) {
+    go.push(` "encoding/json"`);
+    go.push(` "strconv"`);
+  }
+  // Equal compares floats with the math package.
+  const hasFloats = schema.definitions.some(
+    (definition) =>
+      ["STRUCT", "MESSAGE"].includes(definition.kind) &&
+      definition.fields.some(
+        (field) =>
+          !field.isDeprecated &&
+          ["float32", "float64"].includes(
+            TYPE_NAMES[aliases[field.type] || field.type]
+          )
+      )
+  );
+  if (hasFloats) {
+    go.push(` "math"`);
+  }
+  // Arrays of a named number type are read and written in one go as a slice
+  // of the number type, which needs package unsafe.
+  const hasNamedArrays = schema.definitions.some(
+    (definition) =>
+      ["STRUCT", "MESSAGE"].includes(definition.kind) &&
+      definition.fields.some(
+        (field) =>
+          field.isArray &&
+          !field.isDeprecated &&
+          TYPED_ARRAYS[namedTypes[field.type]]
+      )
+  );
+  if (hasNamedArrays) {
+    go.push(` "unsafe"`);
+  }
+  go.push(` "github.com/jarred-sumner/peechy/buffer"`);
+  const messages = schema.definitions.filter((definition) =>
+    ["STRUCT", "MESSAGE"].includes(definition.kind)
+  );
+  if (messages.length > 0) {
+    go.push(` "github.com/jarred-sumner/peechy/message"`);
+  }
+  go.push(")");
+
   for (let i = 0; i < schema.definitions.length; i++) {
     let definition = schema.definitions[i];
-    if (definition.kind === "ALIAS") continue;
 
     switch (definition.kind) {
+      case "ALIAS": {
+        const target = definition.fields[0].name;
+        go.push(
+          `type ${pascalCase(definition.name)} ${
+            namedTypes[definition.name] ? "" : "= "
+          }${goElementType(target, stringViews)}`
+        );
+        go.push("");
+        break;
+      }
+
       case "SMOL":
       case "ENUM": {
         let value: any = {};
@@ -813,8 +2017,10 @@ export function compileSchema(schema: Schema): string {
             `  ${intName} ${pascalCase(definition.name)} = ${field.value}`
           );
 
-          stringValues.push(`  "${intName}": ${intName},`);
-          invertValues.push(`  ${intName}: "${intName}",`);
+          // The names are the schema's, which JavaScript and peechyjson use
+          // too.
+          stringValues.push(`  ${quote(field.name)}: ${intName},`);
+          invertValues.push(`  ${intName}: ${quote(field.name)},`);
         }
         go.push(...constantValues);
         go.push("");
@@ -841,99 +2047,33 @@ export function compileSchema(schema: Schema): string {
         go.push("}");
         go.push("");
 
-        go.push(
-          ...`
-// MarshalJSON marshals the enum as a quoted json string
-func (s ${pascalCase(definition.name)}) MarshalJSON() ([]byte, error) {
-  buffer := bytes.NewBufferString(\`"\`)
-  buffer.WriteString(${pascalCase(definition.name)}ToString[s])
-  buffer.WriteString(\`"\`)
-  return buffer.Bytes(), nil
-}
-
-// UnmarshalJSON unmashals a quoted json string to the enum value
-func (s *${pascalCase(definition.name)}) UnmarshalJSON(b []byte) error {
-  var j string
-  err := json.Unmarshal(b, &j)
-  if err != nil {
-    return err
-  }
-  // Note that if the string cannot be found then it will be set to the zero value, 'Created' in this case.
-  *s = ${pascalCase(definition.name)}ToID[j]
-  return nil
-}
-
-        `.split("\n")
-        );
+        go.push(compileEnumMethods(definition, strictEnums));
 
         break;
       }
 
       case "UNION": {
-        console.warn("Unions are unsupported.");
+        go.push(compileUnion(definition, definitions));
+        go.push("");
         break;
-        // let value: any = {};
-        // let keys: any = {};
-        // const encoders = new Array(definition.fields.length);
-        // encoders.fill("() => null");
-        // for (let j = 0; j < definition.fields.length; j++) {
-        //   let field = definition.fields[j];
-        //   let fieldType = field.type;
-        //   if (field.value > 0) {
-        //     if (aliases[field.name]) field.name = aliases[field.name];
-        //     value[field.name] = field.value;
-        //     value[field.value] = field.value;
-        //     keys[field.name] = field.name;
-        //     keys[field.value] = field.name;
-
-        //     encoders[field.value] = "encode" + fieldType;
-        //   }
-        // }
-        // exportsList.push(definition.name);
-        // go.push(
-        //   "const " +
-        //     definition.name +
-        //     " = " +
-        //     JSON.stringify(value, null, 2) +
-        //     ";"
-        // );
-        // go.push(
-        //   "const " +
-        //     definition.name +
-        //     "Keys = " +
-        //     JSON.stringify(keys, null, 2) +
-        //     ";"
-        // );
-
-        // exportsList.push(`${definition.name}Keys`);
-        // go.push("const " + definition.name + "Type = " + definition.name + ";");
-        // exportsList.push(definition.name + "Type");
-        // const encoderName = encoders.join(" , ");
-        // go.push(
-        //   "const encode" +
-        //     definition.name +
-        //     "ByType" +
-        //     " = (function() { return " +
-        //     "[" +
-        //     encoderName +
-        //     "]; })()"
-        // );
       }
       case "STRUCT":
       case "MESSAGE": {
-        go.push(`type ${definition.name} struct {`);
+        go.push(`type ${pascalCase(definition.name)} struct {`);
         for (let j = 0; j < definition.fields.length; j++) {
           let field = definition.fields[j];
 
+          // Deprecated fields are skipped when decoding and never written.
+          if (field.isDeprecated) continue;
+
           let isPrimitive =
             TYPE_NAMES[field.type] ||
             ["SMOL", "ENUM"].includes(definitions[field.type].kind);
           let typeName = "";
 
-          let singleTypeName =
-            TYPE_NAMES[field.type] || pascalCase(definitions[field.type].name);
+          let singleTypeName = goElementType(field.type, stringViews);
 
-          let usePointers = definition.kind === "MESSAGE";
+          let usePointers = usesPointer(definition, field, definitions);
           if (field.isArray && isPrimitive) {
             typeName = (usePointers ? "*" : "") + "[]" + singleTypeName;
           } else if (field.isArray) {
@@ -950,13 +2090,69 @@ func (s *${pascalCase(definition.name)}) UnmarshalJSON(b []byte) error {
             )}" redis:"${camelCase(field.name)}"\``
           );
         }
+        if (definition.kind === "MESSAGE") {
+          go.push("");
+          go.push(
+            "// UnknownFields are fields from a newer schema that DecodeX skipped, which Encode writes back."
+          );
+          go.push('UnknownFields []byte `json:"-" redis:"-"`');
+        }
         go.push(`}`);
 
         go.push("");
-        go.push(compileDecode(definition, definitions, aliases));
+        go.push(
+          compileDecode(
+            definition,
+            definitions,
+            aliases,
+            namedTypes,
+            stringViews,
+            strictEnums
+          )
+        );
+        go.push("");
+        go.push(
+          compileEncode(
+            definition,
+            definitions,
+            aliases,
+            namedTypes,
+            stringViews
+          )
+        );
+        go.push("");
+        go.push(compileMessageMethods(definition, schema));
+        go.push("");
+        go.push(compileReset(definition, definitions, aliases));
+        go.push("");
+        go.push(
+          compileEqual(
+            definition,
+            definitions,
+            aliases,
+            namedTypes,
+            stringViews
+          )
+        );
         go.push("");
-        go.push(compileEncode(definition, definitions, aliases));
+        go.push(compileClone(definition, definitions, aliases, stringViews));
         go.push("");
+        go.push(
+          compileEncodedSize(
+            definition,
+            definitions,
+            aliases,
+            namedTypes,
+            stringViews
+          )
+        );
+        go.push("");
+        if (definition.pickFrom) {
+          go.push(
+            compilePick(definition, definitions, aliases, stringViews)
+          );
+          go.push("");
+        }
         break;
       }
 
@@ -971,8 +2167,45 @@ func (s *${pascalCase(definition.name)}) UnmarshalJSON(b []byte) error {
     }
   }
 
+  go.push(compileTypes(schema, aliases));
   go.push("");
 
+  // Types with a package register themselves, and two registered types with
+  // the same name means two schemas have the same package, which is a bug.
+  // The names of types without one could be in any other schema too, so
+  // those are only registered when the caller asks.
+  if (messages.length > 0) {
+    if (schema.package) {
+      go.push("func init() {");
+    } else {
+      go.push(
+        "// Register registers the types in the schema with package message. It isn't",
+        "// done on import, since the schema has no package and other schemas could",
+        "// have types with the same names. It returns a *message.RegisterError if one",
+        "// of them is already registered.",
+        "func Register() error {"
+      );
+    }
+    go.push("  for _, newMessage := range []func() message.Message{");
+    for (let definition of messages) {
+      go.push(
+        `    func() message.Message { return new(${pascalCase(
+          definition.name
+        )}) },`
+      );
+    }
+    go.push("  } {");
+    go.push("    if err := message.Register(newMessage); err != nil {");
+    go.push(schema.package ? "      panic(err)" : "      return err");
+    go.push("    }");
+    go.push("  }");
+    if (!schema.package) {
+      go.push("  return nil");
+    }
+    go.push("}");
+    go.push("");
+  }
+
   return go.join("\n");
 }
 
@@ -980,9 +2213,14 @@ interface IAllocator {
   alloc(): Object;
 }
 
-export function compileSchemaGo(schema: Schema | string): any {
+export function compileSchemaGo(
+  schema: Schema | string,
+  stringViews: boolean = false,
+  typeAliases: boolean = false,
+  strictEnums: boolean = false
+): any {
   if (typeof schema === "string") {
     schema = parseSchema(schema);
   }
-  return compileSchema(schema);
+  return compileSchema(schema, stringViews, typeAliases, strictEnums);
 }
@@ -289,7 +289,7 @@ function compileDecode(
       ) {
         const key = quote(field.name + "Type");
         lines.push(
-          indent + "result[" + key + "] = " + "bb.readVarUint()" + ";",
+          indent + "result[" + key + "] = " + "bb.readByte()" + ";",
           indent +
             "result[" +
             quote(field.name) +
@@ -18,6 +18,11 @@ export let nativeTypes = [
   "uint",
   "discriminator",
   "alphanumeric",
+  "int64",
+  "uint64",
+  "float64",
+  "varint64",
+  "varuint64",
 ];
 
 export let nativeTypeMap = {
@@ -36,6 +41,11 @@ export let nativeTypeMap = {
   uint: 1,
   discriminator: 1,
   alphanumeric: 1,
+  int64: 1,
+  uint64: 1,
+  float64: 1,
+  varint64: 1,
+  varuint64: 1,
 };
 
 // These are special names on the object returned by compileSchema()
@@ -475,6 +485,7 @@ function parse(tokens: Token[]): Schema {
       column: token.column,
       kind: "STRUCT",
       fields,
+      pickFrom: definition.name,
     });
   }
 
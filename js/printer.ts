@@ -46,7 +46,7 @@ export function prettyPrintSchema(schema: Schema): string {
       for (let j = 0; j < definition.fields.length; j++) {
         let field = definition.fields[j];
         text += "  ";
-        if (definition.kind !== "ENUM") {
+        if (definition.kind !== "ENUM" && definition.kind !== "SMOL") {
           text += field.type;
           if (field.isArray) {
             text += "[]";
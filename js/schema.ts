@@ -21,6 +21,8 @@ export interface Definition {
   fields: Field[];
   extensions?: string[];
   serializerPath?: string;
+  // The definition a "pick" copied its fields from. Picks become structs.
+  pickFrom?: string;
 }
 
 export interface Field {
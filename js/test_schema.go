@@ -1,62 +1,113 @@
 package TestSchema
 
 import (
- "errors"
- "bytes"
  "encoding/json"
+ "strconv"
  "github.com/jarred-sumner/peechy/buffer"
+ "github.com/jarred-sumner/peechy/message"
 )
 type PackageProvider byte
 
 const (
   PackageProviderNpm PackageProvider = 1
   PackageProviderGit PackageProvider = 2
-  PackageProviderHttps PackageProvider = 3
+  PackageProviderHTTPS PackageProvider = 3
   PackageProviderTgz PackageProvider = 4
   PackageProviderOther PackageProvider = 5
 
 )
 
 var PackageProviderToString = map[PackageProvider]string{
-  PackageProviderNpm: "PackageProviderNpm",
-  PackageProviderGit: "PackageProviderGit",
-  PackageProviderHttps: "PackageProviderHttps",
-  PackageProviderTgz: "PackageProviderTgz",
-  PackageProviderOther: "PackageProviderOther",
+  PackageProviderNpm: "npm",
+  PackageProviderGit: "git",
+  PackageProviderHTTPS: "https",
+  PackageProviderTgz: "tgz",
+  PackageProviderOther: "other",
 
 }
 
 var PackageProviderToID = map[string]PackageProvider{
-  "PackageProviderNpm": PackageProviderNpm,
-  "PackageProviderGit": PackageProviderGit,
-  "PackageProviderHttps": PackageProviderHttps,
-  "PackageProviderTgz": PackageProviderTgz,
-  "PackageProviderOther": PackageProviderOther,
+  "npm": PackageProviderNpm,
+  "git": PackageProviderGit,
+  "https": PackageProviderHTTPS,
+  "tgz": PackageProviderTgz,
+  "other": PackageProviderOther,
 
 }
 
 
-// MarshalJSON marshals the enum as a quoted json string
+// PackageProviderValues returns every PackageProvider in the order of the schema.
+func PackageProviderValues() []PackageProvider {
+  return []PackageProvider{PackageProviderNpm, PackageProviderGit, PackageProviderHTTPS, PackageProviderTgz, PackageProviderOther}
+}
+
+// IsValid reports whether s is one of the PackageProvider constants.
+func (s PackageProvider) IsValid() bool {
+  _, ok := PackageProviderToString[s]
+  return ok
+}
+
+// String returns the name s has in the schema.
+func (s PackageProvider) String() string {
+  if name, ok := PackageProviderToString[s]; ok {
+    return name
+  }
+  return "PackageProvider(" + strconv.FormatUint(uint64(s), 10) + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler. It writes what MarshalJSON
+// does without the quotes, so that maps keyed by PackageProvider marshal too.
+func (s PackageProvider) MarshalText() ([]byte, error) {
+  if name, ok := PackageProviderToString[s]; ok {
+    return []byte(name), nil
+  }
+  return []byte(strconv.FormatUint(uint64(s), 10)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the name of
+// one of the values in the schema, or a number.
+func (s *PackageProvider) UnmarshalText(text []byte) error {
+  if value, ok := PackageProviderToID[string(text)]; ok {
+    *s = value
+    return nil
+  }
+  value, err := strconv.ParseUint(string(text), 10, 8)
+  if err != nil {
+    return &buffer.InvalidEnumError{Enum: "PackageProvider", Name: string(text)}
+  }
+  *s = PackageProvider(value)
+  return nil
+}
+
+// MarshalJSON marshals the enum as its name in the schema, the same as
+// JavaScript, or as a number if it isn't one of the constants.
 func (s PackageProvider) MarshalJSON() ([]byte, error) {
-  buffer := bytes.NewBufferString(`"`)
-  buffer.WriteString(PackageProviderToString[s])
-  buffer.WriteString(`"`)
-  return buffer.Bytes(), nil
+  if name, ok := PackageProviderToString[s]; ok {
+    return []byte(`"` + name + `"`), nil
+  }
+  return []byte(strconv.FormatUint(uint64(s), 10)), nil
 }
 
-// UnmarshalJSON unmashals a quoted json string to the enum value
+// UnmarshalJSON unmarshals a quoted json string, which has to be the name of
+// one of the values in the schema, or a number.
 func (s *PackageProvider) UnmarshalJSON(b []byte) error {
+  if string(b) == "null" {
+    return nil
+  }
+
   var j string
-  err := json.Unmarshal(b, &j)
-  if err != nil {
+  if err := json.Unmarshal(b, &j); err == nil {
+    return s.UnmarshalText([]byte(j))
+  }
+
+  var value byte
+  if err := json.Unmarshal(b, &value); err != nil {
     return err
   }
-  // Note that if the string cannot be found then it will be set to the zero value, 'Created' in this case.
-  *s = PackageProviderToID[j]
+  *s = PackageProvider(value)
   return nil
 }
 
-        
 type ExportsType byte
 
 const (
@@ -67,64 +118,143 @@ const (
 )
 
 var ExportsTypeToString = map[ExportsType]string{
-  ExportsTypeCommonJs: "ExportsTypeCommonJs",
-  ExportsTypeEsModule: "ExportsTypeEsModule",
-  ExportsTypeBrowser: "ExportsTypeBrowser",
+  ExportsTypeCommonJs: "commonJs",
+  ExportsTypeEsModule: "esModule",
+  ExportsTypeBrowser: "browser",
 
 }
 
 var ExportsTypeToID = map[string]ExportsType{
-  "ExportsTypeCommonJs": ExportsTypeCommonJs,
-  "ExportsTypeEsModule": ExportsTypeEsModule,
-  "ExportsTypeBrowser": ExportsTypeBrowser,
+  "commonJs": ExportsTypeCommonJs,
+  "esModule": ExportsTypeEsModule,
+  "browser": ExportsTypeBrowser,
+
+}
+
+
+// ExportsTypeValues returns every ExportsType in the order of the schema.
+func ExportsTypeValues() []ExportsType {
+  return []ExportsType{ExportsTypeCommonJs, ExportsTypeEsModule, ExportsTypeBrowser}
+}
+
+// IsValid reports whether s is one of the ExportsType constants.
+func (s ExportsType) IsValid() bool {
+  _, ok := ExportsTypeToString[s]
+  return ok
+}
 
+// String returns the name s has in the schema.
+func (s ExportsType) String() string {
+  if name, ok := ExportsTypeToString[s]; ok {
+    return name
+  }
+  return "ExportsType(" + strconv.FormatUint(uint64(s), 10) + ")"
 }
 
+// MarshalText implements encoding.TextMarshaler. It writes what MarshalJSON
+// does without the quotes, so that maps keyed by ExportsType marshal too.
+func (s ExportsType) MarshalText() ([]byte, error) {
+  if name, ok := ExportsTypeToString[s]; ok {
+    return []byte(name), nil
+  }
+  return []byte(strconv.FormatUint(uint64(s), 10)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the name of
+// one of the values in the schema, or a number.
+func (s *ExportsType) UnmarshalText(text []byte) error {
+  if value, ok := ExportsTypeToID[string(text)]; ok {
+    *s = value
+    return nil
+  }
+  value, err := strconv.ParseUint(string(text), 10, 8)
+  if err != nil {
+    return &buffer.InvalidEnumError{Enum: "ExportsType", Name: string(text)}
+  }
+  *s = ExportsType(value)
+  return nil
+}
 
-// MarshalJSON marshals the enum as a quoted json string
+// MarshalJSON marshals the enum as its name in the schema, the same as
+// JavaScript, or as a number if it isn't one of the constants.
 func (s ExportsType) MarshalJSON() ([]byte, error) {
-  buffer := bytes.NewBufferString(`"`)
-  buffer.WriteString(ExportsTypeToString[s])
-  buffer.WriteString(`"`)
-  return buffer.Bytes(), nil
+  if name, ok := ExportsTypeToString[s]; ok {
+    return []byte(`"` + name + `"`), nil
+  }
+  return []byte(strconv.FormatUint(uint64(s), 10)), nil
 }
 
-// UnmarshalJSON unmashals a quoted json string to the enum value
+// UnmarshalJSON unmarshals a quoted json string, which has to be the name of
+// one of the values in the schema, or a number.
 func (s *ExportsType) UnmarshalJSON(b []byte) error {
+  if string(b) == "null" {
+    return nil
+  }
+
   var j string
-  err := json.Unmarshal(b, &j)
-  if err != nil {
+  if err := json.Unmarshal(b, &j); err == nil {
+    return s.UnmarshalText([]byte(j))
+  }
+
+  var value byte
+  if err := json.Unmarshal(b, &value); err != nil {
     return err
   }
-  // Note that if the string cannot be found then it will be set to the zero value, 'Created' in this case.
-  *s = ExportsTypeToID[j]
+  *s = ExportsType(value)
   return nil
 }
 
-        
 type ExportsManifest struct {
 Source    []string     `json:"source" redis:"source"`
 Destination    []string     `json:"destination" redis:"destination"`
 ExportType    []ExportsType     `json:"exportType" redis:"exportType"`
 }
 
-func DecodeExportsManifest(buf *buffer.Buffer) (ExportsManifest, error) {
-   result := ExportsManifest{}
+func DecodeExportsManifest(buf buffer.Reader) (ExportsManifest, error) {
+  result := ExportsManifest{}
+  err := DecodeExportsManifestInto(buf, &result)
+  return result, err
+}
+
+// DecodeExportsManifestInto decodes into result, reusing the room in its slices.
+func DecodeExportsManifestInto(buf buffer.Reader, result *ExportsManifest) error {
+  var length uint
+  length = buf.ReadArrayLength()
+  if result.Source == nil || uint(cap(result.Source)) < length {
+    result.Source = make([]string, length)
+  } else {
+    result.Source = result.Source[:length]
+  }
+  for j := range result.Source {
+    result.Source[j] = buf.ReadAlphanumeric()
+  }
+  length = buf.ReadArrayLength()
+  if result.Destination == nil || uint(cap(result.Destination)) < length {
+    result.Destination = make([]string, length)
+  } else {
+    result.Destination = result.Destination[:length]
+  }
+  for j := range result.Destination {
+    result.Destination[j] = buf.ReadAlphanumeric()
+  }
+  length = buf.ReadArrayLength()
+  if result.ExportType == nil || uint(cap(result.ExportType)) < length {
+    result.ExportType = make([]ExportsType, length)
+  } else {
+    result.ExportType = result.ExportType[:length]
+  }
+  for j := range result.ExportType {
+    result.ExportType[j] = ExportsType(buf.ReadUint8())
+  }
+  return buf.Err()
+}
 
-  var length uint;
-  length = buf.ReadVarUint();
-  result.Source = make([]string, length)
-  for j := uint(0); j < length; j++ { result.Source[j] = buf.ReadAlphanumeric(); }
-  length = buf.ReadVarUint();
-  result.Destination = make([]string, length)
-  for j := uint(0); j < length; j++ { result.Destination[j] = buf.ReadAlphanumeric(); }
-  length = buf.ReadVarUint();
-  result.ExportType = make([]ExportsType, length)
-  for j := uint(0); j < length; j++ { result.ExportType[j] = ExportsType(buf.ReadByte()); }
-  return result, nil;
+func (i *ExportsManifest) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
 }
 
-func (i *ExportsManifest) Encode(buf *buffer.Buffer) error {
+func (i *ExportsManifest) encode(buf buffer.Writer) error {
 
     var n uint;
     n = uint(len(i.Source))
@@ -147,6 +277,93 @@ func (i *ExportsManifest) Encode(buf *buffer.Buffer) error {
   return nil
 }
 
+func (*ExportsManifest) TypeName() string {
+  return "TestSchema.ExportsManifest"
+}
+
+func (*ExportsManifest) TypeID() uint32 {
+  return 1595863304
+}
+
+func (i *ExportsManifest) Decode(buf buffer.Reader) error {
+  return DecodeExportsManifestInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeExportsManifestInto.
+func (i *ExportsManifest) Reset() {
+  *i = ExportsManifest{Source: i.Source[:0], Destination: i.Destination[:0], ExportType: i.ExportType[:0]}
+}
+
+// Equal reports whether i and other have the same fields.
+func (i *ExportsManifest) Equal(other *ExportsManifest) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if len(i.Source) != len(other.Source) {
+    return false
+  }
+  for j := range i.Source {
+    if i.Source[j] != other.Source[j] {
+      return false
+    }
+  }
+  if len(i.Destination) != len(other.Destination) {
+    return false
+  }
+  for j := range i.Destination {
+    if i.Destination[j] != other.Destination[j] {
+      return false
+    }
+  }
+  if len(i.ExportType) != len(other.ExportType) {
+    return false
+  }
+  for j := range i.ExportType {
+    if i.ExportType[j] != other.ExportType[j] {
+      return false
+    }
+  }
+  return true
+}
+
+// Clone returns a deep copy of i.
+func (i *ExportsManifest) Clone() *ExportsManifest {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *ExportsManifest) clone() ExportsManifest {
+  c := *i
+  c.Source = append(i.Source[:0:0], i.Source...)
+  c.Destination = append(i.Destination[:0:0], i.Destination...)
+  c.ExportType = append(i.ExportType[:0:0], i.ExportType...)
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *ExportsManifest) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *ExportsManifest) EncodedSizeIn(format buffer.WireFormat) int {
+  n := 0
+  n += format.VarUintSize(uint(len(i.Source)))
+  for j := range i.Source {
+    n += len(i.Source[j]) + 1
+  }
+  n += format.VarUintSize(uint(len(i.Destination)))
+  for j := range i.Destination {
+    n += len(i.Destination[j]) + 1
+  }
+  n += format.VarUintSize(uint(len(i.ExportType)))
+  n += len(i.ExportType) * 1
+  return n
+}
+
 type Version struct {
 Major    int     `json:"major" redis:"major"`
 Minor    int     `json:"minor" redis:"minor"`
@@ -155,18 +372,28 @@ Pre    string     `json:"pre" redis:"pre"`
 Build    string     `json:"build" redis:"build"`
 }
 
-func DecodeVersion(buf *buffer.Buffer) (Version, error) {
-   result := Version{}
+func DecodeVersion(buf buffer.Reader) (Version, error) {
+  result := Version{}
+  err := DecodeVersionInto(buf, &result)
+  return result, err
+}
 
+// DecodeVersionInto decodes into result, reusing the room in its slices.
+func DecodeVersionInto(buf buffer.Reader, result *Version) error {
   result.Major = buf.ReadVarInt()
   result.Minor = buf.ReadVarInt()
   result.Patch = buf.ReadVarInt()
   result.Pre = buf.ReadString()
   result.Build = buf.ReadString()
-  return result, nil;
+  return buf.Err()
+}
+
+func (i *Version) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
 }
 
-func (i *Version) Encode(buf *buffer.Buffer) error {
+func (i *Version) encode(buf buffer.Writer) error {
 
     buf.WriteVarInt(i.Major);
 
@@ -180,90 +407,310 @@ func (i *Version) Encode(buf *buffer.Buffer) error {
   return nil
 }
 
+func (*Version) TypeName() string {
+  return "TestSchema.Version"
+}
+
+func (*Version) TypeID() uint32 {
+  return 2363887789
+}
+
+func (i *Version) Decode(buf buffer.Reader) error {
+  return DecodeVersionInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeVersionInto.
+func (i *Version) Reset() {
+  *i = Version{}
+}
+
+// Equal reports whether i and other have the same fields.
+func (i *Version) Equal(other *Version) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if i.Major != other.Major {
+    return false
+  }
+  if i.Minor != other.Minor {
+    return false
+  }
+  if i.Patch != other.Patch {
+    return false
+  }
+  if i.Pre != other.Pre {
+    return false
+  }
+  if i.Build != other.Build {
+    return false
+  }
+  return true
+}
+
+// Clone returns a deep copy of i.
+func (i *Version) Clone() *Version {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *Version) clone() Version {
+  c := *i
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *Version) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *Version) EncodedSizeIn(format buffer.WireFormat) int {
+  n := 0
+  n += format.VarIntSize(i.Major)
+  n += format.VarIntSize(i.Minor)
+  n += format.VarIntSize(i.Patch)
+  n += format.BytesSize(len(i.Pre))
+  n += format.BytesSize(len(i.Build))
+  return n
+}
+
+type Timestamp string
+
 type JavascriptPackageInput struct {
 Name    *string     `json:"name" redis:"name"`
 Version    *string     `json:"version" redis:"version"`
 Dependencies    *RawDependencyList     `json:"dependencies" redis:"dependencies"`
+
+// UnknownFields are fields from a newer schema that DecodeX skipped, which Encode writes back.
+UnknownFields []byte `json:"-" redis:"-"`
 }
 
-func DecodeJavascriptPackageInput(buf *buffer.Buffer) (JavascriptPackageInput, error) {
-   result := JavascriptPackageInput{}
+func DecodeJavascriptPackageInput(buf buffer.Reader) (JavascriptPackageInput, error) {
+  result := JavascriptPackageInput{}
+  err := DecodeJavascriptPackageInputInto(buf, &result)
+  return result, err
+}
+
+// DecodeJavascriptPackageInputInto decodes into result, reusing what its fields point to.
+func DecodeJavascriptPackageInputInto(buf buffer.Reader, result *JavascriptPackageInput) error {
+  var err error
+  name_1, version_2, dependencies_3 := result.Name, result.Version, result.Dependencies
+  *result = JavascriptPackageInput{UnknownFields: result.UnknownFields[:0]}
 
-var err error;
-var fieldType uint;
   for {
-    switch fieldType = buf.ReadVarUint(); fieldType {
+    switch fieldType := buf.ReadUint8(); fieldType {
     case 0:
-      return result, nil;
+      return buf.Err()
 
     case 1:
-      name_0 := buf.ReadAlphanumeric()
-      result.Name = &name_0
+      if name_1 == nil {
+        name_1 = new(string)
+      }
+      result.Name = name_1
+      (*name_1) = buf.ReadAlphanumeric()
 
     case 2:
-      version_1 := buf.ReadString()
-      result.Version = &version_1
+      if version_2 == nil {
+        version_2 = new(string)
+      }
+      result.Version = version_2
+      (*version_2) = buf.ReadString()
 
     case 3:
-      var dependencies_2 RawDependencyList;
-      dependencies_2, err = DecodeRawDependencyList(buf)
-      result.Dependencies = &dependencies_2
-      if err != nil {
-        return result, err;
+      if dependencies_3 == nil {
+        dependencies_3 = new(RawDependencyList)
+      }
+      result.Dependencies = dependencies_3
+      if err = DecodeRawDependencyListInto(buf, dependencies_3); err != nil {
+        return err
       }
 
     default:
-      return result, errors.New("attempted to parse invalid message");
+      unknown, err := buf.SkipField("JavascriptPackageInput", fieldType)
+      if err != nil {
+        return err
+      }
+      result.UnknownFields = append(result.UnknownFields, unknown...)
     }
   }
 }
 
-func (i *JavascriptPackageInput) Encode(buf *buffer.Buffer) error {
+func (i *JavascriptPackageInput) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *JavascriptPackageInput) encode(buf buffer.Writer) error {
 
 var err error;
   if i.Name != nil {
-    buf.WriteVarUint(1);
+    buf.WriteByte(1);
     buf.WriteAlphanumeric(*i.Name);
    }
 
   if i.Version != nil {
-    buf.WriteVarUint(2);
+    buf.WriteByte(2);
     buf.WriteString(*i.Version);
    }
 
   if i.Dependencies != nil {
-    buf.WriteVarUint(3);
-    err =i.Dependencies.Encode(buf)
+    buf.WriteByte(3);
+    err =i.Dependencies.encode(buf)
     if err != nil {
  return err
 }
 
    }
-  buf.WriteVarUint(0);
+  buf.Write(i.UnknownFields);
+  buf.WriteByte(0);
   return nil
 }
 
+func (*JavascriptPackageInput) TypeName() string {
+  return "TestSchema.JavascriptPackageInput"
+}
+
+func (*JavascriptPackageInput) TypeID() uint32 {
+  return 1197167315
+}
+
+func (i *JavascriptPackageInput) Decode(buf buffer.Reader) error {
+  return DecodeJavascriptPackageInputInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeJavascriptPackageInputInto.
+func (i *JavascriptPackageInput) Reset() {
+  *i = JavascriptPackageInput{UnknownFields: i.UnknownFields[:0]}
+}
+
+// Equal reports whether i and other have the same fields set to the same values.
+func (i *JavascriptPackageInput) Equal(other *JavascriptPackageInput) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if (i.Name == nil) != (other.Name == nil) {
+    return false
+  }
+  if i.Name != nil {
+    if (*i.Name) != (*other.Name) {
+      return false
+    }
+  }
+  if (i.Version == nil) != (other.Version == nil) {
+    return false
+  }
+  if i.Version != nil {
+    if (*i.Version) != (*other.Version) {
+      return false
+    }
+  }
+  if (i.Dependencies == nil) != (other.Dependencies == nil) {
+    return false
+  }
+  if i.Dependencies != nil {
+    if !(*i.Dependencies).Equal(&(*other.Dependencies)) {
+      return false
+    }
+  }
+  return string(i.UnknownFields) == string(other.UnknownFields)
+}
+
+// Clone returns a deep copy of i.
+func (i *JavascriptPackageInput) Clone() *JavascriptPackageInput {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *JavascriptPackageInput) clone() JavascriptPackageInput {
+  c := *i
+  if i.Name != nil {
+    name_1 := (*i.Name)
+    c.Name = &name_1
+  }
+  if i.Version != nil {
+    version_2 := (*i.Version)
+    c.Version = &version_2
+  }
+  if i.Dependencies != nil {
+    dependencies_3 := (*i.Dependencies).clone()
+    c.Dependencies = &dependencies_3
+  }
+  c.UnknownFields = append([]byte(nil), i.UnknownFields...)
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *JavascriptPackageInput) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *JavascriptPackageInput) EncodedSizeIn(format buffer.WireFormat) int {
+  n := len(i.UnknownFields) + 1
+  if i.Name != nil {
+    n++
+    n += len((*i.Name)) + 1
+  }
+  if i.Version != nil {
+    n++
+    n += format.BytesSize(len((*i.Version)))
+  }
+  if i.Dependencies != nil {
+    n++
+    n += (*i.Dependencies).EncodedSizeIn(format)
+  }
+  return n
+}
+
 type RawDependencyList struct {
 Count    uint     `json:"count" redis:"count"`
 Names    []string     `json:"names" redis:"names"`
 Versions    []string     `json:"versions" redis:"versions"`
 }
 
-func DecodeRawDependencyList(buf *buffer.Buffer) (RawDependencyList, error) {
-   result := RawDependencyList{}
+func DecodeRawDependencyList(buf buffer.Reader) (RawDependencyList, error) {
+  result := RawDependencyList{}
+  err := DecodeRawDependencyListInto(buf, &result)
+  return result, err
+}
 
-  var length uint;
+// DecodeRawDependencyListInto decodes into result, reusing the room in its slices.
+func DecodeRawDependencyListInto(buf buffer.Reader, result *RawDependencyList) error {
+  var length uint
   result.Count = buf.ReadVarUint()
-  length = buf.ReadVarUint();
-  result.Names = make([]string, length)
-  for j := uint(0); j < length; j++ { result.Names[j] = buf.ReadAlphanumeric(); }
-  length = buf.ReadVarUint();
-  result.Versions = make([]string, length)
-  for j := uint(0); j < length; j++ { result.Versions[j] = buf.ReadString(); }
-  return result, nil;
+  length = buf.ReadArrayLength()
+  if result.Names == nil || uint(cap(result.Names)) < length {
+    result.Names = make([]string, length)
+  } else {
+    result.Names = result.Names[:length]
+  }
+  for j := range result.Names {
+    result.Names[j] = buf.ReadAlphanumeric()
+  }
+  length = buf.ReadArrayLength()
+  if result.Versions == nil || uint(cap(result.Versions)) < length {
+    result.Versions = make([]string, length)
+  } else {
+    result.Versions = result.Versions[:length]
+  }
+  for j := range result.Versions {
+    result.Versions[j] = buf.ReadString()
+  }
+  return buf.Err()
+}
+
+func (i *RawDependencyList) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
 }
 
-func (i *RawDependencyList) Encode(buf *buffer.Buffer) error {
+func (i *RawDependencyList) encode(buf buffer.Writer) error {
 
     var n uint;
     buf.WriteVarUint(i.Count);
@@ -282,6 +729,86 @@ func (i *RawDependencyList) Encode(buf *buffer.Buffer) error {
   return nil
 }
 
+func (*RawDependencyList) TypeName() string {
+  return "TestSchema.RawDependencyList"
+}
+
+func (*RawDependencyList) TypeID() uint32 {
+  return 3060300543
+}
+
+func (i *RawDependencyList) Decode(buf buffer.Reader) error {
+  return DecodeRawDependencyListInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeRawDependencyListInto.
+func (i *RawDependencyList) Reset() {
+  *i = RawDependencyList{Names: i.Names[:0], Versions: i.Versions[:0]}
+}
+
+// Equal reports whether i and other have the same fields.
+func (i *RawDependencyList) Equal(other *RawDependencyList) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if i.Count != other.Count {
+    return false
+  }
+  if len(i.Names) != len(other.Names) {
+    return false
+  }
+  for j := range i.Names {
+    if i.Names[j] != other.Names[j] {
+      return false
+    }
+  }
+  if len(i.Versions) != len(other.Versions) {
+    return false
+  }
+  for j := range i.Versions {
+    if i.Versions[j] != other.Versions[j] {
+      return false
+    }
+  }
+  return true
+}
+
+// Clone returns a deep copy of i.
+func (i *RawDependencyList) Clone() *RawDependencyList {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *RawDependencyList) clone() RawDependencyList {
+  c := *i
+  c.Names = append(i.Names[:0:0], i.Names...)
+  c.Versions = append(i.Versions[:0:0], i.Versions...)
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *RawDependencyList) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *RawDependencyList) EncodedSizeIn(format buffer.WireFormat) int {
+  n := 0
+  n += format.VarUintSize(i.Count)
+  n += format.VarUintSize(uint(len(i.Names)))
+  for j := range i.Names {
+    n += len(i.Names[j]) + 1
+  }
+  n += format.VarUintSize(uint(len(i.Versions)))
+  for j := range i.Versions {
+    n += format.BytesSize(len(i.Versions[j]))
+  }
+  return n
+}
+
 type JavascriptPackageManifest struct {
 Count    uint     `json:"count" redis:"count"`
 Name    []string     `json:"name" redis:"name"`
@@ -293,43 +820,85 @@ ExportsManifest    ExportsManifest     `json:"exportsManifest" redis:"exportsMan
 ExportsManifestIndex    []uint     `json:"exportsManifestIndex" redis:"exportsManifestIndex"`
 }
 
-func DecodeJavascriptPackageManifest(buf *buffer.Buffer) (JavascriptPackageManifest, error) {
-   result := JavascriptPackageManifest{}
+func DecodeJavascriptPackageManifest(buf buffer.Reader) (JavascriptPackageManifest, error) {
+  result := JavascriptPackageManifest{}
+  err := DecodeJavascriptPackageManifestInto(buf, &result)
+  return result, err
+}
 
-  var err error;
-  var length uint;
+// DecodeJavascriptPackageManifestInto decodes into result, reusing the room in its slices.
+func DecodeJavascriptPackageManifestInto(buf buffer.Reader, result *JavascriptPackageManifest) error {
+  var err error
+  var length uint
   result.Count = buf.ReadVarUint()
-  length = buf.ReadVarUint();
-  result.Name = make([]string, length)
-  for j := uint(0); j < length; j++ { result.Name[j] = buf.ReadAlphanumeric(); }
-  length = buf.ReadVarUint();
-  result.Version = make([]Version, length)
-  for j := uint(0); j < length; j++ {
- result.Version[j], err = DecodeVersion(buf);
- if (err != nil) {
- return result, err;
-}
-}
-  length = buf.ReadVarUint();
-  result.Providers = make([]PackageProvider, length)
-  for j := uint(0); j < length; j++ { result.Providers[j] = PackageProvider(buf.ReadByte()); }
-  length = buf.ReadVarUint();
-  result.Dependencies = make([]uint, length)
-  for j := uint(0); j < length; j++ { result.Dependencies[j] = buf.ReadVarUint(); }
-  length = buf.ReadVarUint();
-  result.DependenciesIndex = make([]uint, length)
-  for j := uint(0); j < length; j++ { result.DependenciesIndex[j] = buf.ReadVarUint(); }
-  result.ExportsManifest, err = DecodeExportsManifest(buf)
-  if err != nil {
-    return result, err;
+  length = buf.ReadArrayLength()
+  if result.Name == nil || uint(cap(result.Name)) < length {
+    result.Name = make([]string, length)
+  } else {
+    result.Name = result.Name[:length]
+  }
+  for j := range result.Name {
+    result.Name[j] = buf.ReadAlphanumeric()
+  }
+  length = buf.ReadArrayLength()
+  if result.Version == nil || uint(cap(result.Version)) < length {
+    result.Version = make([]Version, length)
+  } else {
+    result.Version = result.Version[:length]
+  }
+  for j := range result.Version {
+    if err = DecodeVersionInto(buf, &result.Version[j]); err != nil {
+      return err
+    }
+  }
+  length = buf.ReadArrayLength()
+  if result.Providers == nil || uint(cap(result.Providers)) < length {
+    result.Providers = make([]PackageProvider, length)
+  } else {
+    result.Providers = result.Providers[:length]
+  }
+  for j := range result.Providers {
+    result.Providers[j] = PackageProvider(buf.ReadUint8())
+  }
+  length = buf.ReadArrayLength()
+  if result.Dependencies == nil || uint(cap(result.Dependencies)) < length {
+    result.Dependencies = make([]uint, length)
+  } else {
+    result.Dependencies = result.Dependencies[:length]
+  }
+  for j := range result.Dependencies {
+    result.Dependencies[j] = buf.ReadVarUint()
+  }
+  length = buf.ReadArrayLength()
+  if result.DependenciesIndex == nil || uint(cap(result.DependenciesIndex)) < length {
+    result.DependenciesIndex = make([]uint, length)
+  } else {
+    result.DependenciesIndex = result.DependenciesIndex[:length]
+  }
+  for j := range result.DependenciesIndex {
+    result.DependenciesIndex[j] = buf.ReadVarUint()
   }
-  length = buf.ReadVarUint();
-  result.ExportsManifestIndex = make([]uint, length)
-  for j := uint(0); j < length; j++ { result.ExportsManifestIndex[j] = buf.ReadVarUint(); }
-  return result, nil;
+  if err = DecodeExportsManifestInto(buf, &result.ExportsManifest); err != nil {
+    return err
+  }
+  length = buf.ReadArrayLength()
+  if result.ExportsManifestIndex == nil || uint(cap(result.ExportsManifestIndex)) < length {
+    result.ExportsManifestIndex = make([]uint, length)
+  } else {
+    result.ExportsManifestIndex = result.ExportsManifestIndex[:length]
+  }
+  for j := range result.ExportsManifestIndex {
+    result.ExportsManifestIndex[j] = buf.ReadVarUint()
+  }
+  return buf.Err()
 }
 
-func (i *JavascriptPackageManifest) Encode(buf *buffer.Buffer) error {
+func (i *JavascriptPackageManifest) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *JavascriptPackageManifest) encode(buf buffer.Writer) error {
 
 var err error;
     var n uint;
@@ -344,7 +913,7 @@ var err error;
     n = uint(len(i.Version))
     buf.WriteVarUint(n);
     for j := uint(0); j < n; j++ {
-      err := i.Version[j].Encode(buf)
+      err := i.Version[j].encode(buf)
       if err != nil {
 return err;
 }
@@ -369,7 +938,7 @@ return err;
       buf.WriteVarUint(i.DependenciesIndex[j]);
     }
 
-    err =i.ExportsManifest.Encode(buf)
+    err =i.ExportsManifest.encode(buf)
     if err != nil {
  return err
 }
@@ -383,6 +952,145 @@ return err;
   return nil
 }
 
+func (*JavascriptPackageManifest) TypeName() string {
+  return "TestSchema.JavascriptPackageManifest"
+}
+
+func (*JavascriptPackageManifest) TypeID() uint32 {
+  return 218270498
+}
+
+func (i *JavascriptPackageManifest) Decode(buf buffer.Reader) error {
+  return DecodeJavascriptPackageManifestInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeJavascriptPackageManifestInto.
+func (i *JavascriptPackageManifest) Reset() {
+  i.ExportsManifest.Reset()
+  *i = JavascriptPackageManifest{Name: i.Name[:0], Version: i.Version[:0], Providers: i.Providers[:0], Dependencies: i.Dependencies[:0], DependenciesIndex: i.DependenciesIndex[:0], ExportsManifest: i.ExportsManifest, ExportsManifestIndex: i.ExportsManifestIndex[:0]}
+}
+
+// Equal reports whether i and other have the same fields.
+func (i *JavascriptPackageManifest) Equal(other *JavascriptPackageManifest) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if i.Count != other.Count {
+    return false
+  }
+  if len(i.Name) != len(other.Name) {
+    return false
+  }
+  for j := range i.Name {
+    if i.Name[j] != other.Name[j] {
+      return false
+    }
+  }
+  if len(i.Version) != len(other.Version) {
+    return false
+  }
+  for j := range i.Version {
+    if !i.Version[j].Equal(&other.Version[j]) {
+      return false
+    }
+  }
+  if len(i.Providers) != len(other.Providers) {
+    return false
+  }
+  for j := range i.Providers {
+    if i.Providers[j] != other.Providers[j] {
+      return false
+    }
+  }
+  if len(i.Dependencies) != len(other.Dependencies) {
+    return false
+  }
+  for j := range i.Dependencies {
+    if i.Dependencies[j] != other.Dependencies[j] {
+      return false
+    }
+  }
+  if len(i.DependenciesIndex) != len(other.DependenciesIndex) {
+    return false
+  }
+  for j := range i.DependenciesIndex {
+    if i.DependenciesIndex[j] != other.DependenciesIndex[j] {
+      return false
+    }
+  }
+  if !i.ExportsManifest.Equal(&other.ExportsManifest) {
+    return false
+  }
+  if len(i.ExportsManifestIndex) != len(other.ExportsManifestIndex) {
+    return false
+  }
+  for j := range i.ExportsManifestIndex {
+    if i.ExportsManifestIndex[j] != other.ExportsManifestIndex[j] {
+      return false
+    }
+  }
+  return true
+}
+
+// Clone returns a deep copy of i.
+func (i *JavascriptPackageManifest) Clone() *JavascriptPackageManifest {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *JavascriptPackageManifest) clone() JavascriptPackageManifest {
+  c := *i
+  c.Name = append(i.Name[:0:0], i.Name...)
+  c.Version = append(i.Version[:0:0], i.Version...)
+  for j := range c.Version {
+    c.Version[j] = c.Version[j].clone()
+  }
+  c.Providers = append(i.Providers[:0:0], i.Providers...)
+  c.Dependencies = append(i.Dependencies[:0:0], i.Dependencies...)
+  c.DependenciesIndex = append(i.DependenciesIndex[:0:0], i.DependenciesIndex...)
+  c.ExportsManifest = i.ExportsManifest.clone()
+  c.ExportsManifestIndex = append(i.ExportsManifestIndex[:0:0], i.ExportsManifestIndex...)
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *JavascriptPackageManifest) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *JavascriptPackageManifest) EncodedSizeIn(format buffer.WireFormat) int {
+  n := 0
+  n += format.VarUintSize(i.Count)
+  n += format.VarUintSize(uint(len(i.Name)))
+  for j := range i.Name {
+    n += len(i.Name[j]) + 1
+  }
+  n += format.VarUintSize(uint(len(i.Version)))
+  for j := range i.Version {
+    n += i.Version[j].EncodedSizeIn(format)
+  }
+  n += format.VarUintSize(uint(len(i.Providers)))
+  n += len(i.Providers) * 1
+  n += format.VarUintSize(uint(len(i.Dependencies)))
+  for j := range i.Dependencies {
+    n += format.VarUintSize(i.Dependencies[j])
+  }
+  n += format.VarUintSize(uint(len(i.DependenciesIndex)))
+  for j := range i.DependenciesIndex {
+    n += format.VarUintSize(i.DependenciesIndex[j])
+  }
+  n += i.ExportsManifest.EncodedSizeIn(format)
+  n += format.VarUintSize(uint(len(i.ExportsManifestIndex)))
+  for j := range i.ExportsManifestIndex {
+    n += format.VarUintSize(i.ExportsManifestIndex[j])
+  }
+  return n
+}
+
 type JavascriptPackageRequest struct {
 ClientVersion    *string     `json:"clientVersion" redis:"clientVersion"`
 Name    *string     `json:"name" redis:"name"`
@@ -390,80 +1098,109 @@ Dependencies    *RawDependencyList     `json:"dependencies" redis:"dependencies"
 OptionalDependencies    *RawDependencyList     `json:"optionalDependencies" redis:"optionalDependencies"`
 DevDependencies    *RawDependencyList     `json:"devDependencies" redis:"devDependencies"`
 PeerDependencies    *RawDependencyList     `json:"peerDependencies" redis:"peerDependencies"`
+
+// UnknownFields are fields from a newer schema that DecodeX skipped, which Encode writes back.
+UnknownFields []byte `json:"-" redis:"-"`
 }
 
-func DecodeJavascriptPackageRequest(buf *buffer.Buffer) (JavascriptPackageRequest, error) {
-   result := JavascriptPackageRequest{}
+func DecodeJavascriptPackageRequest(buf buffer.Reader) (JavascriptPackageRequest, error) {
+  result := JavascriptPackageRequest{}
+  err := DecodeJavascriptPackageRequestInto(buf, &result)
+  return result, err
+}
+
+// DecodeJavascriptPackageRequestInto decodes into result, reusing what its fields point to.
+func DecodeJavascriptPackageRequestInto(buf buffer.Reader, result *JavascriptPackageRequest) error {
+  var err error
+  client_version_1, name_2, dependencies_3, optional_dependencies_4, dev_dependencies_5, peer_dependencies_6 := result.ClientVersion, result.Name, result.Dependencies, result.OptionalDependencies, result.DevDependencies, result.PeerDependencies
+  *result = JavascriptPackageRequest{UnknownFields: result.UnknownFields[:0]}
 
-var err error;
-var fieldType uint;
   for {
-    switch fieldType = buf.ReadVarUint(); fieldType {
+    switch fieldType := buf.ReadUint8(); fieldType {
     case 0:
-      return result, nil;
+      return buf.Err()
 
     case 1:
-      client_version_0 := buf.ReadString()
-      result.ClientVersion = &client_version_0
+      if client_version_1 == nil {
+        client_version_1 = new(string)
+      }
+      result.ClientVersion = client_version_1
+      (*client_version_1) = buf.ReadString()
 
     case 2:
-      name_1 := buf.ReadAlphanumeric()
-      result.Name = &name_1
+      if name_2 == nil {
+        name_2 = new(string)
+      }
+      result.Name = name_2
+      (*name_2) = buf.ReadAlphanumeric()
 
     case 3:
-      var dependencies_2 RawDependencyList;
-      dependencies_2, err = DecodeRawDependencyList(buf)
-      result.Dependencies = &dependencies_2
-      if err != nil {
-        return result, err;
+      if dependencies_3 == nil {
+        dependencies_3 = new(RawDependencyList)
+      }
+      result.Dependencies = dependencies_3
+      if err = DecodeRawDependencyListInto(buf, dependencies_3); err != nil {
+        return err
       }
 
     case 4:
-      var optional_dependencies_3 RawDependencyList;
-      optional_dependencies_3, err = DecodeRawDependencyList(buf)
-      result.OptionalDependencies = &optional_dependencies_3
-      if err != nil {
-        return result, err;
+      if optional_dependencies_4 == nil {
+        optional_dependencies_4 = new(RawDependencyList)
+      }
+      result.OptionalDependencies = optional_dependencies_4
+      if err = DecodeRawDependencyListInto(buf, optional_dependencies_4); err != nil {
+        return err
       }
 
     case 5:
-      var dev_dependencies_4 RawDependencyList;
-      dev_dependencies_4, err = DecodeRawDependencyList(buf)
-      result.DevDependencies = &dev_dependencies_4
-      if err != nil {
-        return result, err;
+      if dev_dependencies_5 == nil {
+        dev_dependencies_5 = new(RawDependencyList)
+      }
+      result.DevDependencies = dev_dependencies_5
+      if err = DecodeRawDependencyListInto(buf, dev_dependencies_5); err != nil {
+        return err
       }
 
     case 6:
-      var peer_dependencies_5 RawDependencyList;
-      peer_dependencies_5, err = DecodeRawDependencyList(buf)
-      result.PeerDependencies = &peer_dependencies_5
-      if err != nil {
-        return result, err;
+      if peer_dependencies_6 == nil {
+        peer_dependencies_6 = new(RawDependencyList)
+      }
+      result.PeerDependencies = peer_dependencies_6
+      if err = DecodeRawDependencyListInto(buf, peer_dependencies_6); err != nil {
+        return err
       }
 
     default:
-      return result, errors.New("attempted to parse invalid message");
+      unknown, err := buf.SkipField("JavascriptPackageRequest", fieldType)
+      if err != nil {
+        return err
+      }
+      result.UnknownFields = append(result.UnknownFields, unknown...)
     }
   }
 }
 
-func (i *JavascriptPackageRequest) Encode(buf *buffer.Buffer) error {
+func (i *JavascriptPackageRequest) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *JavascriptPackageRequest) encode(buf buffer.Writer) error {
 
 var err error;
   if i.ClientVersion != nil {
-    buf.WriteVarUint(1);
+    buf.WriteByte(1);
     buf.WriteString(*i.ClientVersion);
    }
 
   if i.Name != nil {
-    buf.WriteVarUint(2);
+    buf.WriteByte(2);
     buf.WriteAlphanumeric(*i.Name);
    }
 
   if i.Dependencies != nil {
-    buf.WriteVarUint(3);
-    err =i.Dependencies.Encode(buf)
+    buf.WriteByte(3);
+    err =i.Dependencies.encode(buf)
     if err != nil {
  return err
 }
@@ -471,8 +1208,8 @@ var err error;
    }
 
   if i.OptionalDependencies != nil {
-    buf.WriteVarUint(4);
-    err =i.OptionalDependencies.Encode(buf)
+    buf.WriteByte(4);
+    err =i.OptionalDependencies.encode(buf)
     if err != nil {
  return err
 }
@@ -480,8 +1217,8 @@ var err error;
    }
 
   if i.DevDependencies != nil {
-    buf.WriteVarUint(5);
-    err =i.DevDependencies.Encode(buf)
+    buf.WriteByte(5);
+    err =i.DevDependencies.encode(buf)
     if err != nil {
  return err
 }
@@ -489,17 +1226,165 @@ var err error;
    }
 
   if i.PeerDependencies != nil {
-    buf.WriteVarUint(6);
-    err =i.PeerDependencies.Encode(buf)
+    buf.WriteByte(6);
+    err =i.PeerDependencies.encode(buf)
     if err != nil {
  return err
 }
 
    }
-  buf.WriteVarUint(0);
+  buf.Write(i.UnknownFields);
+  buf.WriteByte(0);
   return nil
 }
 
+func (*JavascriptPackageRequest) TypeName() string {
+  return "TestSchema.JavascriptPackageRequest"
+}
+
+func (*JavascriptPackageRequest) TypeID() uint32 {
+  return 306820272
+}
+
+func (i *JavascriptPackageRequest) Decode(buf buffer.Reader) error {
+  return DecodeJavascriptPackageRequestInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeJavascriptPackageRequestInto.
+func (i *JavascriptPackageRequest) Reset() {
+  *i = JavascriptPackageRequest{UnknownFields: i.UnknownFields[:0]}
+}
+
+// Equal reports whether i and other have the same fields set to the same values.
+func (i *JavascriptPackageRequest) Equal(other *JavascriptPackageRequest) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if (i.ClientVersion == nil) != (other.ClientVersion == nil) {
+    return false
+  }
+  if i.ClientVersion != nil {
+    if (*i.ClientVersion) != (*other.ClientVersion) {
+      return false
+    }
+  }
+  if (i.Name == nil) != (other.Name == nil) {
+    return false
+  }
+  if i.Name != nil {
+    if (*i.Name) != (*other.Name) {
+      return false
+    }
+  }
+  if (i.Dependencies == nil) != (other.Dependencies == nil) {
+    return false
+  }
+  if i.Dependencies != nil {
+    if !(*i.Dependencies).Equal(&(*other.Dependencies)) {
+      return false
+    }
+  }
+  if (i.OptionalDependencies == nil) != (other.OptionalDependencies == nil) {
+    return false
+  }
+  if i.OptionalDependencies != nil {
+    if !(*i.OptionalDependencies).Equal(&(*other.OptionalDependencies)) {
+      return false
+    }
+  }
+  if (i.DevDependencies == nil) != (other.DevDependencies == nil) {
+    return false
+  }
+  if i.DevDependencies != nil {
+    if !(*i.DevDependencies).Equal(&(*other.DevDependencies)) {
+      return false
+    }
+  }
+  if (i.PeerDependencies == nil) != (other.PeerDependencies == nil) {
+    return false
+  }
+  if i.PeerDependencies != nil {
+    if !(*i.PeerDependencies).Equal(&(*other.PeerDependencies)) {
+      return false
+    }
+  }
+  return string(i.UnknownFields) == string(other.UnknownFields)
+}
+
+// Clone returns a deep copy of i.
+func (i *JavascriptPackageRequest) Clone() *JavascriptPackageRequest {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *JavascriptPackageRequest) clone() JavascriptPackageRequest {
+  c := *i
+  if i.ClientVersion != nil {
+    client_version_1 := (*i.ClientVersion)
+    c.ClientVersion = &client_version_1
+  }
+  if i.Name != nil {
+    name_2 := (*i.Name)
+    c.Name = &name_2
+  }
+  if i.Dependencies != nil {
+    dependencies_3 := (*i.Dependencies).clone()
+    c.Dependencies = &dependencies_3
+  }
+  if i.OptionalDependencies != nil {
+    optional_dependencies_4 := (*i.OptionalDependencies).clone()
+    c.OptionalDependencies = &optional_dependencies_4
+  }
+  if i.DevDependencies != nil {
+    dev_dependencies_5 := (*i.DevDependencies).clone()
+    c.DevDependencies = &dev_dependencies_5
+  }
+  if i.PeerDependencies != nil {
+    peer_dependencies_6 := (*i.PeerDependencies).clone()
+    c.PeerDependencies = &peer_dependencies_6
+  }
+  c.UnknownFields = append([]byte(nil), i.UnknownFields...)
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *JavascriptPackageRequest) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *JavascriptPackageRequest) EncodedSizeIn(format buffer.WireFormat) int {
+  n := len(i.UnknownFields) + 1
+  if i.ClientVersion != nil {
+    n++
+    n += format.BytesSize(len((*i.ClientVersion)))
+  }
+  if i.Name != nil {
+    n++
+    n += len((*i.Name)) + 1
+  }
+  if i.Dependencies != nil {
+    n++
+    n += (*i.Dependencies).EncodedSizeIn(format)
+  }
+  if i.OptionalDependencies != nil {
+    n++
+    n += (*i.OptionalDependencies).EncodedSizeIn(format)
+  }
+  if i.DevDependencies != nil {
+    n++
+    n += (*i.DevDependencies).EncodedSizeIn(format)
+  }
+  if i.PeerDependencies != nil {
+    n++
+    n += (*i.PeerDependencies).EncodedSizeIn(format)
+  }
+  return n
+}
+
 type ErrorCode uint
 
 const (
@@ -511,97 +1396,177 @@ const (
 )
 
 var ErrorCodeToString = map[ErrorCode]string{
-  ErrorCodeGeneric: "ErrorCodeGeneric",
-  ErrorCodeMissingPackageName: "ErrorCodeMissingPackageName",
-  ErrorCodeServerDown: "ErrorCodeServerDown",
-  ErrorCodeVersionDoesntExit: "ErrorCodeVersionDoesntExit",
+  ErrorCodeGeneric: "generic",
+  ErrorCodeMissingPackageName: "missingPackageName",
+  ErrorCodeServerDown: "serverDown",
+  ErrorCodeVersionDoesntExit: "versionDoesntExit",
 
 }
 
 var ErrorCodeToID = map[string]ErrorCode{
-  "ErrorCodeGeneric": ErrorCodeGeneric,
-  "ErrorCodeMissingPackageName": ErrorCodeMissingPackageName,
-  "ErrorCodeServerDown": ErrorCodeServerDown,
-  "ErrorCodeVersionDoesntExit": ErrorCodeVersionDoesntExit,
+  "generic": ErrorCodeGeneric,
+  "missingPackageName": ErrorCodeMissingPackageName,
+  "serverDown": ErrorCodeServerDown,
+  "versionDoesntExit": ErrorCodeVersionDoesntExit,
+
+}
+
+
+// ErrorCodeValues returns every ErrorCode in the order of the schema.
+func ErrorCodeValues() []ErrorCode {
+  return []ErrorCode{ErrorCodeGeneric, ErrorCodeMissingPackageName, ErrorCodeServerDown, ErrorCodeVersionDoesntExit}
+}
+
+// IsValid reports whether s is one of the ErrorCode constants.
+func (s ErrorCode) IsValid() bool {
+  _, ok := ErrorCodeToString[s]
+  return ok
+}
+
+// String returns the name s has in the schema.
+func (s ErrorCode) String() string {
+  if name, ok := ErrorCodeToString[s]; ok {
+    return name
+  }
+  return "ErrorCode(" + strconv.FormatUint(uint64(s), 10) + ")"
+}
 
+// MarshalText implements encoding.TextMarshaler. It writes what MarshalJSON
+// does without the quotes, so that maps keyed by ErrorCode marshal too.
+func (s ErrorCode) MarshalText() ([]byte, error) {
+  if name, ok := ErrorCodeToString[s]; ok {
+    return []byte(name), nil
+  }
+  return []byte(strconv.FormatUint(uint64(s), 10)), nil
 }
 
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the name of
+// one of the values in the schema, or a number.
+func (s *ErrorCode) UnmarshalText(text []byte) error {
+  if value, ok := ErrorCodeToID[string(text)]; ok {
+    *s = value
+    return nil
+  }
+  value, err := strconv.ParseUint(string(text), 10, 0)
+  if err != nil {
+    return &buffer.InvalidEnumError{Enum: "ErrorCode", Name: string(text)}
+  }
+  *s = ErrorCode(value)
+  return nil
+}
 
-// MarshalJSON marshals the enum as a quoted json string
+// MarshalJSON marshals the enum as its name in the schema, the same as
+// JavaScript, or as a number if it isn't one of the constants.
 func (s ErrorCode) MarshalJSON() ([]byte, error) {
-  buffer := bytes.NewBufferString(`"`)
-  buffer.WriteString(ErrorCodeToString[s])
-  buffer.WriteString(`"`)
-  return buffer.Bytes(), nil
+  if name, ok := ErrorCodeToString[s]; ok {
+    return []byte(`"` + name + `"`), nil
+  }
+  return []byte(strconv.FormatUint(uint64(s), 10)), nil
 }
 
-// UnmarshalJSON unmashals a quoted json string to the enum value
+// UnmarshalJSON unmarshals a quoted json string, which has to be the name of
+// one of the values in the schema, or a number.
 func (s *ErrorCode) UnmarshalJSON(b []byte) error {
+  if string(b) == "null" {
+    return nil
+  }
+
   var j string
-  err := json.Unmarshal(b, &j)
-  if err != nil {
+  if err := json.Unmarshal(b, &j); err == nil {
+    return s.UnmarshalText([]byte(j))
+  }
+
+  var value uint
+  if err := json.Unmarshal(b, &value); err != nil {
     return err
   }
-  // Note that if the string cannot be found then it will be set to the zero value, 'Created' in this case.
-  *s = ErrorCodeToID[j]
+  *s = ErrorCode(value)
   return nil
 }
 
-        
 type JavascriptPackageResponse struct {
 Name    *string     `json:"name" redis:"name"`
 Result    *JavascriptPackageManifest     `json:"result" redis:"result"`
 ErrorCode    *ErrorCode     `json:"errorCode" redis:"errorCode"`
 Message    *string     `json:"message" redis:"message"`
+
+// UnknownFields are fields from a newer schema that DecodeX skipped, which Encode writes back.
+UnknownFields []byte `json:"-" redis:"-"`
 }
 
-func DecodeJavascriptPackageResponse(buf *buffer.Buffer) (JavascriptPackageResponse, error) {
-   result := JavascriptPackageResponse{}
+func DecodeJavascriptPackageResponse(buf buffer.Reader) (JavascriptPackageResponse, error) {
+  result := JavascriptPackageResponse{}
+  err := DecodeJavascriptPackageResponseInto(buf, &result)
+  return result, err
+}
+
+// DecodeJavascriptPackageResponseInto decodes into result, reusing what its fields point to.
+func DecodeJavascriptPackageResponseInto(buf buffer.Reader, result *JavascriptPackageResponse) error {
+  var err error
+  name_1, result_2, error_code_3, message_4 := result.Name, result.Result, result.ErrorCode, result.Message
+  *result = JavascriptPackageResponse{UnknownFields: result.UnknownFields[:0]}
 
-var err error;
-var fieldType uint;
   for {
-    switch fieldType = buf.ReadVarUint(); fieldType {
+    switch fieldType := buf.ReadUint8(); fieldType {
     case 0:
-      return result, nil;
+      return buf.Err()
 
     case 1:
-      name_0 := buf.ReadAlphanumeric()
-      result.Name = &name_0
+      if name_1 == nil {
+        name_1 = new(string)
+      }
+      result.Name = name_1
+      (*name_1) = buf.ReadAlphanumeric()
 
     case 2:
-      var result_1 JavascriptPackageManifest;
-      result_1, err = DecodeJavascriptPackageManifest(buf)
-      result.Result = &result_1
-      if err != nil {
-        return result, err;
+      if result_2 == nil {
+        result_2 = new(JavascriptPackageManifest)
+      }
+      result.Result = result_2
+      if err = DecodeJavascriptPackageManifestInto(buf, result_2); err != nil {
+        return err
       }
 
     case 3:
-      error_code_2 := ErrorCode(buf.ReadVarUint())
-      result.ErrorCode = &error_code_2
+      if error_code_3 == nil {
+        error_code_3 = new(ErrorCode)
+      }
+      result.ErrorCode = error_code_3
+      (*error_code_3) = ErrorCode(buf.ReadVarUint())
 
     case 4:
-      message_3 := buf.ReadString()
-      result.Message = &message_3
+      if message_4 == nil {
+        message_4 = new(string)
+      }
+      result.Message = message_4
+      (*message_4) = buf.ReadString()
 
     default:
-      return result, errors.New("attempted to parse invalid message");
+      unknown, err := buf.SkipField("JavascriptPackageResponse", fieldType)
+      if err != nil {
+        return err
+      }
+      result.UnknownFields = append(result.UnknownFields, unknown...)
     }
   }
 }
 
-func (i *JavascriptPackageResponse) Encode(buf *buffer.Buffer) error {
+func (i *JavascriptPackageResponse) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *JavascriptPackageResponse) encode(buf buffer.Writer) error {
 
 var err error;
   if i.Name != nil {
-    buf.WriteVarUint(1);
+    buf.WriteByte(1);
     buf.WriteAlphanumeric(*i.Name);
    }
 
   if i.Result != nil {
-    buf.WriteVarUint(2);
-    err =i.Result.Encode(buf)
+    buf.WriteByte(2);
+    err =i.Result.encode(buf)
     if err != nil {
  return err
 }
@@ -609,15 +1574,161 @@ var err error;
    }
 
   if i.ErrorCode != nil {
-    buf.WriteVarUint(3);
+    buf.WriteByte(3);
     buf.WriteVarUint(uint(*i.ErrorCode))
    }
 
   if i.Message != nil {
-    buf.WriteVarUint(4);
+    buf.WriteByte(4);
     buf.WriteString(*i.Message);
    }
-  buf.WriteVarUint(0);
+  buf.Write(i.UnknownFields);
+  buf.WriteByte(0);
   return nil
 }
 
+func (*JavascriptPackageResponse) TypeName() string {
+  return "TestSchema.JavascriptPackageResponse"
+}
+
+func (*JavascriptPackageResponse) TypeID() uint32 {
+  return 2508449945
+}
+
+func (i *JavascriptPackageResponse) Decode(buf buffer.Reader) error {
+  return DecodeJavascriptPackageResponseInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeJavascriptPackageResponseInto.
+func (i *JavascriptPackageResponse) Reset() {
+  *i = JavascriptPackageResponse{UnknownFields: i.UnknownFields[:0]}
+}
+
+// Equal reports whether i and other have the same fields set to the same values.
+func (i *JavascriptPackageResponse) Equal(other *JavascriptPackageResponse) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if (i.Name == nil) != (other.Name == nil) {
+    return false
+  }
+  if i.Name != nil {
+    if (*i.Name) != (*other.Name) {
+      return false
+    }
+  }
+  if (i.Result == nil) != (other.Result == nil) {
+    return false
+  }
+  if i.Result != nil {
+    if !(*i.Result).Equal(&(*other.Result)) {
+      return false
+    }
+  }
+  if (i.ErrorCode == nil) != (other.ErrorCode == nil) {
+    return false
+  }
+  if i.ErrorCode != nil {
+    if (*i.ErrorCode) != (*other.ErrorCode) {
+      return false
+    }
+  }
+  if (i.Message == nil) != (other.Message == nil) {
+    return false
+  }
+  if i.Message != nil {
+    if (*i.Message) != (*other.Message) {
+      return false
+    }
+  }
+  return string(i.UnknownFields) == string(other.UnknownFields)
+}
+
+// Clone returns a deep copy of i.
+func (i *JavascriptPackageResponse) Clone() *JavascriptPackageResponse {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *JavascriptPackageResponse) clone() JavascriptPackageResponse {
+  c := *i
+  if i.Name != nil {
+    name_1 := (*i.Name)
+    c.Name = &name_1
+  }
+  if i.Result != nil {
+    result_2 := (*i.Result).clone()
+    c.Result = &result_2
+  }
+  if i.ErrorCode != nil {
+    error_code_3 := (*i.ErrorCode)
+    c.ErrorCode = &error_code_3
+  }
+  if i.Message != nil {
+    message_4 := (*i.Message)
+    c.Message = &message_4
+  }
+  c.UnknownFields = append([]byte(nil), i.UnknownFields...)
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *JavascriptPackageResponse) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *JavascriptPackageResponse) EncodedSizeIn(format buffer.WireFormat) int {
+  n := len(i.UnknownFields) + 1
+  if i.Name != nil {
+    n++
+    n += len((*i.Name)) + 1
+  }
+  if i.Result != nil {
+    n++
+    n += (*i.Result).EncodedSizeIn(format)
+  }
+  if i.ErrorCode != nil {
+    n++
+    n += format.VarUintSize(uint((*i.ErrorCode)))
+  }
+  if i.Message != nil {
+    n++
+    n += format.BytesSize(len((*i.Message)))
+  }
+  return n
+}
+
+// SchemaTypes describes this schema for buffer.Buffer.SetTypes, which lets
+// code generated from an older version of it skip fields it doesn't know.
+var SchemaTypes = buffer.Types{
+  "PackageProvider": {Kind: buffer.SmolType},
+  "ExportsType": {Kind: buffer.SmolType},
+  "ExportsManifest": {Kind: buffer.StructType, Fields: []buffer.TypeField{{Type: "alphanumeric", Array: true}, {Type: "alphanumeric", Array: true}, {Type: "ExportsType", Array: true}}},
+  "Version": {Kind: buffer.StructType, Fields: []buffer.TypeField{{Type: "int"}, {Type: "int"}, {Type: "int"}, {Type: "string"}, {Type: "string"}}},
+  "JavascriptPackageInput": {Kind: buffer.MessageType, Fields: []buffer.TypeField{{Number: 1, Type: "alphanumeric"}, {Number: 2, Type: "string"}, {Number: 3, Type: "RawDependencyList"}}},
+  "RawDependencyList": {Kind: buffer.StructType, Fields: []buffer.TypeField{{Type: "uint"}, {Type: "alphanumeric", Array: true}, {Type: "string", Array: true}}},
+  "JavascriptPackageManifest": {Kind: buffer.StructType, Fields: []buffer.TypeField{{Type: "uint"}, {Type: "alphanumeric", Array: true}, {Type: "Version", Array: true}, {Type: "PackageProvider", Array: true}, {Type: "uint", Array: true}, {Type: "uint", Array: true}, {Type: "ExportsManifest"}, {Type: "uint", Array: true}}},
+  "JavascriptPackageRequest": {Kind: buffer.MessageType, Fields: []buffer.TypeField{{Number: 1, Type: "string"}, {Number: 2, Type: "alphanumeric"}, {Number: 3, Type: "RawDependencyList"}, {Number: 4, Type: "RawDependencyList"}, {Number: 5, Type: "RawDependencyList"}, {Number: 6, Type: "RawDependencyList"}}},
+  "ErrorCode": {Kind: buffer.EnumType},
+  "JavascriptPackageResponse": {Kind: buffer.MessageType, Fields: []buffer.TypeField{{Number: 1, Type: "alphanumeric"}, {Number: 2, Type: "JavascriptPackageManifest"}, {Number: 3, Type: "ErrorCode"}, {Number: 4, Type: "string"}}},
+}
+
+func init() {
+  for _, newMessage := range []func() message.Message{
+    func() message.Message { return new(ExportsManifest) },
+    func() message.Message { return new(Version) },
+    func() message.Message { return new(JavascriptPackageInput) },
+    func() message.Message { return new(RawDependencyList) },
+    func() message.Message { return new(JavascriptPackageManifest) },
+    func() message.Message { return new(JavascriptPackageRequest) },
+    func() message.Message { return new(JavascriptPackageResponse) },
+  } {
+    if err := message.Register(newMessage); err != nil {
+      panic(err)
+    }
+  }
+}
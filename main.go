@@ -1 +1,278 @@
+// Command peechy is the Go version of js/cli.ts, for the outputs that don't
+// need JavaScript: Go code, schema text and binary schemas, and converting
+// between binary and JSON. It prints the same errors and exits with the same
+// codes. It takes the same flags, but the ones for other languages, like --js
+// and --ts, fail with an error that says to use js/cli.ts.
 package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/jarred-sumner/peechy/golang"
+	"github.com/jarred-sumner/peechy/peechyjson"
+	"github.com/jarred-sumner/peechy/schema"
+)
+
+var usage = strings.Join([]string{
+	"",
+	"Usage: peechy [OPTIONS]",
+	"",
+	"Options:",
+	"",
+	"  --help                Print this message.",
+	"  --schema [PATH]       The schema file to use.",
+	"  --go [PATH]           Generate Go code.",
+	"  --go-strings [TYPE]   Decode Go strings as \"string\" (default) or as \"bytes\",",
+	"                        []byte slices of the buffer that aren't copied.",
+	"  --go-aliases [KIND]   Generate Go aliases as \"named\" types (default) or as",
+	"                        type \"alias\" declarations.",
+	"  --go-enums [POLICY]   Keep enum values that aren't in the schema when decoding",
+	"                        Go (\"preserve\", default) or fail (\"strict\").",
+	"  --text [PATH]         Encode the schema as text.",
+	"  --binary [PATH]       Encode the schema as a binary blob.",
+	"  --root-type [NAME]    Set the root type for JSON.",
+	"  --to-json [PATH]      Convert a binary file to JSON.",
+	"  --from-json [PATH]    Convert a JSON file to binary.",
+	"",
+	"The flags of js/cli.ts for other languages, like --js and --ts, need",
+	"js/cli.ts.",
+	"",
+	"Examples:",
+	"",
+	"  peechy --schema test.kiwi --go test.go",
+	"  peechy --schema test.kiwi --binary test.bkiwi",
+	"  peechy --schema test.kiwi --root-type Test --from-json buffer.json",
+	"  peechy --schema test.kiwi --root-type Test --to-json buffer.bin",
+	"",
+}, "\n")
+
+// flagNames are the flags run takes, which all have a value.
+var flagNames = []string{
+	"--schema",
+	"--go",
+	"--go-strings",
+	"--go-aliases",
+	"--go-enums",
+	"--binary",
+	"--text",
+	"--root-type",
+	"--to-json",
+	"--from-json",
+}
+
+// jsFlags are the flags of cli.ts for other languages. run takes them so that
+// it can say to use cli.ts, instead of calling them unknown.
+var jsFlags = []string{
+	"--js",
+	"--esm",
+	"--js-allocator",
+	"--ts",
+	"--zig",
+	"--cpp",
+	"--callback-cpp",
+	"--skew",
+	"--skew-types",
+}
+
+func writeFile(path string, contents []byte) error {
+	if old, err := ioutil.ReadFile(path); err == nil && bytes.Equal(old, contents) {
+		return nil // Avoid unnecessarily modifying files
+	}
+	return ioutil.WriteFile(path, contents, 0644)
+}
+
+// quote quotes text like JSON.stringify, for the same errors as cli.ts.
+func quote(text string) string {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(text)
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// run is main in cli.ts. It returns 1 after printing the usage, and an error
+// where cli.ts throws one.
+func run(args []string, stdout io.Writer) (int, error) {
+	flags := map[string]*string{}
+	for _, name := range append(flagNames, jsFlags...) {
+		flags[name] = nil
+	}
+
+	// Parse flags
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "-h" || arg == "--help" || !strings.HasPrefix(arg, "-") {
+			fmt.Fprintln(stdout, usage)
+			return 1, nil
+		} else if _, ok := flags[arg]; ok {
+			if i+1 == len(args) {
+				return 1, errors.New("Missing value for " + quote(arg) + ` (use "--help" for usage)`)
+			}
+			i++
+			flags[arg] = &args[i]
+		} else {
+			return 1, errors.New("Unknown flag " + quote(arg) + ` (use "--help" for usage)`)
+		}
+	}
+
+	// Must have a schema
+	if flags["--schema"] == nil {
+		fmt.Fprintln(stdout, usage)
+		return 1, nil
+	}
+
+	// Fail before writing anything if part of the output needs cli.ts
+	for _, name := range jsFlags {
+		if flags[name] != nil {
+			return 1, errors.New("Unsupported flag " + quote(name) + " (use js/cli.ts for other languages)")
+		}
+	}
+
+	// Try loading the schema
+	path := *flags["--schema"]
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 1, err
+	}
+	isText := bytes.IndexByte(content, 0) == -1 // Binary schemas will have null-terminated strings
+
+	// Try parsing the schema, pretty-print errors on failure
+	var parsed *schema.Schema
+	if isText {
+		parsed, err = schema.Parse(string(content))
+	} else {
+		parsed, err = schema.DecodeBinary(content)
+	}
+	if err == nil {
+		_, err = golang.Compile(parsed, golang.Options{})
+	}
+	if err != nil {
+		var e *schema.Error
+		if !errors.As(err, &e) {
+			return 1, err
+		}
+		message := fmt.Sprintf("%s:%d:%d: error: %s", path, e.Line, e.Column, e.Message)
+		if isText {
+			lines := strings.Split(string(content), "\n")
+			line := ""
+			if e.Line >= 1 && e.Line <= len(lines) {
+				line = lines[e.Line-1]
+			}
+			column := e.Column - 1
+			if column < 0 {
+				column = 0
+			}
+			message += "\n" + line + "\n" + strings.Repeat(" ", column) + "^"
+		}
+		return 1, errors.New(message)
+	}
+
+	// Validate the root type
+	rootType := flags["--root-type"]
+	if rootType != nil {
+		definition := parsed.Definition(*rootType)
+		if definition == nil || (definition.Kind != schema.Struct &&
+			definition.Kind != schema.Message && definition.Kind != schema.Union) {
+			return 1, errors.New("Invalid root type: " + quote(*rootType))
+		}
+	}
+
+	goStrings := flags["--go-strings"]
+	if goStrings != nil && *goStrings != "string" && *goStrings != "bytes" {
+		return 1, errors.New("Invalid --go-strings: " + quote(*goStrings))
+	}
+
+	goAliases := flags["--go-aliases"]
+	if goAliases != nil && *goAliases != "named" && *goAliases != "alias" {
+		return 1, errors.New("Invalid --go-aliases: " + quote(*goAliases))
+	}
+
+	goEnums := flags["--go-enums"]
+	if goEnums != nil && *goEnums != "preserve" && *goEnums != "strict" {
+		return 1, errors.New("Invalid --go-enums: " + quote(*goEnums))
+	}
+
+	// Generate Go code
+	if flags["--go"] != nil {
+		code, err := golang.Compile(parsed, golang.Options{
+			StringViews: goStrings != nil && *goStrings == "bytes",
+			TypeAliases: goAliases != nil && *goAliases == "alias",
+			StrictEnums: goEnums != nil && *goEnums == "strict",
+		})
+		if err != nil {
+			return 1, err
+		}
+		if err := writeFile(*flags["--go"], []byte(code)); err != nil {
+			return 1, err
+		}
+	}
+
+	// Generate a binary schema file
+	if flags["--binary"] != nil {
+		if err := writeFile(*flags["--binary"], schema.EncodeBinary(parsed)); err != nil {
+			return 1, err
+		}
+	}
+
+	// Generate a textual schema file
+	if flags["--text"] != nil {
+		if err := writeFile(*flags["--text"], []byte(schema.Format(parsed))); err != nil {
+			return 1, err
+		}
+	}
+
+	// Convert a binary file to JSON
+	if flags["--to-json"] != nil {
+		if rootType == nil {
+			return 1, errors.New("Missing flag --root-type when using --to-json")
+		}
+		data, err := ioutil.ReadFile(*flags["--to-json"])
+		if err != nil {
+			return 1, err
+		}
+		text, err := peechyjson.ToJSON(parsed, *rootType, data)
+		if err != nil {
+			return 1, err
+		}
+		if err := writeFile(*flags["--to-json"]+".json", text); err != nil {
+			return 1, err
+		}
+	}
+
+	// Convert a JSON file to binary
+	if flags["--from-json"] != nil {
+		if rootType == nil {
+			return 1, errors.New("Missing flag --root-type when using --from-json")
+		}
+		text, err := ioutil.ReadFile(*flags["--from-json"])
+		if err != nil {
+			return 1, err
+		}
+		data, err := peechyjson.FromJSON(parsed, *rootType, text)
+		if err != nil {
+			return 1, err
+		}
+		if err := writeFile(*flags["--from-json"]+".bin", data); err != nil {
+			return 1, err
+		}
+	}
+
+	return 0, nil
+}
+
+func main() {
+	code, err := run(os.Args[1:], os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(code)
+}
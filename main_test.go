@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSchema(t *testing.T, text string) string {
+	path := filepath.Join(t.TempDir(), "test.kiwi")
+	if err := ioutil.WriteFile(path, []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunUsage(t *testing.T) {
+	for _, args := range [][]string{nil, {"--help"}, {"-h"}, {"schema.kiwi"}, {"--go", "out.go"}} {
+		var stdout bytes.Buffer
+		code, err := run(args, &stdout)
+		if code != 1 || err != nil {
+			t.Errorf("%q: expected 1, got %d, %v", args, code, err)
+		}
+		if stdout.String() != usage+"\n" {
+			t.Errorf("%q: expected the usage, got %q", args, stdout.String())
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	path := writeSchema(t, "struct Point {\n  float x;\n  flaot y;\n}\n")
+	valid := writeSchema(t, "struct Point { float x; }\nenum Kind { A = 1; }\n")
+
+	for _, test := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"--schema"}, `Missing value for "--schema" (use "--help" for usage)`},
+		{[]string{"--jsx", "out.js"}, `Unknown flag "--jsx" (use "--help" for usage)`},
+		{[]string{"--ts"}, `Missing value for "--ts" (use "--help" for usage)`},
+		{[]string{"--schema", valid, "--go", "out.go", "--js", "out.js"}, `Unsupported flag "--js" (use js/cli.ts for other languages)`},
+		{[]string{"--schema", valid, "--ts", "out.ts"}, `Unsupported flag "--ts" (use js/cli.ts for other languages)`},
+		{[]string{"--schema", path}, path + ":3:9: error: The type \"flaot\" is not defined for field \"y\"\n  flaot y;\n        ^"},
+		{[]string{"--schema", valid, "--root-type", "Kind"}, `Invalid root type: "Kind"`},
+		{[]string{"--schema", valid, "--go-strings", "views"}, `Invalid --go-strings: "views"`},
+		{[]string{"--schema", valid, "--go-aliases", "type"}, `Invalid --go-aliases: "type"`},
+		{[]string{"--schema", valid, "--go-enums", "lax"}, `Invalid --go-enums: "lax"`},
+		{[]string{"--schema", valid, "--to-json", "x.bin"}, "Missing flag --root-type when using --to-json"},
+		{[]string{"--schema", valid, "--from-json", "x.json"}, "Missing flag --root-type when using --from-json"},
+	} {
+		code, err := run(test.args, ioutil.Discard)
+		if code != 1 || err == nil || err.Error() != test.err {
+			t.Errorf("%q: expected %q, got %d, %v", test.args, test.err, code, err)
+		}
+	}
+}
+
+func TestRunGo(t *testing.T) {
+	want, err := ioutil.ReadFile("test/gostrict/schema.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(t.TempDir(), "schema.go")
+	code, err := run([]string{"--schema", "test/test-go-strict.kiwi", "--go", out, "--go-enums", "strict"}, ioutil.Discard)
+	if code != 0 || err != nil {
+		t.Fatalf("expected 0, got %d, %v", code, err)
+	}
+
+	got, err := ioutil.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("generated code differs from test/gostrict/schema.go")
+	}
+}
+
+func TestRunText(t *testing.T) {
+	path := writeSchema(t, "package test; struct Point { float x; float y; }")
+	out := filepath.Join(t.TempDir(), "test.kiwi")
+	code, err := run([]string{"--schema", path, "--text", out}, ioutil.Discard)
+	if code != 0 || err != nil {
+		t.Fatalf("expected 0, got %d, %v", code, err)
+	}
+
+	got, err := ioutil.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package test;\n\nstruct Point {\n  float x;\n  float y;\n}\n"
+	if string(got) != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestRunSchemaNotFound(t *testing.T) {
+	code, err := run([]string{"--schema", "missing.kiwi"}, ioutil.Discard)
+	if code != 1 || err == nil || !strings.Contains(err.Error(), "missing.kiwi") {
+		t.Errorf("expected an error about missing.kiwi, got %d, %v", code, err)
+	}
+}
+
+// The same round trip as test.sh does with cli.js.
+func TestRunBinary(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "test-schema.bkiwi")
+	text := filepath.Join(dir, "test-schema-round-trip.kiwi")
+
+	if code, err := run([]string{"--schema", "test/test-schema.kiwi", "--binary", binary}, ioutil.Discard); code != 0 || err != nil {
+		t.Fatalf("expected 0, got %d, %v", code, err)
+	}
+	if code, err := run([]string{"--schema", binary, "--text", text}, ioutil.Discard); code != 0 || err != nil {
+		t.Fatalf("expected 0, got %d, %v", code, err)
+	}
+
+	got, err := ioutil.ReadFile(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ioutil.ReadFile("test/test-schema-round-trip.kiwi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	path := writeSchema(t, "enum Suit { Clubs = 1; }\nmessage Card { Suit suit = 1; uint64 id = 2; byte[] data = 3; }\n")
+	input := filepath.Join(t.TempDir(), "card.json")
+	want := "{\n  \"data\": \"AQI=\",\n  \"id\": \"18446744073709551615\",\n  \"suit\": \"Clubs\"\n}\n"
+	if err := ioutil.WriteFile(input, []byte(want), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if code, err := run([]string{"--schema", path, "--root-type", "Card", "--from-json", input}, ioutil.Discard); code != 0 || err != nil {
+		t.Fatalf("expected 0, got %d, %v", code, err)
+	}
+	if code, err := run([]string{"--schema", path, "--root-type", "Card", "--to-json", input + ".bin"}, ioutil.Discard); code != 0 || err != nil {
+		t.Fatalf("expected 0, got %d, %v", code, err)
+	}
+
+	got, err := ioutil.ReadFile(input + ".bin.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,139 @@
+// Package message lets code handle generated types without knowing them, by
+// name or by ID. Every struct and message in generated code implements
+// Message. Those of schemas with a package register themselves here when
+// their Go package is imported, and those of schemas without one when its
+// Register function is called.
+package message
+
+import (
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"sync"
+
+	"github.com/jarred-sumner/peechy/buffer"
+)
+
+// Message is implemented by the structs and messages in generated code.
+type Message interface {
+	Encode(buf buffer.Writer) error
+	// Decode decodes into the message, the same as DecodeXInto.
+	Decode(buf buffer.Reader) error
+	// TypeName is the name of the type in the schema, after the schema's
+	// package and a dot if it has one, like "game.Player".
+	TypeName() string
+	// TypeID is ID(TypeName()), for framing code to tell types apart.
+	TypeID() uint32
+}
+
+// ID returns the TypeID of the type called name, the CRC-32 (IEEE) of name.
+func ID(name string) uint32 {
+	return crc32.ChecksumIEEE([]byte(name))
+}
+
+var (
+	mu     sync.RWMutex
+	byName = map[string]func() Message{}
+	byID   = map[uint32]func() Message{}
+)
+
+// Register adds the type of the messages that newMessage returns. Generated
+// code calls it from init for schemas with a package, and from its own
+// Register function for schemas without one. It returns a *RegisterError if
+// a type with the same name or ID is already registered, which happens when
+// two schemas have the same package, or two schemas without one have a type
+// with the same name.
+func Register(newMessage func() Message) error {
+	m := newMessage()
+	name, id := m.TypeName(), m.TypeID()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := byName[name]; ok {
+		return &RegisterError{Name: name, ID: id, Registered: name}
+	}
+	if registered := byID[id]; registered != nil {
+		return &RegisterError{Name: name, ID: id, Registered: registered().TypeName()}
+	}
+	byName[name] = newMessage
+	byID[id] = newMessage
+	return nil
+}
+
+// New returns a new, empty message of the type called name, or nil if it isn't
+// registered.
+func New(name string) Message {
+	mu.RLock()
+	newMessage := byName[name]
+	mu.RUnlock()
+
+	if newMessage == nil {
+		return nil
+	}
+	return newMessage()
+}
+
+// NewByID returns a new, empty message of the type with the given ID, or nil if
+// it isn't registered.
+func NewByID(id uint32) Message {
+	mu.RLock()
+	newMessage := byID[id]
+	mu.RUnlock()
+
+	if newMessage == nil {
+		return nil
+	}
+	return newMessage()
+}
+
+// Decode decodes a message of the type with the given ID.
+func Decode(id uint32, buf buffer.Reader) (Message, error) {
+	m := NewByID(id)
+	if m == nil {
+		return nil, &UnknownIDError{ID: id}
+	}
+	if err := m.Decode(buf); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Names returns the names of every registered type, sorted.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UnknownIDError is returned by Decode for an ID that no type is registered
+// with.
+type UnknownIDError struct {
+	ID uint32
+}
+
+func (e *UnknownIDError) Error() string {
+	return fmt.Sprintf("peechy: no type is registered with ID %d", e.ID)
+}
+
+// RegisterError is returned by Register for a type whose name or ID is taken.
+type RegisterError struct {
+	Name string
+	ID   uint32
+	// Registered is the name of the type already registered, which is Name
+	// unless only the IDs are the same.
+	Registered string
+}
+
+func (e *RegisterError) Error() string {
+	if e.Registered == e.Name {
+		return "peechy: " + e.Name + " is registered twice"
+	}
+	return fmt.Sprintf("peechy: the ID of %s, %d, is already registered by %s", e.Name, e.ID, e.Registered)
+}
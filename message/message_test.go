@@ -0,0 +1,93 @@
+package message_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jarred-sumner/peechy/buffer"
+	"github.com/jarred-sumner/peechy/message"
+)
+
+type ping struct {
+	Seq uint32
+}
+
+func (p *ping) Encode(buf buffer.Writer) error {
+	buf.WriteUint32(p.Seq)
+	return nil
+}
+
+func (p *ping) Decode(buf buffer.Reader) error {
+	p.Seq = buf.ReadUint32()
+	return buf.Err()
+}
+
+func (*ping) TypeName() string { return "test.Ping" }
+func (*ping) TypeID() uint32   { return message.ID("test.Ping") }
+
+func init() {
+	if err := message.Register(func() message.Message { return new(ping) }); err != nil {
+		panic(err)
+	}
+}
+
+func TestMessageRegistry(t *testing.T) {
+	if _, ok := message.New("test.Ping").(*ping); !ok {
+		t.Fatal("Expected New to make a *ping")
+	}
+	if _, ok := message.NewByID(message.ID("test.Ping")).(*ping); !ok {
+		t.Fatal("Expected NewByID to make a *ping")
+	}
+	if message.New("test.Pong") != nil || message.NewByID(1) != nil {
+		t.Fatal("Expected nil for types that aren't registered")
+	}
+
+	names := message.Names()
+	if len(names) != 1 || names[0] != "test.Ping" {
+		t.Fatalf("Expected [test.Ping], got %v", names)
+	}
+}
+
+func TestMessageDecode(t *testing.T) {
+	buf := buffer.FromBytes(nil)
+	(&ping{Seq: 7}).Encode(buf)
+
+	m, err := message.Decode(message.ID("test.Ping"), buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := m.(*ping); !ok || p.Seq != 7 {
+		t.Fatalf("Expected ping 7, got %#v", m)
+	}
+
+	var unknown *message.UnknownIDError
+	if _, err := message.Decode(1, buf); !errors.As(err, &unknown) || unknown.ID != 1 {
+		t.Fatalf("Expected an UnknownIDError, got %v", err)
+	}
+
+	if _, err := message.Decode(message.ID("test.Ping"), buffer.FromBytes([]byte{1})); err == nil {
+		t.Fatal("Expected the error from Decode")
+	}
+}
+
+type pingID struct{ ping }
+
+func (*pingID) TypeName() string { return "test.PingID" }
+
+func TestMessageRegisterTwice(t *testing.T) {
+	var e *message.RegisterError
+	err := message.Register(func() message.Message { return new(ping) })
+	if !errors.As(err, &e) || err.Error() != "peechy: test.Ping is registered twice" {
+		t.Fatalf("Expected a RegisterError, got %v", err)
+	}
+
+	// The ID alone is enough to clash, since frames only have the ID.
+	err = message.Register(func() message.Message { return new(pingID) })
+	if want := fmt.Sprintf("peechy: the ID of test.PingID, %d, is already registered by test.Ping", message.ID("test.Ping")); err == nil || err.Error() != want {
+		t.Fatalf("Expected %q, got %v", want, err)
+	}
+	if message.New("test.PingID") != nil {
+		t.Fatal("Expected a type that failed to register not to be registered")
+	}
+}
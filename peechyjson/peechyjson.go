@@ -0,0 +1,113 @@
+// Package peechyjson converts between peechy data and JSON with a schema read
+// at runtime, like --to-json and --from-json in the CLI.
+//
+// The JSON has the shape of the values in the dynamic package, with a few
+// changes so that nothing is lost on the way:
+//
+//   - 64-bit integers are strings, since JSON numbers past 2^53 lose precision
+//     in JavaScript.
+//   - Byte arrays are base64 strings.
+//   - NaN and infinities are the strings "NaN", "Infinity" and "-Infinity".
+//
+// FromJSON takes the same JSON back, as well as plain numbers for 64-bit
+// integers and arrays of numbers for byte arrays.
+package peechyjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/jarred-sumner/peechy/buffer"
+	"github.com/jarred-sumner/peechy/dynamic"
+	"github.com/jarred-sumner/peechy/schema"
+)
+
+// ToJSON decodes data as the struct, message or union called root in s and
+// returns it as JSON, indented the same as JSON.stringify(value, null, 2) with
+// a trailing newline.
+func ToJSON(s *schema.Schema, root string, data []byte) ([]byte, error) {
+	codec, err := dynamic.New(s)
+	if err != nil {
+		return nil, err
+	}
+	value, err := codec.Decode(root, buffer.FromBytes(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(toJSONValue(value)); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// FromJSON encodes the JSON in data as the struct, message or union called
+// root in s.
+func FromJSON(s *schema.Schema, root string, data []byte) ([]byte, error) {
+	codec, err := dynamic.New(s)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("peechyjson: unexpected data after the JSON value")
+	}
+
+	buf := buffer.FromBytes(nil)
+	if err := codec.Encode(root, buf, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// toJSONValue replaces the values from dynamic that JSON can't hold exactly.
+// []byte is left alone, since encoding/json already writes it as base64.
+func toJSONValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			value[key] = toJSONValue(field)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = toJSONValue(item)
+		}
+	case int64:
+		return strconv.FormatInt(value, 10)
+	case uint64:
+		return strconv.FormatUint(value, 10)
+	case float32:
+		return nonFinite(float64(value), value)
+	case float64:
+		return nonFinite(value, value)
+	}
+	return value
+}
+
+// nonFinite returns NaN and infinities as strings that strconv.ParseFloat
+// reads back, and value otherwise.
+func nonFinite(f float64, value interface{}) interface{} {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return value
+}
@@ -0,0 +1,125 @@
+package peechyjson_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jarred-sumner/peechy/peechyjson"
+	"github.com/jarred-sumner/peechy/schema"
+)
+
+const testSchema = `
+enum Suit {
+  Clubs = 1;
+  Hearts = 2;
+}
+
+struct Point {
+  int x;
+  int y;
+}
+
+message Label {
+  string text = 1;
+}
+
+union Event = Point | Label {
+  kind;
+}
+
+message Packet {
+  int64 id = 1;
+  uint64 sequence = 2;
+  varint64[] offsets = 3;
+  byte[] payload = 4;
+  Suit suit = 5;
+  Event event = 6;
+  float64 score = 7;
+  float32[] weights = 8;
+}
+`
+
+func parse(t *testing.T) *schema.Schema {
+	s, err := schema.Parse(testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestRoundTrip(t *testing.T) {
+	s := parse(t)
+	want := `{
+  "event": {
+    "kind": "Label",
+    "text": "<hi>"
+  },
+  "id": "-9223372036854775808",
+  "offsets": [
+    "9007199254740993",
+    "-1"
+  ],
+  "payload": "AAH/",
+  "score": "NaN",
+  "sequence": "18446744073709551615",
+  "suit": "Hearts",
+  "weights": [
+    0.1,
+    "-Infinity"
+  ]
+}
+`
+
+	data, err := peechyjson.FromJSON(s, "Packet", []byte(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := peechyjson.ToJSON(s, "Packet", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+// JSON from JavaScript has numbers where ToJSON writes strings.
+func TestFromJSONNumbers(t *testing.T) {
+	s := parse(t)
+	a, err := peechyjson.FromJSON(s, "Packet", []byte(`{"id": 5, "payload": [0, 1, 255], "suit": 2, "event": {"kind": 1, "x": 1, "y": 2}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := peechyjson.FromJSON(s, "Packet", []byte(`{"id": "5", "payload": "AAH/", "suit": "Hearts", "event": {"kind": "Point", "x": 1, "y": 2}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a, b) {
+		t.Errorf("expected %v to equal %v", a, b)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	s := parse(t)
+
+	for _, test := range []struct {
+		json string
+		err  string
+	}{
+		{`{"id": 1} {}`, "peechyjson: unexpected data after the JSON value"},
+		{`{"id": `, "unexpected EOF"},
+		{`{"payload": "!"}`, "peechy: Packet.payload: expected a base64 string: illegal base64 data at input byte 0"},
+		{`{"sequence": "-1"}`, "peechy: Packet.sequence: -1 is out of range for an unsigned 64-bit integer"},
+	} {
+		if _, err := peechyjson.FromJSON(s, "Packet", []byte(test.json)); err == nil || err.Error() != test.err {
+			t.Errorf("%s: expected %q, got %v", test.json, test.err, err)
+		}
+	}
+
+	if _, err := peechyjson.ToJSON(s, "Packet", []byte{1, 2}); err == nil {
+		t.Error("expected an error for truncated data")
+	}
+	if _, err := peechyjson.ToJSON(s, "Suit", nil); err == nil || err.Error() != `peechy: invalid root type "Suit"` {
+		t.Errorf("expected an invalid root type, got %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+package schema
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/jarred-sumner/peechy/buffer"
+)
+
+// binaryTypes are the built-in types in a binary schema, which stores them as
+// the bitwise complement of their index here. Definitions are stored as their
+// own index.
+var binaryTypes = []string{
+	"bool",
+	"byte",
+	"float",
+	"int",
+	"uint8",
+	"uint16",
+	"uint32",
+	"int8",
+	"int16",
+	"int32",
+	"float32",
+	"string",
+	"uint",
+	"int64",
+	"uint64",
+	"float64",
+	"varint64",
+	"varuint64",
+	"lowp",
+	"alphanumeric",
+	"discriminator",
+}
+
+var binaryKinds = []Kind{
+	Enum,
+	Struct,
+	Message,
+	Union,
+	Smol,
+	Alias,
+}
+
+// EncodeBinary encodes s as a binary schema, the same as encodeBinarySchema in
+// js/binary.ts. Binary schemas don't keep the package, positions, which fields
+// are deprecated or what picks and extensions came from.
+func EncodeBinary(s *Schema) []byte {
+	buf := buffer.FromBytes(nil)
+	definitionIndex := map[string]int{}
+
+	buf.WriteVarUint(uint(len(s.Definitions)))
+
+	for i, definition := range s.Definitions {
+		definitionIndex[definition.Name] = i
+	}
+
+	for _, definition := range s.Definitions {
+		buf.WriteString(definition.Name)
+		buf.WriteByte(byte(indexOfKind(definition.Kind)))
+		buf.WriteVarUint(uint(len(definition.Fields)))
+
+		for _, field := range definition.Fields {
+			// Types that are neither, like the empty type of enum values, are
+			// written as 0 the same as in JavaScript.
+			value := definitionIndex[field.Type]
+			if index := indexOfType(field.Type); index != -1 {
+				value = ^index
+			}
+
+			buf.WriteString(field.Name)
+			buf.WriteVarInt(value)
+			buf.WriteBool(field.IsArray)
+			buf.WriteBool(field.IsRequired)
+			buf.WriteVarUint(uint(field.Value))
+		}
+
+		buf.WriteString(definition.SerializerPath)
+	}
+
+	return buf.Bytes()
+}
+
+// DecodeBinary decodes a binary schema from EncodeBinary or
+// encodeBinarySchema in js/binary.ts.
+func DecodeBinary(data []byte) (*Schema, error) {
+	buf := buffer.FromBytes(data)
+	definitionCount := buf.ReadArrayLength()
+	if err := buf.Err(); err != nil {
+		return nil, err
+	}
+
+	definitions := make([]*Definition, 0, definitionCount)
+	types := make([][]int, 0, definitionCount)
+
+	// Read in the schema
+	for i := uint(0); i < definitionCount; i++ {
+		definitionName := buf.ReadString()
+		kind := buf.ReadUint8()
+		fieldCount := buf.ReadArrayLength()
+		if err := buf.Err(); err != nil {
+			return nil, err
+		}
+		if int(kind) >= len(binaryKinds) {
+			return nil, errors.New("Invalid kind " + strconv.Itoa(int(kind)))
+		}
+
+		fields := make([]Field, 0, fieldCount)
+		fieldTypes := make([]int, 0, fieldCount)
+		for j := uint(0); j < fieldCount; j++ {
+			fieldName := buf.ReadString()
+			fieldTypes = append(fieldTypes, buf.ReadVarInt())
+			isArray := buf.ReadUint8()&1 != 0
+			isRequired := buf.ReadUint8()&1 != 0
+			value := buf.ReadVarUint()
+
+			fields = append(fields, Field{
+				Name:       fieldName,
+				IsArray:    isArray,
+				IsRequired: isRequired,
+				Value:      int(value),
+			})
+		}
+
+		serializerPath := buf.ReadString()
+		if err := buf.Err(); err != nil {
+			return nil, err
+		}
+
+		definitions = append(definitions, &Definition{
+			Name:           definitionName,
+			Kind:           binaryKinds[kind],
+			Fields:         fields,
+			SerializerPath: serializerPath,
+		})
+		types = append(types, fieldTypes)
+	}
+
+	// Bind type names afterwards
+	for i, definition := range definitions {
+		if definition.Kind == Enum || definition.Kind == Smol {
+			continue
+		}
+		for j := range definition.Fields {
+			typ := types[i][j]
+			if typ < 0 {
+				if ^typ >= len(binaryTypes) {
+					return nil, errors.New("Invalid type " + strconv.Itoa(typ))
+				}
+				definition.Fields[j].Type = binaryTypes[^typ]
+			} else {
+				if typ >= len(definitions) {
+					return nil, errors.New("Invalid type " + strconv.Itoa(typ))
+				}
+				definition.Fields[j].Type = definitions[typ].Name
+			}
+		}
+	}
+
+	return &Schema{Definitions: definitions}, nil
+}
+
+func indexOfType(name string) int {
+	for i, typ := range binaryTypes {
+		if typ == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// indexOfKind returns -1 for kinds binary schemas don't have, which is written
+// as 255.
+func indexOfKind(kind Kind) int {
+	for i, k := range binaryKinds {
+		if k == kind {
+			return i
+		}
+	}
+	return -1
+}
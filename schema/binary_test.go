@@ -0,0 +1,107 @@
+package schema_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/jarred-sumner/peechy/schema"
+)
+
+// These come from test/binary-fixtures.js.
+type binaryFixture struct {
+	Name   string         `json:"name"`
+	Text   string         `json:"text"`
+	Schema *schema.Schema `json:"schema"`
+}
+
+func TestBinaryMatchesJS(t *testing.T) {
+	contents, err := ioutil.ReadFile("../test/binary-fixtures.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixtures []binaryFixture
+	if err := json.Unmarshal(contents, &fixtures); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fixture := range fixtures {
+		binary, err := ioutil.ReadFile("../test/" + fixture.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		text, err := ioutil.ReadFile("../test/" + fixture.Text)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parsed, err := schema.Parse(string(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if encoded := schema.EncodeBinary(parsed); !bytes.Equal(encoded, binary) {
+			t.Errorf("%s: encoding %s gives different bytes", fixture.Name, fixture.Text)
+		}
+
+		decoded, err := schema.DecodeBinary(binary)
+		if err != nil {
+			t.Errorf("%s: %v", fixture.Name, err)
+			continue
+		}
+		if !reflect.DeepEqual(decoded, fixture.Schema) {
+			got, _ := json.Marshal(decoded)
+			want, _ := json.Marshal(fixture.Schema)
+			t.Errorf("%s:\n got %s\nwant %s", fixture.Name, got, want)
+		}
+		if encoded := schema.EncodeBinary(decoded); !bytes.Equal(encoded, binary) {
+			t.Errorf("%s: encoding it again gives different bytes", fixture.Name)
+		}
+	}
+}
+
+func TestBinaryTypes(t *testing.T) {
+	parsed, err := schema.Parse(`struct Point { lowp x; alphanumeric name; }
+union Shape = Point {
+  kind;
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := schema.DecodeBinary(schema.EncodeBinary(parsed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text, want := schema.Format(decoded), schema.Format(parsed); text != want {
+		t.Errorf("got\n%s\nwant\n%s", text, want)
+	}
+}
+
+func TestDecodeBinaryErrors(t *testing.T) {
+	valid := schema.EncodeBinary(&schema.Schema{Definitions: []*schema.Definition{
+		{Name: "A", Kind: schema.Struct, Fields: []schema.Field{{Name: "x", Type: "int"}}},
+	}})
+
+	badKind := append([]byte(nil), valid...)
+	badKind[9] = 9
+	badType := append([]byte(nil), valid...)
+	copy(badType[19:], []byte{5, 0, 0, 0})
+
+	for _, test := range []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{"truncated", valid[:len(valid)-1], ""},
+		{"kind", badKind, "Invalid kind 9"},
+		{"type", badType, "Invalid type 5"},
+		{"count", []byte{0xff, 0xff, 0xff, 0xff}, ""},
+	} {
+		_, err := schema.DecodeBinary(test.data)
+		if err == nil || (test.err != "" && err.Error() != test.err) {
+			t.Errorf("%s: expected an error %q, got %v", test.name, test.err, err)
+		}
+	}
+}
@@ -0,0 +1,551 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// NativeTypes are the built-in types fields can have.
+var NativeTypes = []string{
+	"bool",
+	"byte",
+	"float",
+	"int",
+	"uint8",
+	"uint16",
+	"uint32",
+	"int8",
+	"int16",
+	"lowp",
+	"int32",
+	"float32",
+	"string",
+	"uint",
+	"discriminator",
+	"alphanumeric",
+	"int64",
+	"uint64",
+	"float64",
+	"varint64",
+	"varuint64",
+}
+
+// nativeAliasTypes are the built-in types an alias can name, which don't
+// include lowp in parser.ts.
+var nativeAliasTypes = map[string]bool{
+	"bool":          true,
+	"byte":          true,
+	"float":         true,
+	"int":           true,
+	"uint8":         true,
+	"uint16":        true,
+	"uint32":        true,
+	"int8":          true,
+	"int16":         true,
+	"int32":         true,
+	"float32":       true,
+	"string":        true,
+	"uint":          true,
+	"discriminator": true,
+	"alphanumeric":  true,
+	"int64":         true,
+	"uint64":        true,
+	"float64":       true,
+	"varint64":      true,
+	"varuint64":     true,
+}
+
+// reservedNames are names the generated JavaScript uses itself.
+var reservedNames = []string{"ByteBuffer", "package", "Allocator"}
+
+var (
+	tokenRegex      = regexp.MustCompile(`((?:-|\b)\d+\b|[=:;{}]|\[\]|\[deprecated\]|\[!\]|\b[A-Za-z_][A-Za-z0-9_]*\b|"|-|&|\||//.*|\s+)`)
+	whitespace      = regexp.MustCompile(`^//.*|\s+$`)
+	identifier      = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	integer         = regexp.MustCompile(`^-?\d+$`)
+	endOfFile       = regexp.MustCompile(`^$`)
+	equals          = regexp.MustCompile(`^=$`)
+	semicolon       = regexp.MustCompile(`^;$`)
+	colon           = regexp.MustCompile(`^:$`)
+	leftBrace       = regexp.MustCompile(`^\{$`)
+	rightBrace      = regexp.MustCompile(`^\}$`)
+	arrayToken      = regexp.MustCompile(`^\[\]$`)
+	quoteToken      = regexp.MustCompile(`^"$`)
+	deprecatedToken = regexp.MustCompile(`^\[deprecated\]$`)
+	requiredToken   = regexp.MustCompile(`^\[!\]$`)
+	unionOrToken    = regexp.MustCompile(`^\|$`)
+	extendsToken    = regexp.MustCompile(`^&$`)
+
+	packageKeyword    = regexp.MustCompile(`^package$`)
+	serializerKeyword = regexp.MustCompile(`^from$`)
+	enumKeyword       = regexp.MustCompile(`^enum$`)
+	smolKeyword       = regexp.MustCompile(`^smol$`)
+	pickKeyword       = regexp.MustCompile(`^pick$`)
+	structKeyword     = regexp.MustCompile(`^struct$`)
+	messageKeyword    = regexp.MustCompile(`^message$`)
+	entityKeyword     = regexp.MustCompile(`^entity$`)
+	unionKeyword      = regexp.MustCompile(`^union$`)
+	aliasKeyword      = regexp.MustCompile(`^alias$`)
+)
+
+type token struct {
+	text   string
+	line   int
+	column int
+}
+
+// Parse parses and checks a schema. Errors are an *Error with the position of
+// the problem.
+func Parse(src string) (schema *Schema, err error) {
+	// The parser stops at the first error by panicking with it.
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*Error)
+			if !ok {
+				panic(r)
+			}
+			schema, err = nil, e
+		}
+	}()
+
+	schema = parse(tokenize(src))
+	verify(schema)
+	return schema, nil
+}
+
+func fail(message string, line, column int) {
+	panic(&Error{Message: message, Line: line, Column: column})
+}
+
+// quote quotes text like JSON.stringify, for the same errors as parser.ts.
+func quote(text string) string {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(text)
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+func tokenize(text string) []token {
+	var tokens []token
+	line, column := 0, 0
+
+	// track moves the position past part.
+	track := func(part string) {
+		lines := strings.Split(part, "\n")
+		if len(lines) > 1 {
+			column = 0
+		}
+		line += len(lines) - 1
+		column += utf8.RuneCountInString(lines[len(lines)-1])
+	}
+
+	end := 0
+	for _, match := range tokenRegex.FindAllStringIndex(text, -1) {
+		if match[0] > end {
+			fail("Syntax error "+quote(text[end:match[0]]), line+1, column+1)
+		}
+
+		part := text[match[0]:match[1]]
+		if !whitespace.MatchString(part) {
+			tokens = append(tokens, token{text: part, line: line + 1, column: column + 1})
+		}
+		track(part)
+		end = match[1]
+	}
+	if end < len(text) {
+		fail("Syntax error "+quote(text[end:]), line+1, column+1)
+	}
+
+	// End-of-file token
+	return append(tokens, token{line: line, column: column})
+}
+
+type pick struct {
+	to, from   token
+	fieldNames []string
+}
+
+func parse(tokens []token) *Schema {
+	index := 0
+	current := func() token {
+		return tokens[index]
+	}
+	eat := func(test *regexp.Regexp) bool {
+		if test.MatchString(current().text) {
+			index++
+			return true
+		}
+		return false
+	}
+	expect := func(test *regexp.Regexp, expected string) {
+		if !eat(test) {
+			t := current()
+			fail("Expected "+expected+" but found "+quote(t.text), t.line, t.column)
+		}
+	}
+
+	schema := &Schema{Definitions: []*Definition{}}
+	picks := map[string]*pick{}
+	var pickOrder []string
+
+	if eat(packageKeyword) {
+		schema.Package = current().text
+		expect(identifier, "identifier")
+		expect(semicolon, `";"`)
+	}
+
+	for index < len(tokens) && !eat(endOfFile) {
+		fields := []Field{}
+		var extensions []string
+		var serializerPath string
+		var kind Kind
+
+		switch {
+		case eat(enumKeyword):
+			kind = Enum
+		case eat(smolKeyword):
+			kind = Smol
+		case eat(pickKeyword):
+			kind = Pick
+		case eat(structKeyword):
+			kind = Struct
+		case eat(messageKeyword):
+			kind = Message
+		case eat(entityKeyword):
+			kind = Entity
+		case eat(unionKeyword):
+			kind = Union
+		case eat(aliasKeyword):
+			kind = Alias
+		default:
+			t := current()
+			fail("Unexpected token "+quote(t.text), t.line, t.column)
+		}
+
+		// All definitions start off the same except union
+		name := current()
+		expect(identifier, "identifier")
+
+		switch kind {
+		case Pick:
+			expect(colon, `":"`)
+			from := current()
+			expect(identifier, "identifier")
+			expect(leftBrace, `"{"`)
+
+			p := &pick{to: name, from: from}
+			if _, ok := picks[name.text]; !ok {
+				pickOrder = append(pickOrder, name.text)
+			}
+			picks[name.text] = p
+
+			for !eat(rightBrace) {
+				field := current()
+				expect(identifier, "identifier")
+				for _, fieldName := range p.fieldNames {
+					if fieldName == field.text {
+						fail("Fields must be unique", field.line, field.column)
+					}
+				}
+				p.fieldNames = append(p.fieldNames, field.text)
+				expect(semicolon, ";")
+			}
+			continue
+
+		case Union:
+			expect(equals, `"="`)
+
+			member := func() {
+				field := current()
+				expect(identifier, "identifier")
+				fields = append(fields, Field{
+					Name:       field.text,
+					Line:       field.line,
+					Column:     field.column,
+					Type:       field.text,
+					IsRequired: true,
+					Value:      len(fields) + 1,
+				})
+			}
+			member()
+			for eat(unionOrToken) {
+				member()
+			}
+
+			if eat(leftBrace) {
+				field := current()
+				expect(identifier, "discriminator name")
+				fields = append([]Field{{
+					Name:       field.text,
+					Line:       field.line,
+					Column:     field.column,
+					Type:       "discriminator",
+					IsRequired: true,
+				}}, fields...)
+				expect(semicolon, ";")
+				expect(rightBrace, "}")
+			} else {
+				expect(semicolon, `";"`)
+			}
+
+		case Alias:
+			expect(equals, "=")
+			field := current()
+			expect(identifier, "identifier")
+			fields = append(fields, Field{
+				Name:       field.text,
+				Line:       field.line,
+				Column:     field.column,
+				Type:       field.text,
+				IsRequired: true,
+				Value:      1,
+			})
+			expect(semicolon, ";")
+
+		default:
+			if kind == Struct {
+				for eat(extendsToken) {
+					field := current()
+					expect(identifier, "discriminator name")
+					extensions = append(extensions, field.text)
+				}
+			}
+
+			if eat(serializerKeyword) {
+				expect(quoteToken, `"`)
+				for !eat(quoteToken) {
+					if index == len(tokens)-1 {
+						expect(quoteToken, `"`)
+					}
+					serializerPath += current().text
+					index++
+				}
+			}
+
+			expect(leftBrace, `"{"`)
+
+			// Parse fields
+			for !eat(rightBrace) {
+				var typ string
+				isArray := false
+
+				// Enums don't have types
+				if kind != Enum && kind != Smol {
+					typ = current().text
+					expect(identifier, "identifier")
+					isArray = eat(arrayToken)
+				}
+
+				field := current()
+				expect(identifier, "identifier")
+
+				// Structs don't have explicit values
+				value := len(fields) + 1
+				isRequired := kind == Struct
+				if kind != Struct {
+					expect(equals, `"="`)
+					t := current()
+					expect(integer, "integer")
+
+					if eat(requiredToken) {
+						isRequired = true
+					}
+
+					n, err := strconv.ParseInt(t.text, 10, 32)
+					if err != nil || strconv.FormatInt(n, 10) != t.text {
+						fail("Invalid integer "+quote(t.text), t.line, t.column)
+					}
+					value = int(n)
+				}
+
+				isDeprecated := false
+				deprecated := current()
+				if eat(deprecatedToken) {
+					if kind != Message {
+						fail("Cannot deprecate this field", deprecated.line, deprecated.column)
+					}
+					isDeprecated = true
+				}
+
+				expect(semicolon, `";"`)
+
+				fields = append(fields, Field{
+					Name:         field.text,
+					Line:         field.line,
+					Column:       field.column,
+					Type:         typ,
+					IsRequired:   isRequired,
+					IsArray:      isArray,
+					IsDeprecated: isDeprecated,
+					Value:        value,
+				})
+			}
+		}
+
+		definition := &Definition{
+			Name:       name.text,
+			Line:       name.line,
+			Column:     name.column,
+			Kind:       kind,
+			Fields:     fields,
+			Extensions: extensions,
+		}
+		if strings.TrimSpace(serializerPath) != "" {
+			definition.SerializerPath = serializerPath
+		}
+		schema.Definitions = append(schema.Definitions, definition)
+	}
+
+	for _, definition := range schema.Definitions {
+		for _, extension := range definition.Extensions {
+			other := schema.Definition(extension)
+			if other == nil || other.Kind != Struct {
+				fail("Expected "+extension+" to to be a struct", definition.Line, definition.Column)
+			}
+
+			offset := len(definition.Fields)
+			for _, field := range other.Fields {
+				field.Value += offset
+				definition.Fields = append(definition.Fields, field)
+			}
+		}
+	}
+
+	for _, name := range pickOrder {
+		p := picks[name]
+		from := schema.Definition(p.from.text)
+		if from == nil {
+			fail("Expected type for part to exist", p.from.line, p.from.column)
+		}
+
+		fields := make([]Field, len(p.fieldNames))
+		for i, fieldName := range p.fieldNames {
+			field := from.Field(fieldName)
+			if field == nil {
+				fail("Expected field "+fieldName+" to exist in "+from.Name, p.from.line, p.from.column)
+			}
+
+			fields[i] = *field
+			fields[i].IsRequired = true
+			fields[i].Value = i + 1
+		}
+
+		schema.Definitions = append(schema.Definitions, &Definition{
+			Name:     p.to.text,
+			Line:     p.from.line,
+			Column:   p.from.column,
+			Kind:     Struct,
+			Fields:   fields,
+			PickFrom: from.Name,
+		})
+	}
+
+	return schema
+}
+
+func verify(schema *Schema) {
+	definedTypes := map[string]bool{}
+	for _, name := range NativeTypes {
+		definedTypes[name] = true
+	}
+	definitions := map[string]*Definition{}
+
+	// Define definitions
+	for _, definition := range schema.Definitions {
+		if definedTypes[definition.Name] {
+			fail("The type "+quote(definition.Name)+" is defined twice", definition.Line, definition.Column)
+		}
+		for _, reserved := range reservedNames {
+			if definition.Name == reserved {
+				fail("The type name "+quote(definition.Name)+" is reserved", definition.Line, definition.Column)
+			}
+		}
+		definedTypes[definition.Name] = true
+		definitions[definition.Name] = definition
+	}
+
+	// Check fields
+	for _, definition := range schema.Definitions {
+		fields := definition.Fields
+		if definition.Kind == Enum || definition.Kind == Smol || len(fields) == 0 {
+			continue
+		}
+
+		// Check types
+		switch definition.Kind {
+		case Union:
+			seen := map[string]bool{}
+			for _, field := range fields {
+				if seen[field.Name] {
+					fail("The type "+quote(field.Type)+" can only appear in  "+quote(definition.Name)+" once.", field.Line, field.Column)
+				}
+				seen[field.Name] = true
+
+				if !definedTypes[field.Type] {
+					fail("The type "+quote(field.Type)+" is not defined for union "+quote(definition.Name), field.Line, field.Column)
+				}
+			}
+
+		case Alias:
+			field := fields[0]
+			if definitions[field.Name] == nil && !nativeAliasTypes[field.Name] {
+				fail("Expected type used in alias to exist.", definition.Line, definition.Column)
+			}
+
+		default:
+			for _, field := range fields {
+				if !definedTypes[field.Type] {
+					fail("The type "+quote(field.Type)+" is not defined for field "+quote(field.Name), field.Line, field.Column)
+				}
+				if field.Type == "discriminator" {
+					fail("discriminator is only available inside of unions.", field.Line, field.Column)
+				}
+			}
+		}
+
+		// Check values
+		values := map[int]bool{}
+		for _, field := range fields {
+			if values[field.Value] {
+				fail("The id for field "+quote(field.Name)+" is used twice", field.Line, field.Column)
+			}
+			if field.Value <= 0 && field.Type != "discriminator" {
+				fail("The id for field "+quote(field.Name)+" must be positive", field.Line, field.Column)
+			}
+			if field.Value > len(fields) {
+				fail("The id for field "+quote(field.Name)+" cannot be larger than "+strconv.Itoa(len(fields)), field.Line, field.Column)
+			}
+			values[field.Value] = true
+		}
+	}
+
+	// Check that structs don't contain themselves
+	state := map[string]int{}
+	var check func(name string)
+	check = func(name string) {
+		definition := definitions[name]
+		if definition == nil || definition.Kind != Struct {
+			return
+		}
+		if state[name] == 1 {
+			fail("Recursive nesting of "+quote(name)+" is not allowed", definition.Line, definition.Column)
+		}
+		if state[name] != 2 {
+			state[name] = 1
+			for _, field := range definition.Fields {
+				if !field.IsArray {
+					check(field.Type)
+				}
+			}
+			state[name] = 2
+		}
+	}
+	for _, definition := range schema.Definitions {
+		check(definition.Name)
+	}
+}
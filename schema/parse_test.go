@@ -0,0 +1,120 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/jarred-sumner/peechy/schema"
+)
+
+// These come from test/schema-fixtures.js.
+type parseFixture struct {
+	Name   string         `json:"name"`
+	Text   string         `json:"text"`
+	Schema *schema.Schema `json:"schema"`
+	Error  *schema.Error  `json:"error"`
+}
+
+func readParseFixtures(t *testing.T) []parseFixture {
+	contents, err := ioutil.ReadFile("../test/schema-fixtures.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fixtures []parseFixture
+	if err := json.Unmarshal(contents, &fixtures); err != nil {
+		t.Fatal(err)
+	}
+	return fixtures
+}
+
+func TestParseMatchesJS(t *testing.T) {
+	for _, fixture := range readParseFixtures(t) {
+		parsed, err := schema.Parse(fixture.Text)
+
+		if fixture.Error != nil {
+			var got *schema.Error
+			if !errors.As(err, &got) || *got != *fixture.Error {
+				t.Errorf("%s: expected %v, got %v", fixture.Name, fixture.Error, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: %v", fixture.Name, err)
+			continue
+		}
+		if !reflect.DeepEqual(parsed, fixture.Schema) {
+			got, _ := json.Marshal(parsed)
+			want, _ := json.Marshal(fixture.Schema)
+			t.Errorf("%s:\n got %s\nwant %s", fixture.Name, got, want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	parsed, err := schema.Parse(`package game;
+
+struct Point { float x; float y; }
+struct Point3 & Point { float z; }
+
+message Player {
+  string name = 1 [!];
+  Point[] path = 2;
+  uint age = 3 [deprecated];
+}
+
+pick Name : Player { name; }
+
+union Shape = Point | Point3 {
+  kind;
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.Package != "game" {
+		t.Errorf("Expected package game, got %q", parsed.Package)
+	}
+
+	point3 := parsed.Definition("Point3")
+	if point3 == nil || len(point3.Fields) != 3 || point3.Fields[1].Name != "x" || point3.Fields[1].Value != 2 {
+		t.Errorf("Expected Point3 to have z, x and y, got %+v", point3)
+	}
+
+	player := parsed.Definition("Player")
+	if name := player.Field("name"); !name.IsRequired || name.Value != 1 {
+		t.Errorf("Expected a required name field, got %+v", name)
+	}
+	if path := player.Field("path"); !path.IsArray || path.Type != "Point" {
+		t.Errorf("Expected a Point[] path field, got %+v", path)
+	}
+	if age := player.Field("age"); !age.IsDeprecated {
+		t.Errorf("Expected a deprecated age field, got %+v", age)
+	}
+
+	pick := parsed.Definition("Name")
+	if pick.Kind != schema.Struct || pick.PickFrom != "Player" || len(pick.Fields) != 1 {
+		t.Errorf("Expected a struct picked from Player, got %+v", pick)
+	}
+
+	shape := parsed.Definition("Shape")
+	if shape.Kind != schema.Union || shape.Fields[0].Type != "discriminator" || shape.Fields[0].Name != "kind" || shape.Fields[2].Value != 2 {
+		t.Errorf("Expected a union with a kind discriminator, got %+v", shape)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	_, err := schema.Parse("struct Foo {\n  int x;\n  Bar y;\n}")
+	if err == nil || err.Error() != `3:7: The type "Bar" is not defined for field "y"` {
+		t.Fatalf("Expected an error at 3:7, got %v", err)
+	}
+
+	if _, err := schema.Parse(`struct Foo from "foo`); err == nil {
+		t.Fatal("Expected an error for an unterminated path")
+	}
+}
@@ -0,0 +1,73 @@
+package schema
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Format prints s as schema text, the same as prettyPrintSchema in
+// js/printer.ts. Picks are printed as the structs they become.
+func Format(s *Schema) string {
+	var text strings.Builder
+
+	if s.Package != "" {
+		text.WriteString("package " + s.Package + ";\n")
+	}
+
+	for i, definition := range s.Definitions {
+		if i > 0 || s.Package != "" {
+			text.WriteString("\n")
+		}
+		kind := strings.ToLower(string(definition.Kind))
+
+		switch definition.Kind {
+		case Union:
+			discriminatorIndex := -1
+			text.WriteString(kind + " " + definition.Name + " = ")
+			for j, field := range definition.Fields {
+				if field.Value == 0 {
+					discriminatorIndex = j
+					continue
+				}
+				text.WriteString(field.Name)
+				if j < len(definition.Fields)-1 {
+					text.WriteString(" | ")
+				}
+			}
+			if discriminatorIndex > -1 {
+				text.WriteString(" {\n")
+				text.WriteString("  " + definition.Fields[discriminatorIndex].Name + ";\n")
+				text.WriteString("}\n")
+			} else {
+				text.WriteString(";\n")
+			}
+
+		case Alias:
+			text.WriteString(kind + " " + definition.Name + " = " + definition.Fields[0].Name + ";\n")
+
+		default:
+			text.WriteString(kind + " " + definition.Name + " {\n")
+			for _, field := range definition.Fields {
+				text.WriteString("  ")
+				if definition.Kind != Enum && definition.Kind != Smol {
+					text.WriteString(field.Type)
+					if field.IsArray {
+						text.WriteString("[]")
+					}
+					text.WriteString(" ")
+				}
+				text.WriteString(field.Name)
+				if definition.Kind != Struct {
+					text.WriteString(" = " + strconv.Itoa(field.Value))
+				}
+				if field.IsDeprecated {
+					text.WriteString(" [deprecated]")
+				}
+				text.WriteString(";\n")
+			}
+			text.WriteString("}\n")
+		}
+	}
+
+	return text.String()
+}
@@ -0,0 +1,66 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/jarred-sumner/peechy/schema"
+)
+
+func TestFormat(t *testing.T) {
+	parsed, err := schema.Parse(`package game;
+enum Team { RED = 1; BLUE = 2; }
+smol Direction { UP = 1; DOWN = 2; }
+alias PlayerID = uint;
+struct Point { float x; float y; }
+message Player { PlayerID id = 1; Point[] path = 2; int score = 3 [deprecated]; }
+union Shape = Point | Player;
+union Event = Point | Player { kind; }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `package game;
+
+enum Team {
+  RED = 1;
+  BLUE = 2;
+}
+
+smol Direction {
+  UP = 1;
+  DOWN = 2;
+}
+
+alias PlayerID = uint;
+
+struct Point {
+  float x;
+  float y;
+}
+
+message Player {
+  PlayerID id = 1;
+  Point[] path = 2;
+  int score = 3 [deprecated];
+}
+
+union Shape = Point | Player;
+
+union Event = Point | Player {
+  kind;
+}
+`
+	text := schema.Format(parsed)
+	if text != want {
+		t.Fatalf("got\n%s\nwant\n%s", text, want)
+	}
+
+	// The text parses back to the same schema.
+	reparsed, err := schema.Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again := schema.Format(reparsed); again != text {
+		t.Errorf("got\n%s\nafter parsing it again", again)
+	}
+}
@@ -0,0 +1,87 @@
+// Package schema reads .kiwi schemas, the same as js/parser.ts. Its types are
+// the ones in js/schema.ts, down to the JSON field names.
+package schema
+
+import "fmt"
+
+// Schema is a parsed schema.
+type Schema struct {
+	// Package is the name after the package keyword, or "" if there isn't one.
+	Package     string        `json:"package"`
+	Definitions []*Definition `json:"definitions"`
+}
+
+// Definition returns the definition called name, or nil.
+func (s *Schema) Definition(name string) *Definition {
+	for _, definition := range s.Definitions {
+		if definition.Name == name {
+			return definition
+		}
+	}
+	return nil
+}
+
+// Kind is the kind of a Definition, the keyword it starts with in capitals.
+type Kind string
+
+const (
+	Enum    Kind = "ENUM"
+	Smol    Kind = "SMOL"
+	Struct  Kind = "STRUCT"
+	Message Kind = "MESSAGE"
+	Entity  Kind = "ENTITY"
+	Union   Kind = "UNION"
+	Alias   Kind = "ALIAS"
+	// Pick is never the Kind of a parsed Definition. A pick becomes a Struct
+	// with PickFrom set.
+	Pick Kind = "PICK"
+)
+
+type Definition struct {
+	Name   string `json:"name"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Kind   Kind   `json:"kind"`
+	// Fields are the values of an enum, the members of a union after its
+	// discriminator if it has one, and the target of an alias.
+	Fields []Field `json:"fields"`
+	// Extensions are the structs a struct copies the fields of with &.
+	Extensions     []string `json:"extensions,omitempty"`
+	SerializerPath string   `json:"serializerPath,omitempty"`
+	// PickFrom is the definition a pick copied its fields from.
+	PickFrom string `json:"pickFrom,omitempty"`
+}
+
+// Field returns the field called name, or nil.
+func (d *Definition) Field(name string) *Field {
+	for i := range d.Fields {
+		if d.Fields[i].Name == name {
+			return &d.Fields[i]
+		}
+	}
+	return nil
+}
+
+type Field struct {
+	Name   string `json:"name"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	// Type is "" for the values of enums.
+	Type         string `json:"type"`
+	IsRequired   bool   `json:"isRequired"`
+	IsArray      bool   `json:"isArray"`
+	IsDeprecated bool   `json:"isDeprecated"`
+	Value        int    `json:"value"`
+}
+
+// Error is a problem with a schema, at the line and column where it was found.
+// Both start at 1.
+type Error struct {
+	Message string
+	Line    int
+	Column  int
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
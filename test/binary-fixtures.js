@@ -0,0 +1,26 @@
+// Writes binary-fixtures.json, what the JavaScript decoder makes of the binary
+// schemas in the repo. The Go schema package is tested against it, so that
+// both read and write the same bytes.
+var fs = require("fs");
+var peechy = require(__dirname + "/../js/peechy.node.js");
+
+// Each binary schema and the text schema it was encoded from.
+var files = [
+  ["test1-schema.bkiwi", "test1-schema.kiwi"],
+  ["test2-schema.bkiwi", "test2-schema.kiwi"],
+  ["../js/blob.kiwib", "test-union.kiwi"],
+];
+
+var out = files.map(function (file) {
+  var binary = new Uint8Array(fs.readFileSync(__dirname + "/" + file[0]));
+  return {
+    name: file[0],
+    text: file[1],
+    schema: peechy.decodeBinarySchema(binary),
+  };
+});
+
+fs.writeFileSync(
+  __dirname + "/binary-fixtures.json",
+  JSON.stringify(out, null, 2) + "\n"
+);
@@ -0,0 +1,220 @@
+package gostrict
+
+import (
+ "encoding/json"
+ "strconv"
+ "github.com/jarred-sumner/peechy/buffer"
+ "github.com/jarred-sumner/peechy/message"
+)
+type Suit uint
+
+const (
+  SuitClubs Suit = 1
+  SuitDiamonds Suit = 2
+
+)
+
+var SuitToString = map[Suit]string{
+  SuitClubs: "Clubs",
+  SuitDiamonds: "Diamonds",
+
+}
+
+var SuitToID = map[string]Suit{
+  "Clubs": SuitClubs,
+  "Diamonds": SuitDiamonds,
+
+}
+
+
+// SuitValues returns every Suit in the order of the schema.
+func SuitValues() []Suit {
+  return []Suit{SuitClubs, SuitDiamonds}
+}
+
+// IsValid reports whether s is one of the Suit constants.
+func (s Suit) IsValid() bool {
+  _, ok := SuitToString[s]
+  return ok
+}
+
+// String returns the name s has in the schema.
+func (s Suit) String() string {
+  if name, ok := SuitToString[s]; ok {
+    return name
+  }
+  return "Suit(" + strconv.FormatUint(uint64(s), 10) + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler. It writes what MarshalJSON
+// does without the quotes, so that maps keyed by Suit marshal too.
+func (s Suit) MarshalText() ([]byte, error) {
+  if name, ok := SuitToString[s]; ok {
+    return []byte(name), nil
+  }
+  return nil, &buffer.InvalidEnumError{Enum: "Suit", Value: uint(s)}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the name of
+// one of the values in the schema, or the number of one of them.
+func (s *Suit) UnmarshalText(text []byte) error {
+  if value, ok := SuitToID[string(text)]; ok {
+    *s = value
+    return nil
+  }
+  value, err := strconv.ParseUint(string(text), 10, 0)
+  if err != nil {
+    return &buffer.InvalidEnumError{Enum: "Suit", Name: string(text)}
+  }
+  if !Suit(value).IsValid() {
+    return &buffer.InvalidEnumError{Enum: "Suit", Value: uint(value)}
+  }
+  *s = Suit(value)
+  return nil
+}
+
+// MarshalJSON marshals the enum as its name in the schema, the same as
+// JavaScript, or fails if it isn't one of the constants.
+func (s Suit) MarshalJSON() ([]byte, error) {
+  if name, ok := SuitToString[s]; ok {
+    return []byte(`"` + name + `"`), nil
+  }
+  return nil, &buffer.InvalidEnumError{Enum: "Suit", Value: uint(s)}
+}
+
+// UnmarshalJSON unmarshals a quoted json string, which has to be the name of
+// one of the values in the schema, or the number of one of them.
+func (s *Suit) UnmarshalJSON(b []byte) error {
+  if string(b) == "null" {
+    return nil
+  }
+
+  var j string
+  if err := json.Unmarshal(b, &j); err == nil {
+    return s.UnmarshalText([]byte(j))
+  }
+
+  var value uint
+  if err := json.Unmarshal(b, &value); err != nil {
+    return err
+  }
+  if !Suit(value).IsValid() {
+    return &buffer.InvalidEnumError{Enum: "Suit", Value: uint(value)}
+  }
+  *s = Suit(value)
+  return nil
+}
+
+// DecodeSuit reads a Suit, and fails for values that aren't one of the
+// constants.
+func DecodeSuit(buf buffer.Reader) (Suit, error) {
+  value := Suit(buf.ReadVarUint())
+  if err := buf.Err(); err != nil {
+    return value, err
+  }
+  if !value.IsValid() {
+    return value, &buffer.InvalidEnumError{Enum: "Suit", Value: uint(value)}
+  }
+  return value, nil
+}
+
+type Card struct {
+Suit    Suit     `json:"suit" redis:"suit"`
+}
+
+func DecodeCard(buf buffer.Reader) (Card, error) {
+  result := Card{}
+  err := DecodeCardInto(buf, &result)
+  return result, err
+}
+
+// DecodeCardInto decodes into result, reusing the room in its slices.
+func DecodeCardInto(buf buffer.Reader, result *Card) error {
+  var err error
+  if result.Suit, err = DecodeSuit(buf); err != nil {
+    return err
+  }
+  return buf.Err()
+}
+
+func (i *Card) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *Card) encode(buf buffer.Writer) error {
+
+    buf.WriteVarUint(uint(i.Suit))
+  return nil
+}
+
+func (*Card) TypeName() string {
+  return "gostrict.Card"
+}
+
+func (*Card) TypeID() uint32 {
+  return 1826572309
+}
+
+func (i *Card) Decode(buf buffer.Reader) error {
+  return DecodeCardInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeCardInto.
+func (i *Card) Reset() {
+  *i = Card{}
+}
+
+// Equal reports whether i and other have the same fields.
+func (i *Card) Equal(other *Card) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if i.Suit != other.Suit {
+    return false
+  }
+  return true
+}
+
+// Clone returns a deep copy of i.
+func (i *Card) Clone() *Card {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *Card) clone() Card {
+  c := *i
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *Card) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *Card) EncodedSizeIn(format buffer.WireFormat) int {
+  n := 0
+  n += format.VarUintSize(uint(i.Suit))
+  return n
+}
+
+// SchemaTypes describes this schema for buffer.Buffer.SetTypes, which lets
+// code generated from an older version of it skip fields it doesn't know.
+var SchemaTypes = buffer.Types{
+  "Suit": {Kind: buffer.EnumType},
+  "Card": {Kind: buffer.StructType, Fields: []buffer.TypeField{{Type: "Suit"}}},
+}
+
+func init() {
+  for _, newMessage := range []func() message.Message{
+    func() message.Message { return new(Card) },
+  } {
+    if err := message.Register(newMessage); err != nil {
+      panic(err)
+    }
+  }
+}
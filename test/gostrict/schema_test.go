@@ -0,0 +1,60 @@
+package gostrict_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jarred-sumner/peechy/buffer"
+	"github.com/jarred-sumner/peechy/test/gostrict"
+	"github.com/valyala/bytebufferpool"
+)
+
+// schema.go is generated from test-go-strict.kiwi by test.sh.
+
+func TestSchemaStrictEnum(t *testing.T) {
+	bb := bytebufferpool.Get()
+	defer bytebufferpool.Put(bb)
+
+	in := gostrict.Card{Suit: gostrict.SuitDiamonds}
+	if err := in.Encode(buffer.NewBuffer(bb)); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := gostrict.DecodeCard(buffer.FromBytes(bb.B))
+	if err != nil || out != in {
+		t.Fatalf("Expected %+v to equal %+v, got %v", out, in, err)
+	}
+
+	in.Suit = 3
+	bb.Reset()
+	if err := in.Encode(buffer.NewBuffer(bb)); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = gostrict.DecodeCard(buffer.FromBytes(bb.B))
+	if enum, ok := err.(*buffer.InvalidEnumError); !ok || enum.Enum != "Suit" || enum.Value != 3 {
+		t.Fatalf("Expected an invalid Suit, got %v", err)
+	}
+}
+
+func TestSchemaStrictEnumJSON(t *testing.T) {
+	var suit gostrict.Suit
+	if err := json.Unmarshal([]byte("2"), &suit); err != nil || suit != gostrict.SuitDiamonds {
+		t.Fatalf("Expected SuitDiamonds, got %v, %v", suit, err)
+	}
+
+	// Numbers have to be one of the constants, the same as when decoding.
+	for _, data := range []string{"99", `"99"`} {
+		err := json.Unmarshal([]byte(data), &suit)
+		if enum, ok := err.(*buffer.InvalidEnumError); !ok || enum.Value != 99 {
+			t.Fatalf("Expected an invalid Suit for %s, got %v", data, err)
+		}
+	}
+
+	if _, err := json.Marshal(gostrict.Suit(99)); err == nil {
+		t.Fatal("Expected an error marshaling an unknown value")
+	}
+	if _, err := json.Marshal(map[gostrict.Suit]bool{99: true}); err == nil {
+		t.Fatal("Expected an error marshaling an unknown key")
+	}
+}
@@ -0,0 +1,3713 @@
+package gotest
+
+import (
+ "errors"
+ "encoding/json"
+ "strconv"
+ "math"
+ "unsafe"
+ "github.com/jarred-sumner/peechy/buffer"
+ "github.com/jarred-sumner/peechy/message"
+)
+type Point struct {
+X    int     `json:"x" redis:"x"`
+Y    int     `json:"y" redis:"y"`
+}
+
+func DecodePoint(buf buffer.Reader) (Point, error) {
+  result := Point{}
+  err := DecodePointInto(buf, &result)
+  return result, err
+}
+
+// DecodePointInto decodes into result, reusing the room in its slices.
+func DecodePointInto(buf buffer.Reader, result *Point) error {
+  result.X = buf.ReadVarInt()
+  result.Y = buf.ReadVarInt()
+  return buf.Err()
+}
+
+func (i *Point) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *Point) encode(buf buffer.Writer) error {
+
+    buf.WriteVarInt(i.X);
+
+    buf.WriteVarInt(i.Y);
+  return nil
+}
+
+func (*Point) TypeName() string {
+  return "gotest.Point"
+}
+
+func (*Point) TypeID() uint32 {
+  return 3128814895
+}
+
+func (i *Point) Decode(buf buffer.Reader) error {
+  return DecodePointInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodePointInto.
+func (i *Point) Reset() {
+  *i = Point{}
+}
+
+// Equal reports whether i and other have the same fields.
+func (i *Point) Equal(other *Point) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if i.X != other.X {
+    return false
+  }
+  if i.Y != other.Y {
+    return false
+  }
+  return true
+}
+
+// Clone returns a deep copy of i.
+func (i *Point) Clone() *Point {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *Point) clone() Point {
+  c := *i
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *Point) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *Point) EncodedSizeIn(format buffer.WireFormat) int {
+  n := 0
+  n += format.VarIntSize(i.X)
+  n += format.VarIntSize(i.Y)
+  return n
+}
+
+type Label struct {
+Text    *string     `json:"text" redis:"text"`
+Color    *uint     `json:"color" redis:"color"`
+
+// UnknownFields are fields from a newer schema that DecodeX skipped, which Encode writes back.
+UnknownFields []byte `json:"-" redis:"-"`
+}
+
+func DecodeLabel(buf buffer.Reader) (Label, error) {
+  result := Label{}
+  err := DecodeLabelInto(buf, &result)
+  return result, err
+}
+
+// DecodeLabelInto decodes into result, reusing what its fields point to.
+func DecodeLabelInto(buf buffer.Reader, result *Label) error {
+  text_1, color_2 := result.Text, result.Color
+  *result = Label{UnknownFields: result.UnknownFields[:0]}
+
+  for {
+    switch fieldType := buf.ReadUint8(); fieldType {
+    case 0:
+      return buf.Err()
+
+    case 1:
+      if text_1 == nil {
+        text_1 = new(string)
+      }
+      result.Text = text_1
+      (*text_1) = buf.ReadString()
+
+    case 2:
+      if color_2 == nil {
+        color_2 = new(uint)
+      }
+      result.Color = color_2
+      (*color_2) = buf.ReadVarUint()
+
+    default:
+      unknown, err := buf.SkipField("Label", fieldType)
+      if err != nil {
+        return err
+      }
+      result.UnknownFields = append(result.UnknownFields, unknown...)
+    }
+  }
+}
+
+func (i *Label) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *Label) encode(buf buffer.Writer) error {
+
+  if i.Text != nil {
+    buf.WriteByte(1);
+    buf.WriteString(*i.Text);
+   }
+
+  if i.Color != nil {
+    buf.WriteByte(2);
+    buf.WriteVarUint(*i.Color);
+   }
+  buf.Write(i.UnknownFields);
+  buf.WriteByte(0);
+  return nil
+}
+
+func (*Label) TypeName() string {
+  return "gotest.Label"
+}
+
+func (*Label) TypeID() uint32 {
+  return 58674915
+}
+
+func (i *Label) Decode(buf buffer.Reader) error {
+  return DecodeLabelInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeLabelInto.
+func (i *Label) Reset() {
+  *i = Label{UnknownFields: i.UnknownFields[:0]}
+}
+
+// Equal reports whether i and other have the same fields set to the same values.
+func (i *Label) Equal(other *Label) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if (i.Text == nil) != (other.Text == nil) {
+    return false
+  }
+  if i.Text != nil {
+    if (*i.Text) != (*other.Text) {
+      return false
+    }
+  }
+  if (i.Color == nil) != (other.Color == nil) {
+    return false
+  }
+  if i.Color != nil {
+    if (*i.Color) != (*other.Color) {
+      return false
+    }
+  }
+  return string(i.UnknownFields) == string(other.UnknownFields)
+}
+
+// Clone returns a deep copy of i.
+func (i *Label) Clone() *Label {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *Label) clone() Label {
+  c := *i
+  if i.Text != nil {
+    text_1 := (*i.Text)
+    c.Text = &text_1
+  }
+  if i.Color != nil {
+    color_2 := (*i.Color)
+    c.Color = &color_2
+  }
+  c.UnknownFields = append([]byte(nil), i.UnknownFields...)
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *Label) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *Label) EncodedSizeIn(format buffer.WireFormat) int {
+  n := len(i.UnknownFields) + 1
+  if i.Text != nil {
+    n++
+    n += format.BytesSize(len((*i.Text)))
+  }
+  if i.Color != nil {
+    n++
+    n += format.VarUintSize((*i.Color))
+  }
+  return n
+}
+
+// Shape is one of *Point or *Label.
+type Shape interface {
+  isShape()
+}
+
+type ShapeType byte
+
+const (
+  ShapeTypePoint ShapeType = 1
+  ShapeTypeLabel ShapeType = 2
+)
+
+func (*Point) isShape() {}
+func (*Label) isShape() {}
+
+func EncodeShape(buf buffer.Writer, value Shape) error {
+  buf.Grow(EncodedSizeShape(buf.WireFormat(), value))
+  return encodeShape(buf, value)
+}
+
+func encodeShape(buf buffer.Writer, value Shape) error {
+  switch value := value.(type) {
+  case *Point:
+    if value != nil {
+      buf.WriteByte(byte(ShapeTypePoint))
+      return value.encode(buf)
+    }
+  case *Label:
+    if value != nil {
+      buf.WriteByte(byte(ShapeTypeLabel))
+      return value.encode(buf)
+    }
+  }
+  return errors.New("attempted to encode invalid union");
+}
+
+// EncodedSizeShape returns how many bytes EncodeShape writes with format.
+func EncodedSizeShape(format buffer.WireFormat, value Shape) int {
+  switch value := value.(type) {
+  case *Point:
+    if value != nil {
+      return 1 + value.EncodedSizeIn(format)
+    }
+  case *Label:
+    if value != nil {
+      return 1 + value.EncodedSizeIn(format)
+    }
+  }
+  return 0
+}
+
+// EqualShape reports whether a and b are the same member with the same fields.
+func EqualShape(a, b Shape) bool {
+  if a == nil || b == nil {
+    return a == b
+  }
+  switch a := a.(type) {
+  case *Point:
+    b, ok := b.(*Point)
+    return ok && a.Equal(b)
+  case *Label:
+    b, ok := b.(*Label)
+    return ok && a.Equal(b)
+  default:
+    return false
+  }
+}
+
+// CloneShape returns a deep copy of value.
+func CloneShape(value Shape) Shape {
+  switch value := value.(type) {
+  case *Point:
+    return value.Clone()
+  case *Label:
+    return value.Clone()
+  default:
+    return value
+  }
+}
+
+func DecodeShape(buf buffer.Reader) (Shape, error) {
+  switch ShapeType(buf.ReadUint8()) {
+  case ShapeTypePoint:
+    value, err := DecodePoint(buf)
+    return &value, err
+  case ShapeTypeLabel:
+    value, err := DecodeLabel(buf)
+    return &value, err
+  default:
+    if err := buf.Err(); err != nil {
+      return nil, err
+    }
+    return nil, errors.New("attempted to parse invalid union");
+  }
+}
+
+// Event is one of *Point or *Label.
+// The "kind" discriminator JavaScript sets is the concrete type here.
+type Event interface {
+  isEvent()
+}
+
+type EventType byte
+
+const (
+  EventTypePoint EventType = 1
+  EventTypeLabel EventType = 2
+)
+
+func (*Point) isEvent() {}
+func (*Label) isEvent() {}
+
+func EncodeEvent(buf buffer.Writer, value Event) error {
+  buf.Grow(EncodedSizeEvent(buf.WireFormat(), value))
+  return encodeEvent(buf, value)
+}
+
+func encodeEvent(buf buffer.Writer, value Event) error {
+  switch value := value.(type) {
+  case *Point:
+    if value != nil {
+      buf.WriteByte(byte(EventTypePoint))
+      return value.encode(buf)
+    }
+  case *Label:
+    if value != nil {
+      buf.WriteByte(byte(EventTypeLabel))
+      return value.encode(buf)
+    }
+  }
+  return errors.New("attempted to encode invalid union");
+}
+
+// EncodedSizeEvent returns how many bytes EncodeEvent writes with format.
+func EncodedSizeEvent(format buffer.WireFormat, value Event) int {
+  switch value := value.(type) {
+  case *Point:
+    if value != nil {
+      return 1 + value.EncodedSizeIn(format)
+    }
+  case *Label:
+    if value != nil {
+      return 1 + value.EncodedSizeIn(format)
+    }
+  }
+  return 0
+}
+
+// EqualEvent reports whether a and b are the same member with the same fields.
+func EqualEvent(a, b Event) bool {
+  if a == nil || b == nil {
+    return a == b
+  }
+  switch a := a.(type) {
+  case *Point:
+    b, ok := b.(*Point)
+    return ok && a.Equal(b)
+  case *Label:
+    b, ok := b.(*Label)
+    return ok && a.Equal(b)
+  default:
+    return false
+  }
+}
+
+// CloneEvent returns a deep copy of value.
+func CloneEvent(value Event) Event {
+  switch value := value.(type) {
+  case *Point:
+    return value.Clone()
+  case *Label:
+    return value.Clone()
+  default:
+    return value
+  }
+}
+
+func DecodeEvent(buf buffer.Reader) (Event, error) {
+  switch EventType(buf.ReadUint8()) {
+  case EventTypePoint:
+    value, err := DecodePoint(buf)
+    return &value, err
+  case EventTypeLabel:
+    value, err := DecodeLabel(buf)
+    return &value, err
+  default:
+    if err := buf.Err(); err != nil {
+      return nil, err
+    }
+    return nil, errors.New("attempted to parse invalid union");
+  }
+}
+
+// Any is one of *AnyShape or *AnyEvent.
+type Any interface {
+  isAny()
+}
+
+type AnyType byte
+
+const (
+  AnyTypeShape AnyType = 1
+  AnyTypeEvent AnyType = 2
+)
+
+// AnyShape holds the Shape member of Any.
+type AnyShape struct {
+  Value Shape `json:"value"`
+}
+
+// AnyEvent holds the Event member of Any.
+type AnyEvent struct {
+  Value Event `json:"value"`
+}
+
+func (*AnyShape) isAny() {}
+func (*AnyEvent) isAny() {}
+
+func EncodeAny(buf buffer.Writer, value Any) error {
+  buf.Grow(EncodedSizeAny(buf.WireFormat(), value))
+  return encodeAny(buf, value)
+}
+
+func encodeAny(buf buffer.Writer, value Any) error {
+  switch value := value.(type) {
+  case *AnyShape:
+    if value != nil {
+      buf.WriteByte(byte(AnyTypeShape))
+      return encodeShape(buf, value.Value)
+    }
+  case *AnyEvent:
+    if value != nil {
+      buf.WriteByte(byte(AnyTypeEvent))
+      return encodeEvent(buf, value.Value)
+    }
+  }
+  return errors.New("attempted to encode invalid union");
+}
+
+// EncodedSizeAny returns how many bytes EncodeAny writes with format.
+func EncodedSizeAny(format buffer.WireFormat, value Any) int {
+  switch value := value.(type) {
+  case *AnyShape:
+    if value != nil {
+      return 1 + EncodedSizeShape(format, value.Value)
+    }
+  case *AnyEvent:
+    if value != nil {
+      return 1 + EncodedSizeEvent(format, value.Value)
+    }
+  }
+  return 0
+}
+
+// EqualAny reports whether a and b are the same member with the same fields.
+func EqualAny(a, b Any) bool {
+  if a == nil || b == nil {
+    return a == b
+  }
+  switch a := a.(type) {
+  case *AnyShape:
+    b, ok := b.(*AnyShape)
+    if !ok || a == nil || b == nil {
+      return ok && a == b
+    }
+    return EqualShape(a.Value, b.Value)
+  case *AnyEvent:
+    b, ok := b.(*AnyEvent)
+    if !ok || a == nil || b == nil {
+      return ok && a == b
+    }
+    return EqualEvent(a.Value, b.Value)
+  default:
+    return false
+  }
+}
+
+// CloneAny returns a deep copy of value.
+func CloneAny(value Any) Any {
+  switch value := value.(type) {
+  case *AnyShape:
+    if value == nil {
+      return value
+    }
+    return &AnyShape{Value: CloneShape(value.Value)}
+  case *AnyEvent:
+    if value == nil {
+      return value
+    }
+    return &AnyEvent{Value: CloneEvent(value.Value)}
+  default:
+    return value
+  }
+}
+
+func DecodeAny(buf buffer.Reader) (Any, error) {
+  switch AnyType(buf.ReadUint8()) {
+  case AnyTypeShape:
+    value, err := DecodeShape(buf)
+    if value == nil {
+      return nil, err
+    }
+    return &AnyShape{Value: value}, err
+  case AnyTypeEvent:
+    value, err := DecodeEvent(buf)
+    if value == nil {
+      return nil, err
+    }
+    return &AnyEvent{Value: value}, err
+  default:
+    if err := buf.Err(); err != nil {
+      return nil, err
+    }
+    return nil, errors.New("attempted to parse invalid union");
+  }
+}
+
+type ShapeStruct struct {
+Shape    Shape     `json:"shape" redis:"shape"`
+Event    Event     `json:"event" redis:"event"`
+}
+
+func DecodeShapeStruct(buf buffer.Reader) (ShapeStruct, error) {
+  result := ShapeStruct{}
+  err := DecodeShapeStructInto(buf, &result)
+  return result, err
+}
+
+// DecodeShapeStructInto decodes into result, reusing the room in its slices.
+func DecodeShapeStructInto(buf buffer.Reader, result *ShapeStruct) error {
+  var err error
+  if result.Shape, err = DecodeShape(buf); err != nil {
+    return err
+  }
+  if result.Event, err = DecodeEvent(buf); err != nil {
+    return err
+  }
+  return buf.Err()
+}
+
+func (i *ShapeStruct) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *ShapeStruct) encode(buf buffer.Writer) error {
+
+var err error;
+    err =encodeShape(buf, i.Shape)
+    if err != nil {
+ return err
+}
+
+
+    err =encodeEvent(buf, i.Event)
+    if err != nil {
+ return err
+}
+
+  return nil
+}
+
+func (*ShapeStruct) TypeName() string {
+  return "gotest.ShapeStruct"
+}
+
+func (*ShapeStruct) TypeID() uint32 {
+  return 2724335121
+}
+
+func (i *ShapeStruct) Decode(buf buffer.Reader) error {
+  return DecodeShapeStructInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeShapeStructInto.
+func (i *ShapeStruct) Reset() {
+  *i = ShapeStruct{}
+}
+
+// Equal reports whether i and other have the same fields.
+func (i *ShapeStruct) Equal(other *ShapeStruct) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if !EqualShape(i.Shape, other.Shape) {
+    return false
+  }
+  if !EqualEvent(i.Event, other.Event) {
+    return false
+  }
+  return true
+}
+
+// Clone returns a deep copy of i.
+func (i *ShapeStruct) Clone() *ShapeStruct {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *ShapeStruct) clone() ShapeStruct {
+  c := *i
+  c.Shape = CloneShape(i.Shape)
+  c.Event = CloneEvent(i.Event)
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *ShapeStruct) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *ShapeStruct) EncodedSizeIn(format buffer.WireFormat) int {
+  n := 0
+  n += EncodedSizeShape(format, i.Shape)
+  n += EncodedSizeEvent(format, i.Event)
+  return n
+}
+
+type EventArrayStruct struct {
+Events    []Event     `json:"events" redis:"events"`
+}
+
+func DecodeEventArrayStruct(buf buffer.Reader) (EventArrayStruct, error) {
+  result := EventArrayStruct{}
+  err := DecodeEventArrayStructInto(buf, &result)
+  return result, err
+}
+
+// DecodeEventArrayStructInto decodes into result, reusing the room in its slices.
+func DecodeEventArrayStructInto(buf buffer.Reader, result *EventArrayStruct) error {
+  var err error
+  var length uint
+  length = buf.ReadArrayLength()
+  if result.Events == nil || uint(cap(result.Events)) < length {
+    result.Events = make([]Event, length)
+  } else {
+    result.Events = result.Events[:length]
+  }
+  for j := range result.Events {
+    if result.Events[j], err = DecodeEvent(buf); err != nil {
+      return err
+    }
+  }
+  return buf.Err()
+}
+
+func (i *EventArrayStruct) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *EventArrayStruct) encode(buf buffer.Writer) error {
+
+    var n uint;
+    n = uint(len(i.Events))
+    buf.WriteVarUint(n);
+    for j := uint(0); j < n; j++ {
+      err := encodeEvent(buf, i.Events[j])
+      if err != nil {
+return err;
+}
+
+    }
+  return nil
+}
+
+func (*EventArrayStruct) TypeName() string {
+  return "gotest.EventArrayStruct"
+}
+
+func (*EventArrayStruct) TypeID() uint32 {
+  return 752641771
+}
+
+func (i *EventArrayStruct) Decode(buf buffer.Reader) error {
+  return DecodeEventArrayStructInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeEventArrayStructInto.
+func (i *EventArrayStruct) Reset() {
+  *i = EventArrayStruct{Events: i.Events[:0]}
+}
+
+// Equal reports whether i and other have the same fields.
+func (i *EventArrayStruct) Equal(other *EventArrayStruct) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if len(i.Events) != len(other.Events) {
+    return false
+  }
+  for j := range i.Events {
+    if !EqualEvent(i.Events[j], other.Events[j]) {
+      return false
+    }
+  }
+  return true
+}
+
+// Clone returns a deep copy of i.
+func (i *EventArrayStruct) Clone() *EventArrayStruct {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *EventArrayStruct) clone() EventArrayStruct {
+  c := *i
+  c.Events = append(i.Events[:0:0], i.Events...)
+  for j := range c.Events {
+    c.Events[j] = CloneEvent(c.Events[j])
+  }
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *EventArrayStruct) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *EventArrayStruct) EncodedSizeIn(format buffer.WireFormat) int {
+  n := 0
+  n += format.VarUintSize(uint(len(i.Events)))
+  for j := range i.Events {
+    n += EncodedSizeEvent(format, i.Events[j])
+  }
+  return n
+}
+
+type ShapeMessage struct {
+Shape    Shape     `json:"shape" redis:"shape"`
+Events    *[]Event     `json:"events" redis:"events"`
+
+// UnknownFields are fields from a newer schema that DecodeX skipped, which Encode writes back.
+UnknownFields []byte `json:"-" redis:"-"`
+}
+
+func DecodeShapeMessage(buf buffer.Reader) (ShapeMessage, error) {
+  result := ShapeMessage{}
+  err := DecodeShapeMessageInto(buf, &result)
+  return result, err
+}
+
+// DecodeShapeMessageInto decodes into result, reusing what its fields point to.
+func DecodeShapeMessageInto(buf buffer.Reader, result *ShapeMessage) error {
+  var length uint
+  var err error
+  events_2 := result.Events
+  *result = ShapeMessage{UnknownFields: result.UnknownFields[:0]}
+
+  for {
+    switch fieldType := buf.ReadUint8(); fieldType {
+    case 0:
+      return buf.Err()
+
+    case 1:
+      if result.Shape, err = DecodeShape(buf); err != nil {
+        return err
+      }
+
+    case 2:
+      if events_2 == nil {
+        events_2 = new([]Event)
+      }
+      result.Events = events_2
+      length = buf.ReadArrayLength()
+      if (*events_2) == nil || uint(cap((*events_2))) < length {
+        (*events_2) = make([]Event, length)
+      } else {
+        (*events_2) = (*events_2)[:length]
+      }
+      for j := range (*events_2) {
+        if (*events_2)[j], err = DecodeEvent(buf); err != nil {
+          return err
+        }
+      }
+
+    default:
+      unknown, err := buf.SkipField("ShapeMessage", fieldType)
+      if err != nil {
+        return err
+      }
+      result.UnknownFields = append(result.UnknownFields, unknown...)
+    }
+  }
+}
+
+func (i *ShapeMessage) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *ShapeMessage) encode(buf buffer.Writer) error {
+
+    var n uint;
+var err error;
+  if i.Shape != nil {
+    buf.WriteByte(1);
+    err =encodeShape(buf, i.Shape)
+    if err != nil {
+ return err
+}
+
+   }
+
+  if i.Events != nil {
+    buf.WriteByte(2);
+    n = uint(len(*i.Events))
+    buf.WriteVarUint(n);
+    for j := uint(0); j < n; j++ {
+      err := encodeEvent(buf, (*i.Events)[j])
+      if err != nil {
+return err;
+}
+
+    }
+   }
+  buf.Write(i.UnknownFields);
+  buf.WriteByte(0);
+  return nil
+}
+
+func (*ShapeMessage) TypeName() string {
+  return "gotest.ShapeMessage"
+}
+
+func (*ShapeMessage) TypeID() uint32 {
+  return 12840347
+}
+
+func (i *ShapeMessage) Decode(buf buffer.Reader) error {
+  return DecodeShapeMessageInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeShapeMessageInto.
+func (i *ShapeMessage) Reset() {
+  *i = ShapeMessage{UnknownFields: i.UnknownFields[:0]}
+}
+
+// Equal reports whether i and other have the same fields set to the same values.
+func (i *ShapeMessage) Equal(other *ShapeMessage) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if !EqualShape(i.Shape, other.Shape) {
+    return false
+  }
+  if (i.Events == nil) != (other.Events == nil) {
+    return false
+  }
+  if i.Events != nil {
+    if len((*i.Events)) != len((*other.Events)) {
+      return false
+    }
+    for j := range (*i.Events) {
+      if !EqualEvent((*i.Events)[j], (*other.Events)[j]) {
+        return false
+      }
+    }
+  }
+  return string(i.UnknownFields) == string(other.UnknownFields)
+}
+
+// Clone returns a deep copy of i.
+func (i *ShapeMessage) Clone() *ShapeMessage {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *ShapeMessage) clone() ShapeMessage {
+  c := *i
+  c.Shape = CloneShape(i.Shape)
+  if i.Events != nil {
+    events_2 := append((*i.Events)[:0:0], (*i.Events)...)
+    for j := range events_2 {
+      events_2[j] = CloneEvent(events_2[j])
+    }
+    c.Events = &events_2
+  }
+  c.UnknownFields = append([]byte(nil), i.UnknownFields...)
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *ShapeMessage) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *ShapeMessage) EncodedSizeIn(format buffer.WireFormat) int {
+  n := len(i.UnknownFields) + 1
+  if i.Shape != nil {
+    n++
+    n += EncodedSizeShape(format, i.Shape)
+  }
+  if i.Events != nil {
+    n++
+    n += format.VarUintSize(uint(len((*i.Events))))
+    for j := range (*i.Events) {
+      n += EncodedSizeEvent(format, (*i.Events)[j])
+    }
+  }
+  return n
+}
+
+type AnyStruct struct {
+Value    Any     `json:"value" redis:"value"`
+}
+
+func DecodeAnyStruct(buf buffer.Reader) (AnyStruct, error) {
+  result := AnyStruct{}
+  err := DecodeAnyStructInto(buf, &result)
+  return result, err
+}
+
+// DecodeAnyStructInto decodes into result, reusing the room in its slices.
+func DecodeAnyStructInto(buf buffer.Reader, result *AnyStruct) error {
+  var err error
+  if result.Value, err = DecodeAny(buf); err != nil {
+    return err
+  }
+  return buf.Err()
+}
+
+func (i *AnyStruct) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *AnyStruct) encode(buf buffer.Writer) error {
+
+var err error;
+    err =encodeAny(buf, i.Value)
+    if err != nil {
+ return err
+}
+
+  return nil
+}
+
+func (*AnyStruct) TypeName() string {
+  return "gotest.AnyStruct"
+}
+
+func (*AnyStruct) TypeID() uint32 {
+  return 3952471890
+}
+
+func (i *AnyStruct) Decode(buf buffer.Reader) error {
+  return DecodeAnyStructInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeAnyStructInto.
+func (i *AnyStruct) Reset() {
+  *i = AnyStruct{}
+}
+
+// Equal reports whether i and other have the same fields.
+func (i *AnyStruct) Equal(other *AnyStruct) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if !EqualAny(i.Value, other.Value) {
+    return false
+  }
+  return true
+}
+
+// Clone returns a deep copy of i.
+func (i *AnyStruct) Clone() *AnyStruct {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *AnyStruct) clone() AnyStruct {
+  c := *i
+  c.Value = CloneAny(i.Value)
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *AnyStruct) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *AnyStruct) EncodedSizeIn(format buffer.WireFormat) int {
+  n := 0
+  n += EncodedSizeAny(format, i.Value)
+  return n
+}
+
+type Player struct {
+X    float32     `json:"x" redis:"x"`
+Y    float32     `json:"y" redis:"y"`
+OnGround    bool     `json:"onGround" redis:"onGround"`
+Username    string     `json:"username" redis:"username"`
+}
+
+func DecodePlayer(buf buffer.Reader) (Player, error) {
+  result := Player{}
+  err := DecodePlayerInto(buf, &result)
+  return result, err
+}
+
+// DecodePlayerInto decodes into result, reusing the room in its slices.
+func DecodePlayerInto(buf buffer.Reader, result *Player) error {
+  result.X = buf.ReadVarFloat()
+  result.Y = buf.ReadVarFloat()
+  result.OnGround = buf.ReadBool()
+  result.Username = buf.ReadString()
+  return buf.Err()
+}
+
+func (i *Player) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *Player) encode(buf buffer.Writer) error {
+
+    buf.WriteVarFloat(i.X);
+
+    buf.WriteVarFloat(i.Y);
+
+    buf.WriteBool(i.OnGround);
+
+    buf.WriteString(i.Username);
+  return nil
+}
+
+func (*Player) TypeName() string {
+  return "gotest.Player"
+}
+
+func (*Player) TypeID() uint32 {
+  return 264666099
+}
+
+func (i *Player) Decode(buf buffer.Reader) error {
+  return DecodePlayerInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodePlayerInto.
+func (i *Player) Reset() {
+  *i = Player{}
+}
+
+// Equal reports whether i and other have the same fields.
+func (i *Player) Equal(other *Player) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if math.Float32bits(i.X) != math.Float32bits(other.X) {
+    return false
+  }
+  if math.Float32bits(i.Y) != math.Float32bits(other.Y) {
+    return false
+  }
+  if i.OnGround != other.OnGround {
+    return false
+  }
+  if i.Username != other.Username {
+    return false
+  }
+  return true
+}
+
+// Clone returns a deep copy of i.
+func (i *Player) Clone() *Player {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *Player) clone() Player {
+  c := *i
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *Player) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *Player) EncodedSizeIn(format buffer.WireFormat) int {
+  n := 0
+  n += buffer.VarFloatSize(i.X)
+  n += buffer.VarFloatSize(i.Y)
+  n += 1
+  n += format.BytesSize(len(i.Username))
+  return n
+}
+
+type Profile struct {
+Username    *string     `json:"username" redis:"username"`
+Friends    *[]uint     `json:"friends" redis:"friends"`
+Avatar    Shape     `json:"avatar" redis:"avatar"`
+Age    *uint     `json:"age" redis:"age"`
+
+// UnknownFields are fields from a newer schema that DecodeX skipped, which Encode writes back.
+UnknownFields []byte `json:"-" redis:"-"`
+}
+
+func DecodeProfile(buf buffer.Reader) (Profile, error) {
+  result := Profile{}
+  err := DecodeProfileInto(buf, &result)
+  return result, err
+}
+
+// DecodeProfileInto decodes into result, reusing what its fields point to.
+func DecodeProfileInto(buf buffer.Reader, result *Profile) error {
+  var err error
+  var length uint
+  username_1, friends_2, age_4 := result.Username, result.Friends, result.Age
+  *result = Profile{UnknownFields: result.UnknownFields[:0]}
+
+  for {
+    switch fieldType := buf.ReadUint8(); fieldType {
+    case 0:
+      return buf.Err()
+
+    case 1:
+      if username_1 == nil {
+        username_1 = new(string)
+      }
+      result.Username = username_1
+      (*username_1) = buf.ReadString()
+
+    case 2:
+      if friends_2 == nil {
+        friends_2 = new([]uint)
+      }
+      result.Friends = friends_2
+      length = buf.ReadArrayLength()
+      if (*friends_2) == nil || uint(cap((*friends_2))) < length {
+        (*friends_2) = make([]uint, length)
+      } else {
+        (*friends_2) = (*friends_2)[:length]
+      }
+      for j := range (*friends_2) {
+        (*friends_2)[j] = buf.ReadVarUint()
+      }
+
+    case 3:
+      if result.Avatar, err = DecodeShape(buf); err != nil {
+        return err
+      }
+
+    case 4:
+      if age_4 == nil {
+        age_4 = new(uint)
+      }
+      result.Age = age_4
+      (*age_4) = buf.ReadVarUint()
+
+    default:
+      unknown, err := buf.SkipField("Profile", fieldType)
+      if err != nil {
+        return err
+      }
+      result.UnknownFields = append(result.UnknownFields, unknown...)
+    }
+  }
+}
+
+func (i *Profile) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *Profile) encode(buf buffer.Writer) error {
+
+var err error;
+    var n uint;
+  if i.Username != nil {
+    buf.WriteByte(1);
+    buf.WriteString(*i.Username);
+   }
+
+  if i.Friends != nil {
+    buf.WriteByte(2);
+    n = uint(len(*i.Friends))
+    buf.WriteVarUint(n);
+    for j := uint(0); j < n; j++ {
+      buf.WriteVarUint((*i.Friends)[j]);
+    }
+   }
+
+  if i.Avatar != nil {
+    buf.WriteByte(3);
+    err =encodeShape(buf, i.Avatar)
+    if err != nil {
+ return err
+}
+
+   }
+
+  if i.Age != nil {
+    buf.WriteByte(4);
+    buf.WriteVarUint(*i.Age);
+   }
+  buf.Write(i.UnknownFields);
+  buf.WriteByte(0);
+  return nil
+}
+
+func (*Profile) TypeName() string {
+  return "gotest.Profile"
+}
+
+func (*Profile) TypeID() uint32 {
+  return 2561426303
+}
+
+func (i *Profile) Decode(buf buffer.Reader) error {
+  return DecodeProfileInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeProfileInto.
+func (i *Profile) Reset() {
+  *i = Profile{UnknownFields: i.UnknownFields[:0]}
+}
+
+// Equal reports whether i and other have the same fields set to the same values.
+func (i *Profile) Equal(other *Profile) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if (i.Username == nil) != (other.Username == nil) {
+    return false
+  }
+  if i.Username != nil {
+    if (*i.Username) != (*other.Username) {
+      return false
+    }
+  }
+  if (i.Friends == nil) != (other.Friends == nil) {
+    return false
+  }
+  if i.Friends != nil {
+    if len((*i.Friends)) != len((*other.Friends)) {
+      return false
+    }
+    for j := range (*i.Friends) {
+      if (*i.Friends)[j] != (*other.Friends)[j] {
+        return false
+      }
+    }
+  }
+  if !EqualShape(i.Avatar, other.Avatar) {
+    return false
+  }
+  if (i.Age == nil) != (other.Age == nil) {
+    return false
+  }
+  if i.Age != nil {
+    if (*i.Age) != (*other.Age) {
+      return false
+    }
+  }
+  return string(i.UnknownFields) == string(other.UnknownFields)
+}
+
+// Clone returns a deep copy of i.
+func (i *Profile) Clone() *Profile {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *Profile) clone() Profile {
+  c := *i
+  if i.Username != nil {
+    username_1 := (*i.Username)
+    c.Username = &username_1
+  }
+  if i.Friends != nil {
+    friends_2 := append((*i.Friends)[:0:0], (*i.Friends)...)
+    c.Friends = &friends_2
+  }
+  c.Avatar = CloneShape(i.Avatar)
+  if i.Age != nil {
+    age_4 := (*i.Age)
+    c.Age = &age_4
+  }
+  c.UnknownFields = append([]byte(nil), i.UnknownFields...)
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *Profile) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *Profile) EncodedSizeIn(format buffer.WireFormat) int {
+  n := len(i.UnknownFields) + 1
+  if i.Username != nil {
+    n++
+    n += format.BytesSize(len((*i.Username)))
+  }
+  if i.Friends != nil {
+    n++
+    n += format.VarUintSize(uint(len((*i.Friends))))
+    for j := range (*i.Friends) {
+      n += format.VarUintSize((*i.Friends)[j])
+    }
+  }
+  if i.Avatar != nil {
+    n++
+    n += EncodedSizeShape(format, i.Avatar)
+  }
+  if i.Age != nil {
+    n++
+    n += format.VarUintSize((*i.Age))
+  }
+  return n
+}
+
+// PlayerUpdate is one of *PositionUpdate or *NameChange.
+type PlayerUpdate interface {
+  isPlayerUpdate()
+}
+
+type PlayerUpdateType byte
+
+const (
+  PlayerUpdateTypePositionUpdate PlayerUpdateType = 1
+  PlayerUpdateTypeNameChange PlayerUpdateType = 2
+)
+
+func (*PositionUpdate) isPlayerUpdate() {}
+func (*NameChange) isPlayerUpdate() {}
+
+func EncodePlayerUpdate(buf buffer.Writer, value PlayerUpdate) error {
+  buf.Grow(EncodedSizePlayerUpdate(buf.WireFormat(), value))
+  return encodePlayerUpdate(buf, value)
+}
+
+func encodePlayerUpdate(buf buffer.Writer, value PlayerUpdate) error {
+  switch value := value.(type) {
+  case *PositionUpdate:
+    if value != nil {
+      buf.WriteByte(byte(PlayerUpdateTypePositionUpdate))
+      return value.encode(buf)
+    }
+  case *NameChange:
+    if value != nil {
+      buf.WriteByte(byte(PlayerUpdateTypeNameChange))
+      return value.encode(buf)
+    }
+  }
+  return errors.New("attempted to encode invalid union");
+}
+
+// EncodedSizePlayerUpdate returns how many bytes EncodePlayerUpdate writes with format.
+func EncodedSizePlayerUpdate(format buffer.WireFormat, value PlayerUpdate) int {
+  switch value := value.(type) {
+  case *PositionUpdate:
+    if value != nil {
+      return 1 + value.EncodedSizeIn(format)
+    }
+  case *NameChange:
+    if value != nil {
+      return 1 + value.EncodedSizeIn(format)
+    }
+  }
+  return 0
+}
+
+// EqualPlayerUpdate reports whether a and b are the same member with the same fields.
+func EqualPlayerUpdate(a, b PlayerUpdate) bool {
+  if a == nil || b == nil {
+    return a == b
+  }
+  switch a := a.(type) {
+  case *PositionUpdate:
+    b, ok := b.(*PositionUpdate)
+    return ok && a.Equal(b)
+  case *NameChange:
+    b, ok := b.(*NameChange)
+    return ok && a.Equal(b)
+  default:
+    return false
+  }
+}
+
+// ClonePlayerUpdate returns a deep copy of value.
+func ClonePlayerUpdate(value PlayerUpdate) PlayerUpdate {
+  switch value := value.(type) {
+  case *PositionUpdate:
+    return value.Clone()
+  case *NameChange:
+    return value.Clone()
+  default:
+    return value
+  }
+}
+
+func DecodePlayerUpdate(buf buffer.Reader) (PlayerUpdate, error) {
+  switch PlayerUpdateType(buf.ReadUint8()) {
+  case PlayerUpdateTypePositionUpdate:
+    value, err := DecodePositionUpdate(buf)
+    return &value, err
+  case PlayerUpdateTypeNameChange:
+    value, err := DecodeNameChange(buf)
+    return &value, err
+  default:
+    if err := buf.Err(); err != nil {
+      return nil, err
+    }
+    return nil, errors.New("attempted to parse invalid union");
+  }
+}
+
+type PlayerUpdateStruct struct {
+Update    PlayerUpdate     `json:"update" redis:"update"`
+}
+
+func DecodePlayerUpdateStruct(buf buffer.Reader) (PlayerUpdateStruct, error) {
+  result := PlayerUpdateStruct{}
+  err := DecodePlayerUpdateStructInto(buf, &result)
+  return result, err
+}
+
+// DecodePlayerUpdateStructInto decodes into result, reusing the room in its slices.
+func DecodePlayerUpdateStructInto(buf buffer.Reader, result *PlayerUpdateStruct) error {
+  var err error
+  if result.Update, err = DecodePlayerUpdate(buf); err != nil {
+    return err
+  }
+  return buf.Err()
+}
+
+func (i *PlayerUpdateStruct) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *PlayerUpdateStruct) encode(buf buffer.Writer) error {
+
+var err error;
+    err =encodePlayerUpdate(buf, i.Update)
+    if err != nil {
+ return err
+}
+
+  return nil
+}
+
+func (*PlayerUpdateStruct) TypeName() string {
+  return "gotest.PlayerUpdateStruct"
+}
+
+func (*PlayerUpdateStruct) TypeID() uint32 {
+  return 190537626
+}
+
+func (i *PlayerUpdateStruct) Decode(buf buffer.Reader) error {
+  return DecodePlayerUpdateStructInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodePlayerUpdateStructInto.
+func (i *PlayerUpdateStruct) Reset() {
+  *i = PlayerUpdateStruct{}
+}
+
+// Equal reports whether i and other have the same fields.
+func (i *PlayerUpdateStruct) Equal(other *PlayerUpdateStruct) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if !EqualPlayerUpdate(i.Update, other.Update) {
+    return false
+  }
+  return true
+}
+
+// Clone returns a deep copy of i.
+func (i *PlayerUpdateStruct) Clone() *PlayerUpdateStruct {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *PlayerUpdateStruct) clone() PlayerUpdateStruct {
+  c := *i
+  c.Update = ClonePlayerUpdate(i.Update)
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *PlayerUpdateStruct) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *PlayerUpdateStruct) EncodedSizeIn(format buffer.WireFormat) int {
+  n := 0
+  n += EncodedSizePlayerUpdate(format, i.Update)
+  return n
+}
+
+type ID string
+
+type UserID ID
+
+type Meters float32
+
+type Entity struct {
+ID    UserID     `json:"id" redis:"id"`
+Tags    []ID     `json:"tags" redis:"tags"`
+Height    Meters     `json:"height" redis:"height"`
+Path    []Meters     `json:"path" redis:"path"`
+}
+
+func DecodeEntity(buf buffer.Reader) (Entity, error) {
+  result := Entity{}
+  err := DecodeEntityInto(buf, &result)
+  return result, err
+}
+
+// DecodeEntityInto decodes into result, reusing the room in its slices.
+func DecodeEntityInto(buf buffer.Reader, result *Entity) error {
+  var length uint
+  result.ID = UserID(buf.ReadString())
+  length = buf.ReadArrayLength()
+  if result.Tags == nil || uint(cap(result.Tags)) < length {
+    result.Tags = make([]ID, length)
+  } else {
+    result.Tags = result.Tags[:length]
+  }
+  for j := range result.Tags {
+    result.Tags[j] = ID(buf.ReadString())
+  }
+  result.Height = Meters(buf.ReadFloat32())
+  *(*[]float32)(unsafe.Pointer(&result.Path)) = buf.ReadFloat32ArrayInto(*(*[]float32)(unsafe.Pointer(&result.Path)))
+  return buf.Err()
+}
+
+func (i *Entity) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *Entity) encode(buf buffer.Writer) error {
+
+    var n uint;
+    buf.WriteString(string(i.ID));
+
+    n = uint(len(i.Tags))
+    buf.WriteVarUint(n);
+    for j := uint(0); j < n; j++ {
+      buf.WriteString(string(i.Tags[j]));
+    }
+
+    buf.WriteFloat32(float32(i.Height));
+
+   buf.WriteFloat32Array(*(*[]float32)(unsafe.Pointer(&i.Path)));
+  return nil
+}
+
+func (*Entity) TypeName() string {
+  return "gotest.Entity"
+}
+
+func (*Entity) TypeID() uint32 {
+  return 2583119358
+}
+
+func (i *Entity) Decode(buf buffer.Reader) error {
+  return DecodeEntityInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeEntityInto.
+func (i *Entity) Reset() {
+  *i = Entity{Tags: i.Tags[:0], Path: i.Path[:0]}
+}
+
+// Equal reports whether i and other have the same fields.
+func (i *Entity) Equal(other *Entity) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if i.ID != other.ID {
+    return false
+  }
+  if len(i.Tags) != len(other.Tags) {
+    return false
+  }
+  for j := range i.Tags {
+    if i.Tags[j] != other.Tags[j] {
+      return false
+    }
+  }
+  if math.Float32bits(float32(i.Height)) != math.Float32bits(float32(other.Height)) {
+    return false
+  }
+  if len(i.Path) != len(other.Path) {
+    return false
+  }
+  for j := range i.Path {
+    if math.Float32bits(float32(i.Path[j])) != math.Float32bits(float32(other.Path[j])) {
+      return false
+    }
+  }
+  return true
+}
+
+// Clone returns a deep copy of i.
+func (i *Entity) Clone() *Entity {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *Entity) clone() Entity {
+  c := *i
+  c.Tags = append(i.Tags[:0:0], i.Tags...)
+  c.Path = append(i.Path[:0:0], i.Path...)
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *Entity) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *Entity) EncodedSizeIn(format buffer.WireFormat) int {
+  n := 0
+  n += format.BytesSize(len(string(i.ID)))
+  n += format.VarUintSize(uint(len(i.Tags)))
+  for j := range i.Tags {
+    n += format.BytesSize(len(string(i.Tags[j])))
+  }
+  n += 4
+  n += format.BytesSize(len(i.Path) * 4)
+  return n
+}
+
+type EntityMessage struct {
+ID    *ID     `json:"id" redis:"id"`
+Tags    *[]ID     `json:"tags" redis:"tags"`
+Height    *Meters     `json:"height" redis:"height"`
+
+// UnknownFields are fields from a newer schema that DecodeX skipped, which Encode writes back.
+UnknownFields []byte `json:"-" redis:"-"`
+}
+
+func DecodeEntityMessage(buf buffer.Reader) (EntityMessage, error) {
+  result := EntityMessage{}
+  err := DecodeEntityMessageInto(buf, &result)
+  return result, err
+}
+
+// DecodeEntityMessageInto decodes into result, reusing what its fields point to.
+func DecodeEntityMessageInto(buf buffer.Reader, result *EntityMessage) error {
+  var length uint
+  id_1, tags_2, height_3 := result.ID, result.Tags, result.Height
+  *result = EntityMessage{UnknownFields: result.UnknownFields[:0]}
+
+  for {
+    switch fieldType := buf.ReadUint8(); fieldType {
+    case 0:
+      return buf.Err()
+
+    case 1:
+      if id_1 == nil {
+        id_1 = new(ID)
+      }
+      result.ID = id_1
+      (*id_1) = ID(buf.ReadString())
+
+    case 2:
+      if tags_2 == nil {
+        tags_2 = new([]ID)
+      }
+      result.Tags = tags_2
+      length = buf.ReadArrayLength()
+      if (*tags_2) == nil || uint(cap((*tags_2))) < length {
+        (*tags_2) = make([]ID, length)
+      } else {
+        (*tags_2) = (*tags_2)[:length]
+      }
+      for j := range (*tags_2) {
+        (*tags_2)[j] = ID(buf.ReadString())
+      }
+
+    case 3:
+      if height_3 == nil {
+        height_3 = new(Meters)
+      }
+      result.Height = height_3
+      (*height_3) = Meters(buf.ReadFloat32())
+
+    default:
+      unknown, err := buf.SkipField("EntityMessage", fieldType)
+      if err != nil {
+        return err
+      }
+      result.UnknownFields = append(result.UnknownFields, unknown...)
+    }
+  }
+}
+
+func (i *EntityMessage) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *EntityMessage) encode(buf buffer.Writer) error {
+
+    var n uint;
+  if i.ID != nil {
+    buf.WriteByte(1);
+    buf.WriteString(string(*i.ID));
+   }
+
+  if i.Tags != nil {
+    buf.WriteByte(2);
+    n = uint(len(*i.Tags))
+    buf.WriteVarUint(n);
+    for j := uint(0); j < n; j++ {
+      buf.WriteString(string((*i.Tags)[j]));
+    }
+   }
+
+  if i.Height != nil {
+    buf.WriteByte(3);
+    buf.WriteFloat32(float32(*i.Height));
+   }
+  buf.Write(i.UnknownFields);
+  buf.WriteByte(0);
+  return nil
+}
+
+func (*EntityMessage) TypeName() string {
+  return "gotest.EntityMessage"
+}
+
+func (*EntityMessage) TypeID() uint32 {
+  return 680063451
+}
+
+func (i *EntityMessage) Decode(buf buffer.Reader) error {
+  return DecodeEntityMessageInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeEntityMessageInto.
+func (i *EntityMessage) Reset() {
+  *i = EntityMessage{UnknownFields: i.UnknownFields[:0]}
+}
+
+// Equal reports whether i and other have the same fields set to the same values.
+func (i *EntityMessage) Equal(other *EntityMessage) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if (i.ID == nil) != (other.ID == nil) {
+    return false
+  }
+  if i.ID != nil {
+    if (*i.ID) != (*other.ID) {
+      return false
+    }
+  }
+  if (i.Tags == nil) != (other.Tags == nil) {
+    return false
+  }
+  if i.Tags != nil {
+    if len((*i.Tags)) != len((*other.Tags)) {
+      return false
+    }
+    for j := range (*i.Tags) {
+      if (*i.Tags)[j] != (*other.Tags)[j] {
+        return false
+      }
+    }
+  }
+  if (i.Height == nil) != (other.Height == nil) {
+    return false
+  }
+  if i.Height != nil {
+    if math.Float32bits(float32((*i.Height))) != math.Float32bits(float32((*other.Height))) {
+      return false
+    }
+  }
+  return string(i.UnknownFields) == string(other.UnknownFields)
+}
+
+// Clone returns a deep copy of i.
+func (i *EntityMessage) Clone() *EntityMessage {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *EntityMessage) clone() EntityMessage {
+  c := *i
+  if i.ID != nil {
+    id_1 := (*i.ID)
+    c.ID = &id_1
+  }
+  if i.Tags != nil {
+    tags_2 := append((*i.Tags)[:0:0], (*i.Tags)...)
+    c.Tags = &tags_2
+  }
+  if i.Height != nil {
+    height_3 := (*i.Height)
+    c.Height = &height_3
+  }
+  c.UnknownFields = append([]byte(nil), i.UnknownFields...)
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *EntityMessage) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *EntityMessage) EncodedSizeIn(format buffer.WireFormat) int {
+  n := len(i.UnknownFields) + 1
+  if i.ID != nil {
+    n++
+    n += format.BytesSize(len(string((*i.ID))))
+  }
+  if i.Tags != nil {
+    n++
+    n += format.VarUintSize(uint(len((*i.Tags))))
+    for j := range (*i.Tags) {
+      n += format.BytesSize(len(string((*i.Tags)[j])))
+    }
+  }
+  if i.Height != nil {
+    n++
+    n += 4
+  }
+  return n
+}
+
+type Account struct {
+Name    *string     `json:"name" redis:"name"`
+Age    *uint     `json:"age" redis:"age"`
+Avatar    Shape     `json:"avatar" redis:"avatar"`
+
+// UnknownFields are fields from a newer schema that DecodeX skipped, which Encode writes back.
+UnknownFields []byte `json:"-" redis:"-"`
+}
+
+func DecodeAccount(buf buffer.Reader) (Account, error) {
+  result := Account{}
+  err := DecodeAccountInto(buf, &result)
+  return result, err
+}
+
+// DecodeAccountInto decodes into result, reusing what its fields point to.
+func DecodeAccountInto(buf buffer.Reader, result *Account) error {
+  var err error
+  var length uint
+  name_1, age_2 := result.Name, result.Age
+  *result = Account{UnknownFields: result.UnknownFields[:0]}
+
+  for {
+    switch fieldType := buf.ReadUint8(); fieldType {
+    case 0:
+      if err := buf.Err(); err != nil {
+        return err
+      }
+  if result.Name == nil {
+    return &buffer.MissingFieldError{Message: "Account", Field: "name"}
+  }
+  if result.Avatar == nil {
+    return &buffer.MissingFieldError{Message: "Account", Field: "avatar"}
+  }
+      return buf.Err()
+
+    case 1:
+      if name_1 == nil {
+        name_1 = new(string)
+      }
+      result.Name = name_1
+      (*name_1) = buf.ReadString()
+
+    case 2:
+      if age_2 == nil {
+        age_2 = new(uint)
+      }
+      result.Age = age_2
+      (*age_2) = buf.ReadVarUint()
+
+    case 3:
+      _ = buf.ReadString()
+    case 4:
+      length = buf.ReadArrayLength()
+      for j := uint(0); j < length; j++ {
+        if _, err := DecodeLabel(buf); err != nil {
+          return err
+        }
+      }
+    case 5:
+      if result.Avatar, err = DecodeShape(buf); err != nil {
+        return err
+      }
+
+    default:
+      unknown, err := buf.SkipField("Account", fieldType)
+      if err != nil {
+        return err
+      }
+      result.UnknownFields = append(result.UnknownFields, unknown...)
+    }
+  }
+}
+
+func (i *Account) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *Account) encode(buf buffer.Writer) error {
+  if i.Name == nil {
+    return &buffer.MissingFieldError{Message: "Account", Field: "name"}
+  }
+  if i.Avatar == nil {
+    return &buffer.MissingFieldError{Message: "Account", Field: "avatar"}
+  }
+
+var err error;
+  if i.Name != nil {
+    buf.WriteByte(1);
+    buf.WriteString(*i.Name);
+   }
+
+  if i.Age != nil {
+    buf.WriteByte(2);
+    buf.WriteVarUint(*i.Age);
+   }
+
+  if i.Avatar != nil {
+    buf.WriteByte(5);
+    err =encodeShape(buf, i.Avatar)
+    if err != nil {
+ return err
+}
+
+   }
+  buf.Write(i.UnknownFields);
+  buf.WriteByte(0);
+  return nil
+}
+
+func (*Account) TypeName() string {
+  return "gotest.Account"
+}
+
+func (*Account) TypeID() uint32 {
+  return 1691205588
+}
+
+func (i *Account) Decode(buf buffer.Reader) error {
+  return DecodeAccountInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeAccountInto.
+func (i *Account) Reset() {
+  *i = Account{UnknownFields: i.UnknownFields[:0]}
+}
+
+// Equal reports whether i and other have the same fields set to the same values.
+func (i *Account) Equal(other *Account) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if (i.Name == nil) != (other.Name == nil) {
+    return false
+  }
+  if i.Name != nil {
+    if (*i.Name) != (*other.Name) {
+      return false
+    }
+  }
+  if (i.Age == nil) != (other.Age == nil) {
+    return false
+  }
+  if i.Age != nil {
+    if (*i.Age) != (*other.Age) {
+      return false
+    }
+  }
+  if !EqualShape(i.Avatar, other.Avatar) {
+    return false
+  }
+  return string(i.UnknownFields) == string(other.UnknownFields)
+}
+
+// Clone returns a deep copy of i.
+func (i *Account) Clone() *Account {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *Account) clone() Account {
+  c := *i
+  if i.Name != nil {
+    name_1 := (*i.Name)
+    c.Name = &name_1
+  }
+  if i.Age != nil {
+    age_2 := (*i.Age)
+    c.Age = &age_2
+  }
+  c.Avatar = CloneShape(i.Avatar)
+  c.UnknownFields = append([]byte(nil), i.UnknownFields...)
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *Account) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *Account) EncodedSizeIn(format buffer.WireFormat) int {
+  n := len(i.UnknownFields) + 1
+  if i.Name != nil {
+    n++
+    n += format.BytesSize(len((*i.Name)))
+  }
+  if i.Age != nil {
+    n++
+    n += format.VarUintSize((*i.Age))
+  }
+  if i.Avatar != nil {
+    n++
+    n += EncodedSizeShape(format, i.Avatar)
+  }
+  return n
+}
+
+type AccountV1 struct {
+Name    *string     `json:"name" redis:"name"`
+Age    *uint     `json:"age" redis:"age"`
+Email    *string     `json:"email" redis:"email"`
+Labels    *[]Label     `json:"labels" redis:"labels"`
+Avatar    Shape     `json:"avatar" redis:"avatar"`
+
+// UnknownFields are fields from a newer schema that DecodeX skipped, which Encode writes back.
+UnknownFields []byte `json:"-" redis:"-"`
+}
+
+func DecodeAccountV1(buf buffer.Reader) (AccountV1, error) {
+  result := AccountV1{}
+  err := DecodeAccountV1Into(buf, &result)
+  return result, err
+}
+
+// DecodeAccountV1Into decodes into result, reusing what its fields point to.
+func DecodeAccountV1Into(buf buffer.Reader, result *AccountV1) error {
+  var err error
+  var length uint
+  name_1, age_2, email_3, labels_4 := result.Name, result.Age, result.Email, result.Labels
+  *result = AccountV1{UnknownFields: result.UnknownFields[:0]}
+
+  for {
+    switch fieldType := buf.ReadUint8(); fieldType {
+    case 0:
+      return buf.Err()
+
+    case 1:
+      if name_1 == nil {
+        name_1 = new(string)
+      }
+      result.Name = name_1
+      (*name_1) = buf.ReadString()
+
+    case 2:
+      if age_2 == nil {
+        age_2 = new(uint)
+      }
+      result.Age = age_2
+      (*age_2) = buf.ReadVarUint()
+
+    case 3:
+      if email_3 == nil {
+        email_3 = new(string)
+      }
+      result.Email = email_3
+      (*email_3) = buf.ReadString()
+
+    case 4:
+      if labels_4 == nil {
+        labels_4 = new([]Label)
+      }
+      result.Labels = labels_4
+      length = buf.ReadArrayLength()
+      if (*labels_4) == nil || uint(cap((*labels_4))) < length {
+        (*labels_4) = make([]Label, length)
+      } else {
+        (*labels_4) = (*labels_4)[:length]
+      }
+      for j := range (*labels_4) {
+        if err = DecodeLabelInto(buf, &(*labels_4)[j]); err != nil {
+          return err
+        }
+      }
+
+    case 5:
+      if result.Avatar, err = DecodeShape(buf); err != nil {
+        return err
+      }
+
+    default:
+      unknown, err := buf.SkipField("AccountV1", fieldType)
+      if err != nil {
+        return err
+      }
+      result.UnknownFields = append(result.UnknownFields, unknown...)
+    }
+  }
+}
+
+func (i *AccountV1) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *AccountV1) encode(buf buffer.Writer) error {
+
+var err error;
+    var n uint;
+  if i.Name != nil {
+    buf.WriteByte(1);
+    buf.WriteString(*i.Name);
+   }
+
+  if i.Age != nil {
+    buf.WriteByte(2);
+    buf.WriteVarUint(*i.Age);
+   }
+
+  if i.Email != nil {
+    buf.WriteByte(3);
+    buf.WriteString(*i.Email);
+   }
+
+  if i.Labels != nil {
+    buf.WriteByte(4);
+    n = uint(len(*i.Labels))
+    buf.WriteVarUint(n);
+    for j := uint(0); j < n; j++ {
+      err := (*i.Labels)[j].encode(buf)
+      if err != nil {
+return err;
+}
+
+    }
+   }
+
+  if i.Avatar != nil {
+    buf.WriteByte(5);
+    err =encodeShape(buf, i.Avatar)
+    if err != nil {
+ return err
+}
+
+   }
+  buf.Write(i.UnknownFields);
+  buf.WriteByte(0);
+  return nil
+}
+
+func (*AccountV1) TypeName() string {
+  return "gotest.AccountV1"
+}
+
+func (*AccountV1) TypeID() uint32 {
+  return 1114383068
+}
+
+func (i *AccountV1) Decode(buf buffer.Reader) error {
+  return DecodeAccountV1Into(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeAccountV1Into.
+func (i *AccountV1) Reset() {
+  *i = AccountV1{UnknownFields: i.UnknownFields[:0]}
+}
+
+// Equal reports whether i and other have the same fields set to the same values.
+func (i *AccountV1) Equal(other *AccountV1) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if (i.Name == nil) != (other.Name == nil) {
+    return false
+  }
+  if i.Name != nil {
+    if (*i.Name) != (*other.Name) {
+      return false
+    }
+  }
+  if (i.Age == nil) != (other.Age == nil) {
+    return false
+  }
+  if i.Age != nil {
+    if (*i.Age) != (*other.Age) {
+      return false
+    }
+  }
+  if (i.Email == nil) != (other.Email == nil) {
+    return false
+  }
+  if i.Email != nil {
+    if (*i.Email) != (*other.Email) {
+      return false
+    }
+  }
+  if (i.Labels == nil) != (other.Labels == nil) {
+    return false
+  }
+  if i.Labels != nil {
+    if len((*i.Labels)) != len((*other.Labels)) {
+      return false
+    }
+    for j := range (*i.Labels) {
+      if !(*i.Labels)[j].Equal(&(*other.Labels)[j]) {
+        return false
+      }
+    }
+  }
+  if !EqualShape(i.Avatar, other.Avatar) {
+    return false
+  }
+  return string(i.UnknownFields) == string(other.UnknownFields)
+}
+
+// Clone returns a deep copy of i.
+func (i *AccountV1) Clone() *AccountV1 {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *AccountV1) clone() AccountV1 {
+  c := *i
+  if i.Name != nil {
+    name_1 := (*i.Name)
+    c.Name = &name_1
+  }
+  if i.Age != nil {
+    age_2 := (*i.Age)
+    c.Age = &age_2
+  }
+  if i.Email != nil {
+    email_3 := (*i.Email)
+    c.Email = &email_3
+  }
+  if i.Labels != nil {
+    labels_4 := append((*i.Labels)[:0:0], (*i.Labels)...)
+    for j := range labels_4 {
+      labels_4[j] = labels_4[j].clone()
+    }
+    c.Labels = &labels_4
+  }
+  c.Avatar = CloneShape(i.Avatar)
+  c.UnknownFields = append([]byte(nil), i.UnknownFields...)
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *AccountV1) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *AccountV1) EncodedSizeIn(format buffer.WireFormat) int {
+  n := len(i.UnknownFields) + 1
+  if i.Name != nil {
+    n++
+    n += format.BytesSize(len((*i.Name)))
+  }
+  if i.Age != nil {
+    n++
+    n += format.VarUintSize((*i.Age))
+  }
+  if i.Email != nil {
+    n++
+    n += format.BytesSize(len((*i.Email)))
+  }
+  if i.Labels != nil {
+    n++
+    n += format.VarUintSize(uint(len((*i.Labels))))
+    for j := range (*i.Labels) {
+      n += (*i.Labels)[j].EncodedSizeIn(format)
+    }
+  }
+  if i.Avatar != nil {
+    n++
+    n += EncodedSizeShape(format, i.Avatar)
+  }
+  return n
+}
+
+type Card struct {
+Title    *string     `json:"title" redis:"title"`
+
+// UnknownFields are fields from a newer schema that DecodeX skipped, which Encode writes back.
+UnknownFields []byte `json:"-" redis:"-"`
+}
+
+func DecodeCard(buf buffer.Reader) (Card, error) {
+  result := Card{}
+  err := DecodeCardInto(buf, &result)
+  return result, err
+}
+
+// DecodeCardInto decodes into result, reusing what its fields point to.
+func DecodeCardInto(buf buffer.Reader, result *Card) error {
+  title_1 := result.Title
+  *result = Card{UnknownFields: result.UnknownFields[:0]}
+
+  for {
+    switch fieldType := buf.ReadUint8(); fieldType {
+    case 0:
+      return buf.Err()
+
+    case 1:
+      if title_1 == nil {
+        title_1 = new(string)
+      }
+      result.Title = title_1
+      (*title_1) = buf.ReadString()
+
+    default:
+      unknown, err := buf.SkipField("Card", fieldType)
+      if err != nil {
+        return err
+      }
+      result.UnknownFields = append(result.UnknownFields, unknown...)
+    }
+  }
+}
+
+func (i *Card) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *Card) encode(buf buffer.Writer) error {
+
+  if i.Title != nil {
+    buf.WriteByte(1);
+    buf.WriteString(*i.Title);
+   }
+  buf.Write(i.UnknownFields);
+  buf.WriteByte(0);
+  return nil
+}
+
+func (*Card) TypeName() string {
+  return "gotest.Card"
+}
+
+func (*Card) TypeID() uint32 {
+  return 2741022041
+}
+
+func (i *Card) Decode(buf buffer.Reader) error {
+  return DecodeCardInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeCardInto.
+func (i *Card) Reset() {
+  *i = Card{UnknownFields: i.UnknownFields[:0]}
+}
+
+// Equal reports whether i and other have the same fields set to the same values.
+func (i *Card) Equal(other *Card) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if (i.Title == nil) != (other.Title == nil) {
+    return false
+  }
+  if i.Title != nil {
+    if (*i.Title) != (*other.Title) {
+      return false
+    }
+  }
+  return string(i.UnknownFields) == string(other.UnknownFields)
+}
+
+// Clone returns a deep copy of i.
+func (i *Card) Clone() *Card {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *Card) clone() Card {
+  c := *i
+  if i.Title != nil {
+    title_1 := (*i.Title)
+    c.Title = &title_1
+  }
+  c.UnknownFields = append([]byte(nil), i.UnknownFields...)
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *Card) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *Card) EncodedSizeIn(format buffer.WireFormat) int {
+  n := len(i.UnknownFields) + 1
+  if i.Title != nil {
+    n++
+    n += format.BytesSize(len((*i.Title)))
+  }
+  return n
+}
+
+type CardV2 struct {
+Title    *string     `json:"title" redis:"title"`
+Corner    *Point     `json:"corner" redis:"corner"`
+Notes    *[]Label     `json:"notes" redis:"notes"`
+Events    *[]Event     `json:"events" redis:"events"`
+Weights    *[]float32     `json:"weights" redis:"weights"`
+Back    *Card     `json:"back" redis:"back"`
+
+// UnknownFields are fields from a newer schema that DecodeX skipped, which Encode writes back.
+UnknownFields []byte `json:"-" redis:"-"`
+}
+
+func DecodeCardV2(buf buffer.Reader) (CardV2, error) {
+  result := CardV2{}
+  err := DecodeCardV2Into(buf, &result)
+  return result, err
+}
+
+// DecodeCardV2Into decodes into result, reusing what its fields point to.
+func DecodeCardV2Into(buf buffer.Reader, result *CardV2) error {
+  var length uint
+  var err error
+  title_1, corner_2, notes_3, events_4, weights_5, back_6 := result.Title, result.Corner, result.Notes, result.Events, result.Weights, result.Back
+  *result = CardV2{UnknownFields: result.UnknownFields[:0]}
+
+  for {
+    switch fieldType := buf.ReadUint8(); fieldType {
+    case 0:
+      return buf.Err()
+
+    case 1:
+      if title_1 == nil {
+        title_1 = new(string)
+      }
+      result.Title = title_1
+      (*title_1) = buf.ReadString()
+
+    case 2:
+      if corner_2 == nil {
+        corner_2 = new(Point)
+      }
+      result.Corner = corner_2
+      if err = DecodePointInto(buf, corner_2); err != nil {
+        return err
+      }
+
+    case 3:
+      if notes_3 == nil {
+        notes_3 = new([]Label)
+      }
+      result.Notes = notes_3
+      length = buf.ReadArrayLength()
+      if (*notes_3) == nil || uint(cap((*notes_3))) < length {
+        (*notes_3) = make([]Label, length)
+      } else {
+        (*notes_3) = (*notes_3)[:length]
+      }
+      for j := range (*notes_3) {
+        if err = DecodeLabelInto(buf, &(*notes_3)[j]); err != nil {
+          return err
+        }
+      }
+
+    case 4:
+      if events_4 == nil {
+        events_4 = new([]Event)
+      }
+      result.Events = events_4
+      length = buf.ReadArrayLength()
+      if (*events_4) == nil || uint(cap((*events_4))) < length {
+        (*events_4) = make([]Event, length)
+      } else {
+        (*events_4) = (*events_4)[:length]
+      }
+      for j := range (*events_4) {
+        if (*events_4)[j], err = DecodeEvent(buf); err != nil {
+          return err
+        }
+      }
+
+    case 5:
+      if weights_5 == nil {
+        weights_5 = new([]float32)
+      }
+      result.Weights = weights_5
+      (*weights_5) = buf.ReadFloat32ArrayInto((*weights_5))
+
+    case 6:
+      if back_6 == nil {
+        back_6 = new(Card)
+      }
+      result.Back = back_6
+      if err = DecodeCardInto(buf, back_6); err != nil {
+        return err
+      }
+
+    default:
+      unknown, err := buf.SkipField("CardV2", fieldType)
+      if err != nil {
+        return err
+      }
+      result.UnknownFields = append(result.UnknownFields, unknown...)
+    }
+  }
+}
+
+func (i *CardV2) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *CardV2) encode(buf buffer.Writer) error {
+
+    var n uint;
+var err error;
+  if i.Title != nil {
+    buf.WriteByte(1);
+    buf.WriteString(*i.Title);
+   }
+
+  if i.Corner != nil {
+    buf.WriteByte(2);
+    err =i.Corner.encode(buf)
+    if err != nil {
+ return err
+}
+
+   }
+
+  if i.Notes != nil {
+    buf.WriteByte(3);
+    n = uint(len(*i.Notes))
+    buf.WriteVarUint(n);
+    for j := uint(0); j < n; j++ {
+      err := (*i.Notes)[j].encode(buf)
+      if err != nil {
+return err;
+}
+
+    }
+   }
+
+  if i.Events != nil {
+    buf.WriteByte(4);
+    n = uint(len(*i.Events))
+    buf.WriteVarUint(n);
+    for j := uint(0); j < n; j++ {
+      err := encodeEvent(buf, (*i.Events)[j])
+      if err != nil {
+return err;
+}
+
+    }
+   }
+
+  if i.Weights != nil {
+    buf.WriteByte(5);
+   buf.WriteFloat32Array(*i.Weights);
+   }
+
+  if i.Back != nil {
+    buf.WriteByte(6);
+    err =i.Back.encode(buf)
+    if err != nil {
+ return err
+}
+
+   }
+  buf.Write(i.UnknownFields);
+  buf.WriteByte(0);
+  return nil
+}
+
+func (*CardV2) TypeName() string {
+  return "gotest.CardV2"
+}
+
+func (*CardV2) TypeID() uint32 {
+  return 2800821125
+}
+
+func (i *CardV2) Decode(buf buffer.Reader) error {
+  return DecodeCardV2Into(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeCardV2Into.
+func (i *CardV2) Reset() {
+  *i = CardV2{UnknownFields: i.UnknownFields[:0]}
+}
+
+// Equal reports whether i and other have the same fields set to the same values.
+func (i *CardV2) Equal(other *CardV2) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if (i.Title == nil) != (other.Title == nil) {
+    return false
+  }
+  if i.Title != nil {
+    if (*i.Title) != (*other.Title) {
+      return false
+    }
+  }
+  if (i.Corner == nil) != (other.Corner == nil) {
+    return false
+  }
+  if i.Corner != nil {
+    if !(*i.Corner).Equal(&(*other.Corner)) {
+      return false
+    }
+  }
+  if (i.Notes == nil) != (other.Notes == nil) {
+    return false
+  }
+  if i.Notes != nil {
+    if len((*i.Notes)) != len((*other.Notes)) {
+      return false
+    }
+    for j := range (*i.Notes) {
+      if !(*i.Notes)[j].Equal(&(*other.Notes)[j]) {
+        return false
+      }
+    }
+  }
+  if (i.Events == nil) != (other.Events == nil) {
+    return false
+  }
+  if i.Events != nil {
+    if len((*i.Events)) != len((*other.Events)) {
+      return false
+    }
+    for j := range (*i.Events) {
+      if !EqualEvent((*i.Events)[j], (*other.Events)[j]) {
+        return false
+      }
+    }
+  }
+  if (i.Weights == nil) != (other.Weights == nil) {
+    return false
+  }
+  if i.Weights != nil {
+    if len((*i.Weights)) != len((*other.Weights)) {
+      return false
+    }
+    for j := range (*i.Weights) {
+      if math.Float32bits((*i.Weights)[j]) != math.Float32bits((*other.Weights)[j]) {
+        return false
+      }
+    }
+  }
+  if (i.Back == nil) != (other.Back == nil) {
+    return false
+  }
+  if i.Back != nil {
+    if !(*i.Back).Equal(&(*other.Back)) {
+      return false
+    }
+  }
+  return string(i.UnknownFields) == string(other.UnknownFields)
+}
+
+// Clone returns a deep copy of i.
+func (i *CardV2) Clone() *CardV2 {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *CardV2) clone() CardV2 {
+  c := *i
+  if i.Title != nil {
+    title_1 := (*i.Title)
+    c.Title = &title_1
+  }
+  if i.Corner != nil {
+    corner_2 := (*i.Corner).clone()
+    c.Corner = &corner_2
+  }
+  if i.Notes != nil {
+    notes_3 := append((*i.Notes)[:0:0], (*i.Notes)...)
+    for j := range notes_3 {
+      notes_3[j] = notes_3[j].clone()
+    }
+    c.Notes = &notes_3
+  }
+  if i.Events != nil {
+    events_4 := append((*i.Events)[:0:0], (*i.Events)...)
+    for j := range events_4 {
+      events_4[j] = CloneEvent(events_4[j])
+    }
+    c.Events = &events_4
+  }
+  if i.Weights != nil {
+    weights_5 := append((*i.Weights)[:0:0], (*i.Weights)...)
+    c.Weights = &weights_5
+  }
+  if i.Back != nil {
+    back_6 := (*i.Back).clone()
+    c.Back = &back_6
+  }
+  c.UnknownFields = append([]byte(nil), i.UnknownFields...)
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *CardV2) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *CardV2) EncodedSizeIn(format buffer.WireFormat) int {
+  n := len(i.UnknownFields) + 1
+  if i.Title != nil {
+    n++
+    n += format.BytesSize(len((*i.Title)))
+  }
+  if i.Corner != nil {
+    n++
+    n += (*i.Corner).EncodedSizeIn(format)
+  }
+  if i.Notes != nil {
+    n++
+    n += format.VarUintSize(uint(len((*i.Notes))))
+    for j := range (*i.Notes) {
+      n += (*i.Notes)[j].EncodedSizeIn(format)
+    }
+  }
+  if i.Events != nil {
+    n++
+    n += format.VarUintSize(uint(len((*i.Events))))
+    for j := range (*i.Events) {
+      n += EncodedSizeEvent(format, (*i.Events)[j])
+    }
+  }
+  if i.Weights != nil {
+    n++
+    n += format.BytesSize(len((*i.Weights)) * 4)
+  }
+  if i.Back != nil {
+    n++
+    n += (*i.Back).EncodedSizeIn(format)
+  }
+  return n
+}
+
+type Suit uint
+
+const (
+  SuitClubs Suit = 1
+  SuitDiamonds Suit = 2
+  SuitHearts Suit = 3
+  SuitSpades Suit = 4
+
+)
+
+var SuitToString = map[Suit]string{
+  SuitClubs: "Clubs",
+  SuitDiamonds: "Diamonds",
+  SuitHearts: "Hearts",
+  SuitSpades: "Spades",
+
+}
+
+var SuitToID = map[string]Suit{
+  "Clubs": SuitClubs,
+  "Diamonds": SuitDiamonds,
+  "Hearts": SuitHearts,
+  "Spades": SuitSpades,
+
+}
+
+
+// SuitValues returns every Suit in the order of the schema.
+func SuitValues() []Suit {
+  return []Suit{SuitClubs, SuitDiamonds, SuitHearts, SuitSpades}
+}
+
+// IsValid reports whether s is one of the Suit constants.
+func (s Suit) IsValid() bool {
+  _, ok := SuitToString[s]
+  return ok
+}
+
+// String returns the name s has in the schema.
+func (s Suit) String() string {
+  if name, ok := SuitToString[s]; ok {
+    return name
+  }
+  return "Suit(" + strconv.FormatUint(uint64(s), 10) + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler. It writes what MarshalJSON
+// does without the quotes, so that maps keyed by Suit marshal too.
+func (s Suit) MarshalText() ([]byte, error) {
+  if name, ok := SuitToString[s]; ok {
+    return []byte(name), nil
+  }
+  return []byte(strconv.FormatUint(uint64(s), 10)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the name of
+// one of the values in the schema, or a number.
+func (s *Suit) UnmarshalText(text []byte) error {
+  if value, ok := SuitToID[string(text)]; ok {
+    *s = value
+    return nil
+  }
+  value, err := strconv.ParseUint(string(text), 10, 0)
+  if err != nil {
+    return &buffer.InvalidEnumError{Enum: "Suit", Name: string(text)}
+  }
+  *s = Suit(value)
+  return nil
+}
+
+// MarshalJSON marshals the enum as its name in the schema, the same as
+// JavaScript, or as a number if it isn't one of the constants.
+func (s Suit) MarshalJSON() ([]byte, error) {
+  if name, ok := SuitToString[s]; ok {
+    return []byte(`"` + name + `"`), nil
+  }
+  return []byte(strconv.FormatUint(uint64(s), 10)), nil
+}
+
+// UnmarshalJSON unmarshals a quoted json string, which has to be the name of
+// one of the values in the schema, or a number.
+func (s *Suit) UnmarshalJSON(b []byte) error {
+  if string(b) == "null" {
+    return nil
+  }
+
+  var j string
+  if err := json.Unmarshal(b, &j); err == nil {
+    return s.UnmarshalText([]byte(j))
+  }
+
+  var value uint
+  if err := json.Unmarshal(b, &value); err != nil {
+    return err
+  }
+  *s = Suit(value)
+  return nil
+}
+
+type Rank byte
+
+const (
+  RankAce Rank = 1
+  RankKing Rank = 13
+
+)
+
+var RankToString = map[Rank]string{
+  RankAce: "Ace",
+  RankKing: "King",
+
+}
+
+var RankToID = map[string]Rank{
+  "Ace": RankAce,
+  "King": RankKing,
+
+}
+
+
+// RankValues returns every Rank in the order of the schema.
+func RankValues() []Rank {
+  return []Rank{RankAce, RankKing}
+}
+
+// IsValid reports whether s is one of the Rank constants.
+func (s Rank) IsValid() bool {
+  _, ok := RankToString[s]
+  return ok
+}
+
+// String returns the name s has in the schema.
+func (s Rank) String() string {
+  if name, ok := RankToString[s]; ok {
+    return name
+  }
+  return "Rank(" + strconv.FormatUint(uint64(s), 10) + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler. It writes what MarshalJSON
+// does without the quotes, so that maps keyed by Rank marshal too.
+func (s Rank) MarshalText() ([]byte, error) {
+  if name, ok := RankToString[s]; ok {
+    return []byte(name), nil
+  }
+  return []byte(strconv.FormatUint(uint64(s), 10)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the name of
+// one of the values in the schema, or a number.
+func (s *Rank) UnmarshalText(text []byte) error {
+  if value, ok := RankToID[string(text)]; ok {
+    *s = value
+    return nil
+  }
+  value, err := strconv.ParseUint(string(text), 10, 8)
+  if err != nil {
+    return &buffer.InvalidEnumError{Enum: "Rank", Name: string(text)}
+  }
+  *s = Rank(value)
+  return nil
+}
+
+// MarshalJSON marshals the enum as its name in the schema, the same as
+// JavaScript, or as a number if it isn't one of the constants.
+func (s Rank) MarshalJSON() ([]byte, error) {
+  if name, ok := RankToString[s]; ok {
+    return []byte(`"` + name + `"`), nil
+  }
+  return []byte(strconv.FormatUint(uint64(s), 10)), nil
+}
+
+// UnmarshalJSON unmarshals a quoted json string, which has to be the name of
+// one of the values in the schema, or a number.
+func (s *Rank) UnmarshalJSON(b []byte) error {
+  if string(b) == "null" {
+    return nil
+  }
+
+  var j string
+  if err := json.Unmarshal(b, &j); err == nil {
+    return s.UnmarshalText([]byte(j))
+  }
+
+  var value byte
+  if err := json.Unmarshal(b, &value); err != nil {
+    return err
+  }
+  *s = Rank(value)
+  return nil
+}
+
+type Hand struct {
+Trump    *Suit     `json:"trump" redis:"trump"`
+Suits    *[]Suit     `json:"suits" redis:"suits"`
+High    *Rank     `json:"high" redis:"high"`
+Ranks    *[]Rank     `json:"ranks" redis:"ranks"`
+
+// UnknownFields are fields from a newer schema that DecodeX skipped, which Encode writes back.
+UnknownFields []byte `json:"-" redis:"-"`
+}
+
+func DecodeHand(buf buffer.Reader) (Hand, error) {
+  result := Hand{}
+  err := DecodeHandInto(buf, &result)
+  return result, err
+}
+
+// DecodeHandInto decodes into result, reusing what its fields point to.
+func DecodeHandInto(buf buffer.Reader, result *Hand) error {
+  var length uint
+  trump_1, suits_2, high_3, ranks_4 := result.Trump, result.Suits, result.High, result.Ranks
+  *result = Hand{UnknownFields: result.UnknownFields[:0]}
+
+  for {
+    switch fieldType := buf.ReadUint8(); fieldType {
+    case 0:
+      return buf.Err()
+
+    case 1:
+      if trump_1 == nil {
+        trump_1 = new(Suit)
+      }
+      result.Trump = trump_1
+      (*trump_1) = Suit(buf.ReadVarUint())
+
+    case 2:
+      if suits_2 == nil {
+        suits_2 = new([]Suit)
+      }
+      result.Suits = suits_2
+      length = buf.ReadArrayLength()
+      if (*suits_2) == nil || uint(cap((*suits_2))) < length {
+        (*suits_2) = make([]Suit, length)
+      } else {
+        (*suits_2) = (*suits_2)[:length]
+      }
+      for j := range (*suits_2) {
+        (*suits_2)[j] = Suit(buf.ReadVarUint())
+      }
+
+    case 3:
+      if high_3 == nil {
+        high_3 = new(Rank)
+      }
+      result.High = high_3
+      (*high_3) = Rank(buf.ReadUint8())
+
+    case 4:
+      if ranks_4 == nil {
+        ranks_4 = new([]Rank)
+      }
+      result.Ranks = ranks_4
+      length = buf.ReadArrayLength()
+      if (*ranks_4) == nil || uint(cap((*ranks_4))) < length {
+        (*ranks_4) = make([]Rank, length)
+      } else {
+        (*ranks_4) = (*ranks_4)[:length]
+      }
+      for j := range (*ranks_4) {
+        (*ranks_4)[j] = Rank(buf.ReadUint8())
+      }
+
+    default:
+      unknown, err := buf.SkipField("Hand", fieldType)
+      if err != nil {
+        return err
+      }
+      result.UnknownFields = append(result.UnknownFields, unknown...)
+    }
+  }
+}
+
+func (i *Hand) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *Hand) encode(buf buffer.Writer) error {
+
+    var n uint;
+  if i.Trump != nil {
+    buf.WriteByte(1);
+    buf.WriteVarUint(uint(*i.Trump))
+   }
+
+  if i.Suits != nil {
+    buf.WriteByte(2);
+    n = uint(len(*i.Suits))
+    buf.WriteVarUint(n);
+    for j := uint(0); j < n; j++ {
+      buf.WriteVarUint(uint((*i.Suits)[j]))
+    }
+   }
+
+  if i.High != nil {
+    buf.WriteByte(3);
+    buf.WriteByte(byte(*i.High))
+   }
+
+  if i.Ranks != nil {
+    buf.WriteByte(4);
+    n = uint(len(*i.Ranks))
+    buf.WriteVarUint(n);
+    for j := uint(0); j < n; j++ {
+      buf.WriteByte(byte((*i.Ranks)[j]))
+    }
+   }
+  buf.Write(i.UnknownFields);
+  buf.WriteByte(0);
+  return nil
+}
+
+func (*Hand) TypeName() string {
+  return "gotest.Hand"
+}
+
+func (*Hand) TypeID() uint32 {
+  return 2450945797
+}
+
+func (i *Hand) Decode(buf buffer.Reader) error {
+  return DecodeHandInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeHandInto.
+func (i *Hand) Reset() {
+  *i = Hand{UnknownFields: i.UnknownFields[:0]}
+}
+
+// Equal reports whether i and other have the same fields set to the same values.
+func (i *Hand) Equal(other *Hand) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if (i.Trump == nil) != (other.Trump == nil) {
+    return false
+  }
+  if i.Trump != nil {
+    if (*i.Trump) != (*other.Trump) {
+      return false
+    }
+  }
+  if (i.Suits == nil) != (other.Suits == nil) {
+    return false
+  }
+  if i.Suits != nil {
+    if len((*i.Suits)) != len((*other.Suits)) {
+      return false
+    }
+    for j := range (*i.Suits) {
+      if (*i.Suits)[j] != (*other.Suits)[j] {
+        return false
+      }
+    }
+  }
+  if (i.High == nil) != (other.High == nil) {
+    return false
+  }
+  if i.High != nil {
+    if (*i.High) != (*other.High) {
+      return false
+    }
+  }
+  if (i.Ranks == nil) != (other.Ranks == nil) {
+    return false
+  }
+  if i.Ranks != nil {
+    if len((*i.Ranks)) != len((*other.Ranks)) {
+      return false
+    }
+    for j := range (*i.Ranks) {
+      if (*i.Ranks)[j] != (*other.Ranks)[j] {
+        return false
+      }
+    }
+  }
+  return string(i.UnknownFields) == string(other.UnknownFields)
+}
+
+// Clone returns a deep copy of i.
+func (i *Hand) Clone() *Hand {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *Hand) clone() Hand {
+  c := *i
+  if i.Trump != nil {
+    trump_1 := (*i.Trump)
+    c.Trump = &trump_1
+  }
+  if i.Suits != nil {
+    suits_2 := append((*i.Suits)[:0:0], (*i.Suits)...)
+    c.Suits = &suits_2
+  }
+  if i.High != nil {
+    high_3 := (*i.High)
+    c.High = &high_3
+  }
+  if i.Ranks != nil {
+    ranks_4 := append((*i.Ranks)[:0:0], (*i.Ranks)...)
+    c.Ranks = &ranks_4
+  }
+  c.UnknownFields = append([]byte(nil), i.UnknownFields...)
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *Hand) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *Hand) EncodedSizeIn(format buffer.WireFormat) int {
+  n := len(i.UnknownFields) + 1
+  if i.Trump != nil {
+    n++
+    n += format.VarUintSize(uint((*i.Trump)))
+  }
+  if i.Suits != nil {
+    n++
+    n += format.VarUintSize(uint(len((*i.Suits))))
+    for j := range (*i.Suits) {
+      n += format.VarUintSize(uint((*i.Suits)[j]))
+    }
+  }
+  if i.High != nil {
+    n++
+    n += 1
+  }
+  if i.Ranks != nil {
+    n++
+    n += format.VarUintSize(uint(len((*i.Ranks))))
+    n += len((*i.Ranks)) * 1
+  }
+  return n
+}
+
+type PositionUpdate struct {
+X    float32     `json:"x" redis:"x"`
+Y    float32     `json:"y" redis:"y"`
+OnGround    bool     `json:"onGround" redis:"onGround"`
+}
+
+func DecodePositionUpdate(buf buffer.Reader) (PositionUpdate, error) {
+  result := PositionUpdate{}
+  err := DecodePositionUpdateInto(buf, &result)
+  return result, err
+}
+
+// DecodePositionUpdateInto decodes into result, reusing the room in its slices.
+func DecodePositionUpdateInto(buf buffer.Reader, result *PositionUpdate) error {
+  result.X = buf.ReadVarFloat()
+  result.Y = buf.ReadVarFloat()
+  result.OnGround = buf.ReadBool()
+  return buf.Err()
+}
+
+func (i *PositionUpdate) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *PositionUpdate) encode(buf buffer.Writer) error {
+
+    buf.WriteVarFloat(i.X);
+
+    buf.WriteVarFloat(i.Y);
+
+    buf.WriteBool(i.OnGround);
+  return nil
+}
+
+func (*PositionUpdate) TypeName() string {
+  return "gotest.PositionUpdate"
+}
+
+func (*PositionUpdate) TypeID() uint32 {
+  return 1453841207
+}
+
+func (i *PositionUpdate) Decode(buf buffer.Reader) error {
+  return DecodePositionUpdateInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodePositionUpdateInto.
+func (i *PositionUpdate) Reset() {
+  *i = PositionUpdate{}
+}
+
+// Equal reports whether i and other have the same fields.
+func (i *PositionUpdate) Equal(other *PositionUpdate) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if math.Float32bits(i.X) != math.Float32bits(other.X) {
+    return false
+  }
+  if math.Float32bits(i.Y) != math.Float32bits(other.Y) {
+    return false
+  }
+  if i.OnGround != other.OnGround {
+    return false
+  }
+  return true
+}
+
+// Clone returns a deep copy of i.
+func (i *PositionUpdate) Clone() *PositionUpdate {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *PositionUpdate) clone() PositionUpdate {
+  c := *i
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *PositionUpdate) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *PositionUpdate) EncodedSizeIn(format buffer.WireFormat) int {
+  n := 0
+  n += buffer.VarFloatSize(i.X)
+  n += buffer.VarFloatSize(i.Y)
+  n += 1
+  return n
+}
+
+// ToPositionUpdate returns a deep copy of the fields PositionUpdate picks from Player.
+func (i *Player) ToPositionUpdate() PositionUpdate {
+  result := PositionUpdate{}
+  result.X = i.X
+  result.Y = i.Y
+  result.OnGround = i.OnGround
+  return result
+}
+
+// ApplyTo deep copies the fields of PositionUpdate to the Player it was picked from.
+func (i *PositionUpdate) ApplyTo(to *Player) {
+  to.X = i.X
+  to.Y = i.Y
+  to.OnGround = i.OnGround
+}
+
+type NameChange struct {
+Username    string     `json:"username" redis:"username"`
+}
+
+func DecodeNameChange(buf buffer.Reader) (NameChange, error) {
+  result := NameChange{}
+  err := DecodeNameChangeInto(buf, &result)
+  return result, err
+}
+
+// DecodeNameChangeInto decodes into result, reusing the room in its slices.
+func DecodeNameChangeInto(buf buffer.Reader, result *NameChange) error {
+  result.Username = buf.ReadString()
+  return buf.Err()
+}
+
+func (i *NameChange) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *NameChange) encode(buf buffer.Writer) error {
+
+    buf.WriteString(i.Username);
+  return nil
+}
+
+func (*NameChange) TypeName() string {
+  return "gotest.NameChange"
+}
+
+func (*NameChange) TypeID() uint32 {
+  return 4073148087
+}
+
+func (i *NameChange) Decode(buf buffer.Reader) error {
+  return DecodeNameChangeInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeNameChangeInto.
+func (i *NameChange) Reset() {
+  *i = NameChange{}
+}
+
+// Equal reports whether i and other have the same fields.
+func (i *NameChange) Equal(other *NameChange) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if i.Username != other.Username {
+    return false
+  }
+  return true
+}
+
+// Clone returns a deep copy of i.
+func (i *NameChange) Clone() *NameChange {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *NameChange) clone() NameChange {
+  c := *i
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *NameChange) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *NameChange) EncodedSizeIn(format buffer.WireFormat) int {
+  n := 0
+  n += format.BytesSize(len(i.Username))
+  return n
+}
+
+// ToNameChange returns a deep copy of the fields NameChange picks from Player.
+func (i *Player) ToNameChange() NameChange {
+  result := NameChange{}
+  result.Username = i.Username
+  return result
+}
+
+// ApplyTo deep copies the fields of NameChange to the Player it was picked from.
+func (i *NameChange) ApplyTo(to *Player) {
+  to.Username = i.Username
+}
+
+type ProfileSummary struct {
+Username    string     `json:"username" redis:"username"`
+Friends    []uint     `json:"friends" redis:"friends"`
+Avatar    Shape     `json:"avatar" redis:"avatar"`
+}
+
+func DecodeProfileSummary(buf buffer.Reader) (ProfileSummary, error) {
+  result := ProfileSummary{}
+  err := DecodeProfileSummaryInto(buf, &result)
+  return result, err
+}
+
+// DecodeProfileSummaryInto decodes into result, reusing the room in its slices.
+func DecodeProfileSummaryInto(buf buffer.Reader, result *ProfileSummary) error {
+  var err error
+  var length uint
+  result.Username = buf.ReadString()
+  length = buf.ReadArrayLength()
+  if result.Friends == nil || uint(cap(result.Friends)) < length {
+    result.Friends = make([]uint, length)
+  } else {
+    result.Friends = result.Friends[:length]
+  }
+  for j := range result.Friends {
+    result.Friends[j] = buf.ReadVarUint()
+  }
+  if result.Avatar, err = DecodeShape(buf); err != nil {
+    return err
+  }
+  return buf.Err()
+}
+
+func (i *ProfileSummary) Encode(buf buffer.Writer) error {
+  buf.Grow(i.EncodedSizeIn(buf.WireFormat()))
+  return i.encode(buf)
+}
+
+func (i *ProfileSummary) encode(buf buffer.Writer) error {
+
+var err error;
+    var n uint;
+    buf.WriteString(i.Username);
+
+    n = uint(len(i.Friends))
+    buf.WriteVarUint(n);
+    for j := uint(0); j < n; j++ {
+      buf.WriteVarUint(i.Friends[j]);
+    }
+
+    err =encodeShape(buf, i.Avatar)
+    if err != nil {
+ return err
+}
+
+  return nil
+}
+
+func (*ProfileSummary) TypeName() string {
+  return "gotest.ProfileSummary"
+}
+
+func (*ProfileSummary) TypeID() uint32 {
+  return 1599749155
+}
+
+func (i *ProfileSummary) Decode(buf buffer.Reader) error {
+  return DecodeProfileSummaryInto(buf, i)
+}
+
+// Reset empties i, keeping the room in its slices for DecodeProfileSummaryInto.
+func (i *ProfileSummary) Reset() {
+  *i = ProfileSummary{Friends: i.Friends[:0]}
+}
+
+// Equal reports whether i and other have the same fields.
+func (i *ProfileSummary) Equal(other *ProfileSummary) bool {
+  if i == nil || other == nil {
+    return i == other
+  }
+  if i.Username != other.Username {
+    return false
+  }
+  if len(i.Friends) != len(other.Friends) {
+    return false
+  }
+  for j := range i.Friends {
+    if i.Friends[j] != other.Friends[j] {
+      return false
+    }
+  }
+  if !EqualShape(i.Avatar, other.Avatar) {
+    return false
+  }
+  return true
+}
+
+// Clone returns a deep copy of i.
+func (i *ProfileSummary) Clone() *ProfileSummary {
+  if i == nil {
+    return nil
+  }
+  c := i.clone()
+  return &c
+}
+
+func (i *ProfileSummary) clone() ProfileSummary {
+  c := *i
+  c.Friends = append(i.Friends[:0:0], i.Friends...)
+  c.Avatar = CloneShape(i.Avatar)
+  return c
+}
+
+// EncodedSize returns how many bytes Encode writes with buffer.FixedWidthFormat.
+func (i *ProfileSummary) EncodedSize() int {
+  return i.EncodedSizeIn(buffer.FixedWidthFormat)
+}
+
+// EncodedSizeIn returns how many bytes Encode writes with format.
+func (i *ProfileSummary) EncodedSizeIn(format buffer.WireFormat) int {
+  n := 0
+  n += format.BytesSize(len(i.Username))
+  n += format.VarUintSize(uint(len(i.Friends)))
+  for j := range i.Friends {
+    n += format.VarUintSize(i.Friends[j])
+  }
+  n += EncodedSizeShape(format, i.Avatar)
+  return n
+}
+
+// ToProfileSummary returns a deep copy of the fields ProfileSummary picks from Profile.
+func (i *Profile) ToProfileSummary() ProfileSummary {
+  result := ProfileSummary{}
+  if i.Username != nil {
+    result.Username = (*i.Username)
+  }
+  if i.Friends != nil {
+    result.Friends = append((*i.Friends)[:0:0], (*i.Friends)...)
+  }
+  result.Avatar = CloneShape(i.Avatar)
+  return result
+}
+
+// ApplyTo deep copies the fields of ProfileSummary to the Profile it was picked from.
+func (i *ProfileSummary) ApplyTo(to *Profile) {
+  username_value := i.Username
+  to.Username = &username_value
+  friends_value := append(i.Friends[:0:0], i.Friends...)
+  to.Friends = &friends_value
+  to.Avatar = CloneShape(i.Avatar)
+}
+
+// SchemaTypes describes this schema for buffer.Buffer.SetTypes, which lets
+// code generated from an older version of it skip fields it doesn't know.
+var SchemaTypes = buffer.Types{
+  "Point": {Kind: buffer.StructType, Fields: []buffer.TypeField{{Type: "int"}, {Type: "int"}}},
+  "Label": {Kind: buffer.MessageType, Fields: []buffer.TypeField{{Number: 1, Type: "string"}, {Number: 2, Type: "uint"}}},
+  "Shape": {Kind: buffer.UnionType, Fields: []buffer.TypeField{{Number: 1, Type: "Point"}, {Number: 2, Type: "Label"}}},
+  "Event": {Kind: buffer.UnionType, Fields: []buffer.TypeField{{Number: 1, Type: "Point"}, {Number: 2, Type: "Label"}}},
+  "Any": {Kind: buffer.UnionType, Fields: []buffer.TypeField{{Number: 1, Type: "Shape"}, {Number: 2, Type: "Event"}}},
+  "ShapeStruct": {Kind: buffer.StructType, Fields: []buffer.TypeField{{Type: "Shape"}, {Type: "Event"}}},
+  "EventArrayStruct": {Kind: buffer.StructType, Fields: []buffer.TypeField{{Type: "Event", Array: true}}},
+  "ShapeMessage": {Kind: buffer.MessageType, Fields: []buffer.TypeField{{Number: 1, Type: "Shape"}, {Number: 2, Type: "Event", Array: true}}},
+  "AnyStruct": {Kind: buffer.StructType, Fields: []buffer.TypeField{{Type: "Any"}}},
+  "Player": {Kind: buffer.StructType, Fields: []buffer.TypeField{{Type: "float"}, {Type: "float"}, {Type: "bool"}, {Type: "string"}}},
+  "Profile": {Kind: buffer.MessageType, Fields: []buffer.TypeField{{Number: 1, Type: "string"}, {Number: 2, Type: "uint", Array: true}, {Number: 3, Type: "Shape"}, {Number: 4, Type: "uint"}}},
+  "PlayerUpdate": {Kind: buffer.UnionType, Fields: []buffer.TypeField{{Number: 1, Type: "PositionUpdate"}, {Number: 2, Type: "NameChange"}}},
+  "PlayerUpdateStruct": {Kind: buffer.StructType, Fields: []buffer.TypeField{{Type: "PlayerUpdate"}}},
+  "Entity": {Kind: buffer.StructType, Fields: []buffer.TypeField{{Type: "string"}, {Type: "string", Array: true}, {Type: "float32"}, {Type: "float32", Array: true}}},
+  "EntityMessage": {Kind: buffer.MessageType, Fields: []buffer.TypeField{{Number: 1, Type: "string"}, {Number: 2, Type: "string", Array: true}, {Number: 3, Type: "float32"}}},
+  "Account": {Kind: buffer.MessageType, Fields: []buffer.TypeField{{Number: 1, Type: "string"}, {Number: 2, Type: "uint"}, {Number: 3, Type: "string"}, {Number: 4, Type: "Label", Array: true}, {Number: 5, Type: "Shape"}}},
+  "AccountV1": {Kind: buffer.MessageType, Fields: []buffer.TypeField{{Number: 1, Type: "string"}, {Number: 2, Type: "uint"}, {Number: 3, Type: "string"}, {Number: 4, Type: "Label", Array: true}, {Number: 5, Type: "Shape"}}},
+  "Card": {Kind: buffer.MessageType, Fields: []buffer.TypeField{{Number: 1, Type: "string"}}},
+  "CardV2": {Kind: buffer.MessageType, Fields: []buffer.TypeField{{Number: 1, Type: "string"}, {Number: 2, Type: "Point"}, {Number: 3, Type: "Label", Array: true}, {Number: 4, Type: "Event", Array: true}, {Number: 5, Type: "float32", Array: true}, {Number: 6, Type: "Card"}}},
+  "Suit": {Kind: buffer.EnumType},
+  "Rank": {Kind: buffer.SmolType},
+  "Hand": {Kind: buffer.MessageType, Fields: []buffer.TypeField{{Number: 1, Type: "Suit"}, {Number: 2, Type: "Suit", Array: true}, {Number: 3, Type: "Rank"}, {Number: 4, Type: "Rank", Array: true}}},
+  "PositionUpdate": {Kind: buffer.StructType, Fields: []buffer.TypeField{{Type: "float"}, {Type: "float"}, {Type: "bool"}}},
+  "NameChange": {Kind: buffer.StructType, Fields: []buffer.TypeField{{Type: "string"}}},
+  "ProfileSummary": {Kind: buffer.StructType, Fields: []buffer.TypeField{{Type: "string"}, {Type: "uint", Array: true}, {Type: "Shape"}}},
+}
+
+func init() {
+  for _, newMessage := range []func() message.Message{
+    func() message.Message { return new(Point) },
+    func() message.Message { return new(Label) },
+    func() message.Message { return new(ShapeStruct) },
+    func() message.Message { return new(EventArrayStruct) },
+    func() message.Message { return new(ShapeMessage) },
+    func() message.Message { return new(AnyStruct) },
+    func() message.Message { return new(Player) },
+    func() message.Message { return new(Profile) },
+    func() message.Message { return new(PlayerUpdateStruct) },
+    func() message.Message { return new(Entity) },
+    func() message.Message { return new(EntityMessage) },
+    func() message.Message { return new(Account) },
+    func() message.Message { return new(AccountV1) },
+    func() message.Message { return new(Card) },
+    func() message.Message { return new(CardV2) },
+    func() message.Message { return new(Hand) },
+    func() message.Message { return new(PositionUpdate) },
+    func() message.Message { return new(NameChange) },
+    func() message.Message { return new(ProfileSummary) },
+  } {
+    if err := message.Register(newMessage); err != nil {
+      panic(err)
+    }
+  }
+}
@@ -0,0 +1,633 @@
+package gotest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"testing"
+	"testing/iotest"
+
+	"github.com/jarred-sumner/peechy/buffer"
+	"github.com/jarred-sumner/peechy/message"
+	"github.com/jarred-sumner/peechy/test/gotest"
+	"github.com/valyala/bytebufferpool"
+)
+
+// schema.go is generated from test-go.kiwi by test.sh.
+
+type jsFixture struct {
+	Type  string `json:"type"`
+	Bytes []int  `json:"bytes"`
+}
+
+func (f jsFixture) bytes() []byte {
+	out := make([]byte, len(f.Bytes))
+	for i, b := range f.Bytes {
+		out[i] = byte(b)
+	}
+	return out
+}
+
+type encoder interface {
+	Encode(buf buffer.Writer) error
+	EncodedSize() int
+	EncodedSizeIn(format buffer.WireFormat) int
+}
+
+// roundTrip decodes the bytes JavaScript wrote and encodes them again.
+var roundTrip = map[string]func(buf buffer.Reader) (encoder, error){
+	"ShapeStruct": func(buf buffer.Reader) (encoder, error) {
+		value, err := gotest.DecodeShapeStruct(buf)
+		return &value, err
+	},
+	"EventArrayStruct": func(buf buffer.Reader) (encoder, error) {
+		value, err := gotest.DecodeEventArrayStruct(buf)
+		return &value, err
+	},
+	"ShapeMessage": func(buf buffer.Reader) (encoder, error) {
+		value, err := gotest.DecodeShapeMessage(buf)
+		return &value, err
+	},
+	"PlayerUpdateStruct": func(buf buffer.Reader) (encoder, error) {
+		value, err := gotest.DecodePlayerUpdateStruct(buf)
+		return &value, err
+	},
+	"ProfileSummary": func(buf buffer.Reader) (encoder, error) {
+		value, err := gotest.DecodeProfileSummary(buf)
+		return &value, err
+	},
+	"Hand": func(buf buffer.Reader) (encoder, error) {
+		value, err := gotest.DecodeHand(buf)
+		return &value, err
+	},
+	"EntityMessage": func(buf buffer.Reader) (encoder, error) {
+		value, err := gotest.DecodeEntityMessage(buf)
+		return &value, err
+	},
+}
+
+func encode(t *testing.T, value encoder) []byte {
+	bb := bytebufferpool.Get()
+	defer bytebufferpool.Put(bb)
+
+	if err := value.Encode(buffer.NewBuffer(bb)); err != nil {
+		t.Fatal(err)
+	}
+	return append([]byte(nil), bb.B...)
+}
+
+// These come from test/go-fixtures.js.
+func readJSFixtures(t *testing.T) []jsFixture {
+	contents, err := ioutil.ReadFile("../go-fixtures.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fixtures []jsFixture
+	if err = json.Unmarshal(contents, &fixtures); err != nil {
+		t.Fatal(err)
+	}
+	return fixtures
+}
+
+func TestSchemaMatchesJS(t *testing.T) {
+	tested := 0
+	for _, fixture := range readJSFixtures(t) {
+		decode, ok := roundTrip[fixture.Type]
+		if !ok {
+			continue
+		}
+		tested++
+
+		value, err := decode(buffer.FromBytes(fixture.bytes()))
+		if err != nil {
+			t.Fatalf("%s %v: %v", fixture.Type, fixture.Bytes, err)
+		}
+
+		if out := encode(t, value); !bytes.Equal(out, fixture.bytes()) {
+			t.Fatalf("%s: expected %v to equal %v", fixture.Type, out, fixture.bytes())
+		}
+	}
+
+	if tested == 0 {
+		t.Fatal("No fixtures for test-go.kiwi")
+	}
+}
+
+func TestSchemaUnion(t *testing.T) {
+	in := gotest.ShapeStruct{
+		Shape: &gotest.Label{Text: &[]string{"hi"}[0]},
+		Event: &gotest.Point{X: 3, Y: 4},
+	}
+
+	out, err := gotest.DecodeShapeStruct(buffer.FromBytes(encode(t, &in)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	switch shape := out.Shape.(type) {
+	case *gotest.Label:
+		if shape.Text == nil || *shape.Text != "hi" || shape.Color != nil {
+			t.Fatalf("Expected %+v to equal %+v", shape, in.Shape)
+		}
+	default:
+		t.Fatalf("Expected *Label, got %T", out.Shape)
+	}
+
+	if point, ok := out.Event.(*gotest.Point); !ok || *point != (gotest.Point{X: 3, Y: 4}) {
+		t.Fatalf("Expected %+v to equal %+v", out.Event, in.Event)
+	}
+}
+
+func TestSchemaNestedUnion(t *testing.T) {
+	point := &gotest.Point{X: 1, Y: 2}
+	for _, test := range []struct {
+		value gotest.Any
+		tag   gotest.AnyType
+	}{
+		{&gotest.AnyShape{Value: point}, gotest.AnyTypeShape},
+		{&gotest.AnyEvent{Value: point}, gotest.AnyTypeEvent},
+		{&gotest.AnyEvent{Value: &gotest.Label{}}, gotest.AnyTypeEvent},
+	} {
+		in := gotest.AnyStruct{Value: test.value}
+		data := encode(t, &in)
+		if data[0] != byte(test.tag) {
+			t.Fatalf("Expected %v to start with %d", data, test.tag)
+		}
+
+		// The wrapper keeps which union the Point was in, so it goes back
+		// with the same type.
+		out, err := gotest.DecodeAnyStruct(buffer.FromBytes(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !out.Equal(&in) || !bytes.Equal(encode(t, &out), data) {
+			t.Fatalf("Expected %+v to equal %+v", out, in)
+		}
+	}
+
+	if gotest.EqualAny(&gotest.AnyShape{Value: point}, &gotest.AnyEvent{Value: point}) {
+		t.Fatal("Expected a Shape and an Event not to be equal")
+	}
+}
+
+func TestSchemaInvalidUnion(t *testing.T) {
+	var in gotest.ShapeStruct
+	if err := in.Encode(buffer.NewBuffer(bytebufferpool.Get())); err == nil {
+		t.Fatal("Expected an error encoding a nil union")
+	}
+
+	// A nil pointer is no more a member than a nil interface.
+	for _, value := range []gotest.Any{(*gotest.AnyShape)(nil), &gotest.AnyShape{}, &gotest.AnyEvent{Value: (*gotest.Point)(nil)}} {
+		in := gotest.AnyStruct{Value: value}
+		if err := in.Encode(buffer.NewBuffer(bytebufferpool.Get())); err == nil {
+			t.Fatalf("Expected an error encoding %#v", value)
+		}
+	}
+	if size := gotest.EncodedSizeShape(buffer.FixedWidthFormat, (*gotest.Point)(nil)); size != 0 {
+		t.Fatalf("Expected a nil member to have no size, got %d", size)
+	}
+
+	if _, err := gotest.DecodeShape(buffer.FromBytes([]byte{3})); err == nil {
+		t.Fatal("Expected an error for an unknown union type")
+	}
+
+	if _, err := gotest.DecodeShape(buffer.FromBytes(nil)); err == nil {
+		t.Fatal("Expected an error for an empty buffer")
+	}
+}
+
+func TestSchemaPick(t *testing.T) {
+	player := gotest.Player{X: 1, Y: 2, OnGround: true, Username: "a"}
+
+	update := player.ToPositionUpdate()
+	if update != (gotest.PositionUpdate{X: 1, Y: 2, OnGround: true}) {
+		t.Fatalf("Expected %+v to have the fields of %+v", update, player)
+	}
+
+	update.X = 3
+	update.ApplyTo(&player)
+	if player != (gotest.Player{X: 3, Y: 2, OnGround: true, Username: "a"}) {
+		t.Fatalf("Expected %+v to have the fields of %+v", player, update)
+	}
+
+	// Picks from messages copy the values the message fields point to.
+	username := "b"
+	profile := gotest.Profile{Username: &username}
+
+	summary := profile.ToProfileSummary()
+	if summary.Username != "b" || summary.Friends != nil || summary.Avatar != nil {
+		t.Fatalf("Expected %+v to have the fields of %+v", summary, profile)
+	}
+
+	summary.Username = "c"
+	summary.Friends = []uint{1}
+	summary.ApplyTo(&profile)
+	if username != "b" || *profile.Username != "c" || len(*profile.Friends) != 1 || profile.Age != nil {
+		t.Fatalf("Expected %+v to have the fields of %+v", profile, summary)
+	}
+
+	// Like Clone, neither shares slices or union members with the other.
+	summary.Friends[0] = 2
+	summary.Avatar = &gotest.Point{X: 1}
+	summary.ApplyTo(&profile)
+	summary.Friends[0] = 3
+	summary.Avatar.(*gotest.Point).X = 3
+	if (*profile.Friends)[0] != 2 || profile.Avatar.(*gotest.Point).X != 1 {
+		t.Fatalf("Expected ApplyTo to copy, got %+v", profile)
+	}
+	copied := profile.ToProfileSummary()
+	(*profile.Friends)[0] = 4
+	profile.Avatar.(*gotest.Point).X = 4
+	if copied.Friends[0] != 2 || copied.Avatar.(*gotest.Point).X != 1 {
+		t.Fatalf("Expected ToProfileSummary to copy, got %+v", copied)
+	}
+}
+
+func TestSchemaAlias(t *testing.T) {
+	in := gotest.Entity{
+		ID:     gotest.UserID("u1"),
+		Tags:   []gotest.ID{"a", "b"},
+		Height: gotest.Meters(1.5),
+		Path:   []gotest.Meters{1, 2},
+	}
+
+	out, err := gotest.DecodeEntity(buffer.FromBytes(encode(t, &in)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out.ID != in.ID || len(out.Tags) != 2 || out.Tags[1] != "b" || out.Height != in.Height || len(out.Path) != 2 || out.Path[1] != 2 {
+		t.Fatalf("Expected %+v to equal %+v", out, in)
+	}
+}
+
+func TestSchemaAliasArray(t *testing.T) {
+	in := gotest.Entity{Path: []gotest.Meters{0.5, -2, 3}}
+	data := encode(t, &in)
+
+	// The path is written the same as a float32 array.
+	buf := buffer.NewBuffer(bytebufferpool.Get())
+	buf.WriteFloat32Array([]float32{0.5, -2, 3})
+	if !bytes.HasSuffix(data, buf.Bytes()) {
+		t.Fatalf("Expected %v to end with %v", data, buf.Bytes())
+	}
+
+	// Decoding into an entity reuses its path.
+	out := gotest.Entity{Path: make([]gotest.Meters, 1, 8)}
+	reused := &out.Path[0]
+	if err := gotest.DecodeEntityInto(buffer.FromBytes(data), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !out.Equal(&in) || &out.Path[0] != reused {
+		t.Fatalf("Expected %+v to equal %+v in the same array", out, in)
+	}
+}
+
+func TestSchemaRequired(t *testing.T) {
+	name := "a"
+	in := gotest.AccountV1{Name: &name}
+
+	_, err := gotest.DecodeAccount(buffer.FromBytes(encode(t, &in)))
+	if missing, ok := err.(*buffer.MissingFieldError); !ok || missing.Message != "Account" || missing.Field != "avatar" {
+		t.Fatalf("Expected a missing avatar, got %v", err)
+	}
+
+	account := gotest.Account{Avatar: &gotest.Point{}}
+	err = account.Encode(buffer.NewBuffer(bytebufferpool.Get()))
+	if missing, ok := err.(*buffer.MissingFieldError); !ok || missing.Field != "name" {
+		t.Fatalf("Expected a missing name, got %v", err)
+	}
+
+	// Running out of bytes isn't reported as a missing field.
+	data := encode(t, &gotest.AccountV1{Name: &name, Avatar: &gotest.Point{}})
+	_, err = gotest.DecodeAccount(buffer.FromBytes(data[:len(data)-1]))
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("Expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestSchemaDeprecated(t *testing.T) {
+	name, age, email := "a", uint(3), "a@example.com"
+	in := gotest.AccountV1{
+		Name:   &name,
+		Age:    &age,
+		Email:  &email,
+		Labels: &[]gotest.Label{{Text: &name}},
+		Avatar: &gotest.Point{X: 1},
+	}
+
+	account, err := gotest.DecodeAccount(buffer.FromBytes(encode(t, &in)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := gotest.AccountV1{Name: &name, Age: &age, Avatar: &gotest.Point{X: 1}}
+	if !bytes.Equal(encode(t, &account), encode(t, &expected)) {
+		t.Fatalf("Expected %+v to be written without deprecated fields", account)
+	}
+}
+
+func TestSchemaUnknownFields(t *testing.T) {
+	// Card as it is now, for decoding CardV2 with the generated DecodeCard.
+	types := buffer.Types{}
+	for name, typ := range gotest.SchemaTypes {
+		types[name] = typ
+	}
+	types["Card"] = gotest.SchemaTypes["CardV2"]
+
+	title := "a"
+	weights := []float32{1, 2}
+	fromGo := gotest.CardV2{Title: &title, Weights: &weights, Back: &gotest.Card{Title: &title}}
+
+	tested := 0
+	for _, data := range append([][]byte{encode(t, &fromGo)}, jsFixtureBytes(t, "CardV2")...) {
+		tested++
+
+		_, err := gotest.DecodeCard(buffer.FromBytes(data))
+		if unknown, ok := err.(*buffer.UnknownFieldError); !ok || unknown.Message != "Card" || unknown.Field < 2 {
+			t.Fatalf("Expected an unknown field in Card, got %v", err)
+		}
+
+		buf := buffer.FromBytes(data)
+		buf.SetTypes(types)
+		card, err := gotest.DecodeCard(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if card.Title == nil || *card.Title != "a" || len(card.UnknownFields) == 0 {
+			t.Fatalf("Expected %+v to have a title and unknown fields", card)
+		}
+		if out := encode(t, &card); !bytes.Equal(out, data) {
+			t.Fatalf("Expected %v to equal %v", out, data)
+		}
+
+		// Skipping has to work while reading a stream, too.
+		buf = buffer.NewReader(iotest.OneByteReader(bytes.NewReader(data)))
+		buf.SetTypes(types)
+		card, err = gotest.DecodeCard(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out := encode(t, &card); !bytes.Equal(out, data) {
+			t.Fatalf("Expected %v to equal %v", out, data)
+		}
+	}
+
+	if tested < 2 {
+		t.Fatal("No fixtures for CardV2")
+	}
+}
+
+func jsFixtureBytes(t *testing.T, typeName string) [][]byte {
+	var out [][]byte
+	for _, fixture := range readJSFixtures(t) {
+		if fixture.Type == typeName {
+			out = append(out, fixture.bytes())
+		}
+	}
+	return out
+}
+
+func TestSchemaEnum(t *testing.T) {
+	values := gotest.SuitValues()
+	if len(values) != 4 || values[0] != gotest.SuitClubs || values[3] != gotest.SuitSpades {
+		t.Fatalf("Expected %v to be every Suit", values)
+	}
+
+	if !gotest.SuitHearts.IsValid() || gotest.Suit(5).IsValid() || gotest.Rank(2).IsValid() {
+		t.Fatal("Expected only the constants to be valid")
+	}
+
+	if s := gotest.SuitHearts.String(); s != "Hearts" {
+		t.Fatalf("Expected %q to equal %q", s, "Hearts")
+	}
+	if s := gotest.Suit(5).String(); s != "Suit(5)" {
+		t.Fatalf("Expected %q to equal %q", s, "Suit(5)")
+	}
+
+	var suit gotest.Suit
+	if err := suit.UnmarshalText([]byte("Spades")); err != nil || suit != gotest.SuitSpades {
+		t.Fatalf("Expected SuitSpades, got %v, %v", suit, err)
+	}
+	if err := suit.UnmarshalText([]byte("SuitSpades")); err == nil {
+		t.Fatal("Expected an error for the Go name")
+	}
+	if err := suit.UnmarshalText([]byte("Joker")); err == nil {
+		t.Fatal("Expected an error for an unknown name")
+	}
+	if text, err := gotest.Suit(5).MarshalText(); err != nil || string(text) != "5" {
+		t.Fatalf("Expected an unknown value to be a number, got %q, %v", text, err)
+	}
+	if err := suit.UnmarshalText([]byte("5")); err != nil || suit != 5 {
+		t.Fatalf("Expected Suit(5), got %v, %v", suit, err)
+	}
+
+	// Unknown values survive JSON as numbers, but unknown names don't parse.
+	var hand struct{ Suits []gotest.Suit }
+	if err := json.Unmarshal([]byte(`{"Suits":["Clubs",9]}`), &hand); err != nil {
+		t.Fatal(err)
+	}
+	out, err := json.Marshal(hand)
+	if err != nil || string(out) != `{"Suits":["Clubs",9]}` {
+		t.Fatalf("Expected %s to round trip, got %v", out, err)
+	}
+	if err := json.Unmarshal([]byte(`{"Suits":["Joker"]}`), &hand); err == nil {
+		t.Fatal("Expected an error for an unknown name")
+	}
+
+	// Map keys are marshaled as text, which agrees with JSON.
+	counts := map[gotest.Suit]int{gotest.SuitClubs: 1, 9: 2}
+	out, err = json.Marshal(counts)
+	if err != nil || string(out) != `{"9":2,"Clubs":1}` {
+		t.Fatalf("Expected the unknown key to be a number, got %s, %v", out, err)
+	}
+	decodedCounts := map[gotest.Suit]int{}
+	if err := json.Unmarshal(out, &decodedCounts); err != nil || len(decodedCounts) != 2 || decodedCounts[9] != 2 {
+		t.Fatalf("Expected %s to round trip, got %v, %v", out, decodedCounts, err)
+	}
+
+	// Decoding keeps unknown values.
+	trump := gotest.Suit(9)
+	in := gotest.Hand{Trump: &trump}
+	decoded, err := gotest.DecodeHand(buffer.FromBytes(encode(t, &in)))
+	if err != nil || *decoded.Trump != 9 {
+		t.Fatalf("Expected the unknown value to be kept, got %v, %v", decoded.Trump, err)
+	}
+}
+
+func TestSchemaEncodedSize(t *testing.T) {
+	for _, fixture := range readJSFixtures(t) {
+		decode, ok := roundTrip[fixture.Type]
+		if !ok {
+			continue
+		}
+		value, err := decode(buffer.FromBytes(fixture.bytes()))
+		if err != nil {
+			t.Fatalf("%s: %v", fixture.Type, err)
+		}
+
+		if size, want := value.EncodedSize(), len(fixture.Bytes); size != want {
+			t.Errorf("%s: EncodedSize is %d, Encode writes %d bytes", fixture.Type, size, want)
+		}
+
+		buf := buffer.FromBytes(nil)
+		buf.SetWireFormat(buffer.VarintFormat)
+		if err := value.Encode(buf); err != nil {
+			t.Fatalf("%s: %v", fixture.Type, err)
+		}
+		if size, want := value.EncodedSizeIn(buffer.VarintFormat), len(buf.Bytes()); size != want {
+			t.Errorf("%s: EncodedSizeIn(VarintFormat) is %d, Encode writes %d bytes", fixture.Type, size, want)
+		}
+	}
+
+	// Unions and unknown fields count too.
+	text := "peechy"
+	shape := gotest.ShapeMessage{Shape: &gotest.Label{Text: &text}, UnknownFields: []byte{9, 1}}
+	if size, want := shape.EncodedSize(), len(encode(t, &shape)); size != want {
+		t.Errorf("EncodedSize is %d, Encode writes %d bytes", size, want)
+	}
+}
+
+func TestSchemaEncodeGrowsOnce(t *testing.T) {
+	hand := gotest.Hand{Suits: &[]gotest.Suit{gotest.SuitHearts, gotest.SuitSpades}, Ranks: &[]gotest.Rank{gotest.RankAce}}
+	buf := buffer.FromBytes(nil)
+	if err := hand.Encode(buf); err != nil {
+		t.Fatal(err)
+	}
+	if len(buf.Bytes()) != cap(buf.Bytes()) {
+		t.Fatalf("Expected Encode to reserve exactly %d bytes, got %d", len(buf.Bytes()), cap(buf.Bytes()))
+	}
+}
+
+func TestSchemaDecodeInto(t *testing.T) {
+	trump, high := gotest.SuitHearts, gotest.RankKing
+	first := encode(t, &gotest.Hand{Trump: &trump, Suits: &[]gotest.Suit{gotest.SuitClubs, gotest.SuitSpades}, High: &high})
+	second := encode(t, &gotest.Hand{Trump: &trump, Suits: &[]gotest.Suit{gotest.SuitHearts}})
+
+	var hand gotest.Hand
+	if err := gotest.DecodeHandInto(buffer.FromBytes(first), &hand); err != nil {
+		t.Fatal(err)
+	}
+	trumpPointer, suits := hand.Trump, &(*hand.Suits)[0]
+
+	if err := gotest.DecodeHandInto(buffer.FromBytes(second), &hand); err != nil {
+		t.Fatal(err)
+	}
+	if hand.Trump != trumpPointer || &(*hand.Suits)[0] != suits {
+		t.Fatal("Expected DecodeHandInto to reuse the fields of the last value")
+	}
+	if hand.High != nil {
+		t.Fatalf("Expected a field that isn't in the message to be nil, got %v", *hand.High)
+	}
+	if got := encode(t, &hand); !bytes.Equal(got, second) {
+		t.Fatalf("Expected %v, got %v", second, got)
+	}
+
+	entity := gotest.Entity{ID: "a", Tags: []gotest.ID{"b", "c"}, Path: []gotest.Meters{1, 2, 3}}
+	data := encode(t, &entity)
+	tags, path := &entity.Tags[0], &entity.Path[0]
+
+	entity.Reset()
+	if entity.ID != "" || len(entity.Tags) != 0 || len(entity.Path) != 0 {
+		t.Fatalf("Expected Reset to empty the struct, got %+v", entity)
+	}
+	if err := gotest.DecodeEntityInto(buffer.FromBytes(data), &entity); err != nil {
+		t.Fatal(err)
+	}
+	if &entity.Tags[0] != tags || &entity.Path[0] != path {
+		t.Fatal("Expected DecodeEntityInto to reuse the slices kept by Reset")
+	}
+	if got := encode(t, &entity); !bytes.Equal(got, data) {
+		t.Fatalf("Expected %v, got %v", data, got)
+	}
+}
+
+func TestSchemaEqualClone(t *testing.T) {
+	name, age := "ada", uint(36)
+	profile := gotest.Profile{
+		Username: &name,
+		Friends:  &[]uint{1, 2},
+		Avatar:   &gotest.Point{X: 1, Y: 2},
+		Age:      &age,
+	}
+
+	clone := profile.Clone()
+	if !clone.Equal(&profile) || !profile.Equal(clone) {
+		t.Fatal("Expected a clone to equal the original")
+	}
+	if clone.Username == profile.Username || clone.Avatar == profile.Avatar {
+		t.Fatal("Expected Clone to copy what the fields point to")
+	}
+
+	(*clone.Friends)[0] = 3
+	if (*profile.Friends)[0] != 1 || clone.Equal(&profile) {
+		t.Fatal("Expected changing a clone to leave the original alone")
+	}
+
+	zero := uint(0)
+	if (&gotest.Profile{}).Equal(&gotest.Profile{Age: &zero}) {
+		t.Fatal("Expected an unset field not to equal one set to zero")
+	}
+	if !(&gotest.Profile{Friends: &[]uint{}}).Equal(&gotest.Profile{Friends: new([]uint)}) {
+		t.Fatal("Expected nil and empty slices to be equal")
+	}
+	if (&gotest.Profile{}).Equal(&gotest.Profile{UnknownFields: []byte{5, 1}}) {
+		t.Fatal("Expected unknown fields to be compared")
+	}
+
+	var missing *gotest.Profile
+	if missing.Clone() != nil || !missing.Equal(nil) || missing.Equal(&profile) {
+		t.Fatal("Expected nil values to only equal nil")
+	}
+
+	if !(&gotest.Entity{Tags: nil}).Equal(&gotest.Entity{Tags: []gotest.ID{}}) {
+		t.Fatal("Expected nil and empty slices in structs to be equal")
+	}
+
+	// Floats are compared by their bits, so NaN equals itself, but -0 and 0
+	// don't, since they encode differently.
+	nan := gotest.Meters(math.NaN())
+	entity := gotest.Entity{Height: nan, Path: []gotest.Meters{nan}}
+	if !entity.Equal(&entity) || !entity.Clone().Equal(&entity) {
+		t.Fatal("Expected NaN fields to equal themselves")
+	}
+	if (&gotest.Entity{Height: gotest.Meters(math.Copysign(0, -1))}).Equal(&gotest.Entity{}) {
+		t.Fatal("Expected -0 not to equal 0")
+	}
+
+	var value gotest.Any = &gotest.AnyShape{Value: &gotest.Label{Text: &name}}
+	copied := gotest.CloneAny(value)
+	if !gotest.EqualAny(value, copied) || copied.(*gotest.AnyShape).Value.(*gotest.Label) == value.(*gotest.AnyShape).Value.(*gotest.Label) {
+		t.Fatal("Expected CloneAny to copy the member")
+	}
+	if gotest.EqualShape(&gotest.Point{}, &gotest.Label{}) || gotest.EqualShape(&gotest.Point{}, nil) {
+		t.Fatal("Expected different members not to be equal")
+	}
+	if !gotest.EqualShape(nil, nil) || gotest.CloneShape(nil) != nil {
+		t.Fatal("Expected nil unions to equal and clone to nil")
+	}
+}
+
+func TestSchemaRegistry(t *testing.T) {
+	var player message.Message = &gotest.Player{}
+	if player.TypeName() != "gotest.Player" || player.TypeID() != message.ID("gotest.Player") {
+		t.Fatalf("Expected gotest.Player and its ID, got %s and %d", player.TypeName(), player.TypeID())
+	}
+
+	hand := gotest.Hand{Suits: &[]gotest.Suit{gotest.SuitSpades}}
+	decoded, err := message.Decode(hand.TypeID(), buffer.FromBytes(encode(t, &hand)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := decoded.(*gotest.Hand); !ok || !got.Equal(&hand) {
+		t.Fatalf("Expected %+v, got %+v", hand, decoded)
+	}
+
+	if _, ok := message.New("gotest.Profile").(*gotest.Profile); !ok {
+		t.Fatal("Expected gotest.Profile to be registered")
+	}
+}
@@ -0,0 +1,80 @@
+// Writes schema-fixtures.json, what the JavaScript parser makes of the
+// schemas in the repo and of some broken ones. The Go schema package is tested
+// against it, so that both parsers agree.
+var fs = require("fs");
+var peechy = require(__dirname + "/../js/peechy.node.js");
+
+var files = [
+  "test-schema.kiwi",
+  "test-schema-large.kiwi",
+  "test-schema-round-trip.kiwi",
+  "test1-schema.kiwi",
+  "test2-schema.kiwi",
+  "test-union.kiwi",
+  "test-go.kiwi",
+  "test-go-strict.kiwi",
+  "../js/simple-schema.kiwi",
+  "../js/lockfile.kiwi",
+];
+
+var broken = [
+  "struct Foo { int x; } ?",
+  "struct Foo { int x }",
+  "message Foo { int x = 1 }",
+  "message Foo { int x = 01; }",
+  "message Foo { int x = 4294967296; }",
+  "message Foo { int x = 0; }",
+  "message Foo { int x = 2; }",
+  "message Foo { int x = 1; int y = 1; }",
+  "struct Foo { int x; }\nstruct Foo { int y; }",
+  "struct int { int x; }",
+  "struct ByteBuffer { int x; }",
+  "struct Foo { Bar x; }",
+  "struct Foo { discriminator x; }",
+  "struct Foo { int x [deprecated]; }",
+  "struct Foo { Foo x; }",
+  "struct A { B b; }\nstruct B { A a; }",
+  "union U = A | A;\nstruct A { int x; }",
+  "union U = A | B;\nstruct A { int x; }",
+  "alias A = Missing;",
+  "enum E { A = 1; }\nstruct S & E { int x; }",
+  "pick P : Missing { x; }",
+  "struct S { int x; }\npick P : S { y; }",
+  "struct S { int x; }\npick P : S { x; x; }",
+  "package 1;",
+  "widget Foo {}",
+  "message Foo {\n  int x = 1;\n  string y = 3;\n}",
+];
+
+var inline = [
+  "package game;\n\nenum Kind { A = 1; B = 2; }\nsmol Small { X = 1; }\n",
+  "struct A { int a; }\nstruct B & A { int b; }\n",
+  "struct Point { float x; float y; }\nunion Shape = Point;\nunion Tagged = Point {\n  kind;\n}\n",
+  "message Player { string name = 1 [!]; uint age = 2 [deprecated]; int[] scores = 3; }\npick Name : Player { name; }\n",
+  "alias ID = string;\nalias Other = ID;\nentity E { ID id = 1; }\n",
+  'struct Foo from "foo" { int x; }\n',
+];
+
+function parse(text) {
+  try {
+    return { schema: peechy.parseSchema(text) };
+  } catch (e) {
+    return { error: { message: e.message, line: e.line, column: e.column } };
+  }
+}
+
+var out = files
+  .map(function (file) {
+    var text = fs.readFileSync(__dirname + "/" + file, "utf8");
+    return Object.assign({ name: file, text: text }, parse(text));
+  })
+  .concat(
+    inline.concat(broken).map(function (text) {
+      return Object.assign({ name: JSON.stringify(text), text: text }, parse(text));
+    })
+  );
+
+fs.writeFileSync(
+  __dirname + "/schema-fixtures.json",
+  JSON.stringify(out, null, 2) + "\n"
+);
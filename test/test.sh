@@ -6,6 +6,13 @@ node ../js/node_modules/mocha/bin/mocha ./test.js
 
 node ../js/cli.js --schema ./test-schema.kiwi --js ./test-schema.js
 
+node ../js/cli.js --schema ./test-go.kiwi --go ./gotest/schema.go
+node ../js/cli.js --schema ./test-go-strict.kiwi --go ./gostrict/schema.go --go-enums strict
+node ./go-fixtures.js
+node ./schema-fixtures.js
+node ./binary-fixtures.js
+(cd .. && go test . ./buffer/... ./dynamic/... ./frame/... ./golang/... ./message/... ./peechyjson/... ./schema/... ./test/...)
+
 node ../js/cli.js --schema ./test-schema.kiwi --ts ./test-schema.ts
 
 node ../js/cli.js --schema ./test-schema.kiwi --cpp ./test-schema.h